@@ -1,18 +1,33 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
 	"github.com/purnama/scaffold/internal/components"
 	"github.com/purnama/scaffold/internal/config"
+	"github.com/purnama/scaffold/internal/conflict"
+	"github.com/purnama/scaffold/internal/drift"
 	"github.com/purnama/scaffold/internal/generator"
+	"github.com/purnama/scaffold/internal/hooks"
+	"github.com/purnama/scaffold/internal/lockfile"
+	"github.com/purnama/scaffold/internal/progress"
+	"github.com/purnama/scaffold/internal/project"
+	"github.com/purnama/scaffold/internal/render"
+	"github.com/purnama/scaffold/internal/safefs"
 	"github.com/purnama/scaffold/internal/templates"
 	"github.com/purnama/scaffold/internal/tui"
+	"github.com/purnama/scaffold/internal/upgrade"
 	"github.com/spf13/cobra"
 )
 
@@ -20,8 +35,53 @@ var version = "0.2.0"
 
 // Flags
 var (
-	dryRun bool
-	force  bool
+	dryRun         bool
+	force          bool
+	conflictFlag   string
+	followSymlinks bool
+	varArgs        []string
+	profileName    string
+	jsonOutput     bool
+	diffSummary    bool
+	diffExitCode   bool
+	upgradeTo      string
+	listTag        string
+)
+
+// Init flags
+var (
+	initName       string
+	initLicense    string
+	initDocker     bool
+	initNoGit      bool
+	initTidy       bool
+	initModule     string
+	initOutputDir  string
+	nonInteractive bool
+	ignoreConfig   bool
+	noHooks        bool
+	hooksOnly      bool
+	trustHooks     bool
+)
+
+// Output flags
+var (
+	quiet        bool
+	verbose      bool
+	outputFormat string
+)
+
+// promptOut receives prompts, stderr when stdout carries JSON events
+var promptOut io.Writer = os.Stdout
+
+// Profile flags
+var (
+	profileAuthor     string
+	profileModule     string
+	profileLicense    string
+	profileTemplate   string
+	profileComponents []string
+	profileHooks      []string
 )
 
 // Styles
@@ -32,6 +92,8 @@ var (
 )
 
 func main() {
+	lockfile.ScaffoldVersion = version
+
 	rootCmd := &cobra.Command{
 		Use:   "scaffold",
 		Short: "Generate project folder structures automatically",
@@ -44,17 +106,20 @@ Commands:
   init [template]    Initialize a new project (interactive or with template)
   list               List all available templates (categorized)
   info <template>    Show template details before creating
-  config             Show current configuration
+  inspect [dir]      Describe an existing Go project
+  diff [dir]         Show how a project drifted from its template
+  upgrade [dir]      Merge a newer template version into a project
+  config             Show or edit configuration (set/get/unset/edit/path)
 
 Examples:
   scaffold init                        # Interactive mode with prompts
   scaffold init go-api                 # Quick start with template
   scaffold init fullstack --dry-run    # Preview without creating
-  scaffold list                        # See all 30 templates
+  scaffold list                        # See all 31 templates
   scaffold info learn-dsa              # Check what files will be created
 
-Templates (30):
-  Project:    go-api, go-cli, go-lib, go-grpc, go-worker, go-tui,
+Templates (31):
+  Project:    go-base, go-api, go-cli, go-lib, go-grpc, go-worker, go-tui,
               go-microservice, go-websocket, go-graphql, go-lambda, go-cron,
               go-auth, go-kafka, go-redis, go-clean-arch, go-monorepo
   Fullstack:  fullstack (Go + React/Vite/Bun/Tailwind)
@@ -63,15 +128,49 @@ Templates (30):
               learn-interfaces, learn-design-patterns
   Skill:      challenge-30days, mini-project, refactoring-exercise, code-review-exercise
 
-Config: ~/.scaffold/config.json`,
+Custom templates: templates/<name>/ in the config directory, with a
+        template.json manifest; "extends" and "includes" build on other
+        templates and components, "when" keeps paths only for some variable
+        values, "modes" sets file permissions (scripts starting with #! get
+        0755) and "static" lists files copied as they are (binary files
+        always are)
+Template functions in file contents and paths: title, camel, pascal, snake,
+        kebab, screaming, pluralize, goIdent, now, year, uuid, env, indent,
+        quote and default, e.g. {{.ProjectName | snake}}
+
+Config: $XDG_CONFIG_HOME/scaffold/config.json (~/.config/scaffold when unset,
+        or the legacy ~/.scaffold when it exists), .scaffoldrc and SCAFFOLD_*
+        variables (see 'scaffold config --help')
+Profiles: --profile work or SCAFFOLD_PROFILE=work (see 'scaffold config profile')`,
 		Version: version,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			loadCustomTemplates()
+		},
 	}
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Config profile to use (default $SCAFFOLD_PROFILE or the profile key)")
 
 	initCmd := &cobra.Command{
 		Use:   "init [template]",
 		Short: "Initialize a new project",
 		Long: `Initialize a new project in the current directory.
 
+Defaults for the author, license, module prefix and git come from the
+layered configuration (see 'scaffold config'); flags override them.
+The selected profile can also provide the template, components to add and
+hooks to run in the new project (see 'scaffold config profile').
+
+The project gets a .scaffold.json recording the template and its version,
+the variables, components and scaffold version used, and a hash of every
+generated file. 'scaffold add' updates it.
+
+go.mod requires the modules the template imports at pinned versions, written
+without network access; 'go mod tidy' (or --tidy) downloads them.
+
+Templates and the "hooks" config key can declare pre-init hooks, run in the
+staged project before anything is written, and post-init hooks, run in the
+new project. Hooks of custom templates and of a .scaffoldrc are only run
+after you confirm them.
+
 If no template is specified, interactive mode will guide you through:
   - Project name
   - Template selection  
@@ -80,8 +179,32 @@ If no template is specified, interactive mode will guide you through:
   - Git initialization
 
 Flags:
-  --dry-run    Preview what files will be created without creating them
-  --force      Overwrite existing directory if it already exists
+  --dry-run            Preview what files will be created without creating them
+  --conflict mode      Update an existing directory: skip, overwrite, backup
+                       (keep <file>.orig), prompt (show a diff and ask per file)
+                       or merge (add missing .gitignore lines/Makefile targets)
+  --var key=value      Set a template variable (repeatable, see 'scaffold info')
+  --name               Project name (skips the prompt)
+  --license            MIT, "Apache 2.0", "GPL 3.0" or None (default_license)
+  --docker             Add a Dockerfile
+  --no-git             Skip git init (auto_git)
+  --tidy               Run go mod tidy to download the template's
+                       dependencies and write go.sum (auto_install)
+  --module             Go module path (default <module_prefix>/<name>)
+  --output-dir         Create the project inside this directory
+  -y, --yes            Never prompt; fail if a required value is missing
+                       (implied when stdin is not a terminal)
+  --ignore-config      Use built-in defaults, ignoring config files and env
+  --no-hooks           Don't run template, config or profile hooks
+  --hooks-only         Run the hooks in an existing project (--name or .)
+                       with the template from its .scaffold.json
+  --trust-hooks        Run hooks that need confirmation without asking
+  -q, --quiet          Only show warnings and errors
+  -v, --verbose        Also show unchanged files, file modes and the
+                       commands scaffold runs
+  --output format      pretty (emoji and colors), plain, or json for one
+                       JSON event per line; plain when stdout isn't a
+                       terminal or NO_COLOR is set
 
 Available Templates:
   go-api                 REST API with clean architecture
@@ -105,38 +228,209 @@ Examples:
   scaffold init fullstack              # Create fullstack project (auto-installs deps)
   scaffold init learn-dsa              # Practice DSA with tests
   scaffold init go-api --dry-run       # Preview only
-  scaffold init go-api --force         # Overwrite if exists`,
+  scaffold init go-api --conflict=merge  # Update an existing directory
+  scaffold init go-api --var port=9000 # Set a template variable
+  scaffold init go-cli --yes --name tool --license None --no-git   # For CI`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: runInit,
 	}
 	initCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview files without creating them")
+	initCmd.Flags().StringVar(&conflictFlag, "conflict", "", "How to treat existing files: "+conflict.ModeNames())
 	initCmd.Flags().BoolVar(&force, "force", false, "Overwrite existing files")
+	initCmd.Flags().MarkDeprecated("force", "use --conflict=overwrite")
+	initCmd.Flags().BoolVar(&followSymlinks, "follow-symlinks", false, "Write through symbolic links in an existing project that stay inside it")
+	initCmd.Flags().StringArrayVar(&varArgs, "var", nil, "Template variable as key=value (repeatable)")
+	initCmd.Flags().StringVar(&initName, "name", "", "Project name")
+	initCmd.Flags().StringVar(&initLicense, "license", "", "License: MIT, \"Apache 2.0\", \"GPL 3.0\" or None (default from config)")
+	initCmd.Flags().BoolVar(&initDocker, "docker", false, "Include a Dockerfile")
+	initCmd.Flags().BoolVar(&initTidy, "tidy", false, "Run go mod tidy to download dependencies (auto_install, needs the module cache or a proxy)")
+	initCmd.Flags().BoolVar(&initNoGit, "no-git", false, "Don't initialize a git repository")
+	initCmd.Flags().StringVar(&initModule, "module", "", "Go module path (default <module_prefix>/<name>)")
+	initCmd.Flags().StringVar(&initOutputDir, "output-dir", "", "Directory to create the project in (default current directory)")
+	initCmd.Flags().BoolVarP(&nonInteractive, "yes", "y", false, "Never prompt, fail when a value is missing")
+	initCmd.Flags().BoolVar(&nonInteractive, "non-interactive", false, "Alias for --yes")
+	initCmd.Flags().BoolVar(&ignoreConfig, "ignore-config", false, "Ignore config files and SCAFFOLD_* variables, use built-in defaults")
+	initCmd.Flags().BoolVar(&noHooks, "no-hooks", false, "Don't run template, config or profile hooks")
+	initCmd.Flags().BoolVar(&hooksOnly, "hooks-only", false, "Only run the hooks in an existing project, generate nothing")
+	initCmd.Flags().BoolVar(&trustHooks, "trust-hooks", false, "Run hooks of custom templates and .scaffoldrc, profiles included, without asking")
+	addOutputFlags(initCmd)
+	initCmd.MarkFlagsMutuallyExclusive("no-hooks", "hooks-only")
 
 	listCmd := &cobra.Command{
 		Use:   "list",
 		Short: "List available templates",
-		RunE:  runList,
+		Long: `List available templates by category.
+
+Flags:
+  --tag name    Only list templates with this tag, e.g. http or testing`,
+		RunE: runList,
 	}
+	listCmd.Flags().StringVar(&listTag, "tag", "", "Only list templates with this tag")
 
 	infoCmd := &cobra.Command{
 		Use:   "info <template>",
 		Short: "Show template details",
-		Long:  `Show detailed information about a template including files and directories that will be created.`,
-		Args:  cobra.ExactArgs(1),
-		RunE:  runInfo,
+		Long: `Show detailed information about a template including files and directories that will be created.
+
+Paths are shown as they render for a project named my-project with the
+variables' defaults; --name and --var show them for other values.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runInfo,
 	}
+	infoCmd.Flags().StringVar(&initName, "name", "", "Project name paths are rendered for (default my-project)")
+	infoCmd.Flags().StringArrayVar(&varArgs, "var", nil, "Template variable as key=value (repeatable)")
 
 	configCmd := &cobra.Command{
 		Use:   "config",
 		Short: "Show or edit configuration",
-		RunE:  runConfig,
+		Long: `Show or edit configuration.
+
+Values are merged from these layers, later ones winning:
+  1. Built-in defaults
+  2. $XDG_CONFIG_HOME/scaffold/config.json, or the legacy ~/.scaffold/config.json
+  3. .scaffoldrc in the current directory or the nearest parent (same JSON keys)
+  4. The selected profile (--profile, SCAFFOLD_PROFILE or the profile key)
+  5. SCAFFOLD_<KEY> environment variables, e.g. SCAFFOLD_MODULE_PREFIX
+  6. Command line flags such as 'init --license'
+
+set, unset and edit change the user config file (layer 2).
+
+Keys:
+  author            Copyright holder written to LICENSE and README
+  default_license   MIT, "Apache 2.0", "GPL 3.0" or None
+  module_prefix     Go module path prefix, e.g. github.com/jane
+  auto_git          Run git init for new projects (true/false)
+  auto_install      Run go mod tidy after init (true/false), see 'init --tidy'
+  profile           Profile used when --profile isn't given
+  hooks             {"pre": [...], "post": [...]} hooks run by init, each
+                    with run or command and optionally name, dir, env,
+                    timeout, requires and continue_on_error, or a string
+                    short for {"run": ...} (edit only)
+
+Examples:
+  scaffold config                              # Show all values
+  scaffold config set author "Jane Doe"
+  scaffold config set module_prefix github.com/jane
+  scaffold config get module_prefix
+  scaffold config unset author                 # Back to the default
+  scaffold config edit                         # Open in $EDITOR
+  scaffold config validate                     # Report problems in the file
+  scaffold config path
+  scaffold config profile add work --license "Apache 2.0" --author "ACME Corp"
+  scaffold config profile use work`,
+		RunE: runConfig,
 	}
 
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage named config profiles",
+		Long: `Manage named config profiles.
+
+A profile bundles defaults for one kind of project, e.g. employer work
+versus open-source side projects. Its author, module prefix and license
+override the config file; its template, components and hooks are used by
+'scaffold init'. Select a profile with --profile, SCAFFOLD_PROFILE or
+'scaffold config profile use'.
+
+Profiles live under "profiles" in the config file and may also be defined
+in a .scaffoldrc. A profile's "hooks" are listed like the hooks key's pre
+and post hooks and run after post-init. Like the hooks key, the hooks of a
+profile from a .scaffoldrc only run once you trust them.
+
+Examples:
+  scaffold config profile list
+  scaffold config profile add work --author "ACME Corp" \
+      --module-prefix git.acme.com/platform --license "Apache 2.0" \
+      --template go-api --component makefile --hook "make setup"
+  scaffold config profile add personal --module-prefix github.com/jane --license MIT
+  scaffold config profile use personal
+  scaffold init go-cli --profile work --name tool`,
+		Args: cobra.NoArgs,
+		RunE: runConfigProfileList,
+	}
+	profileAddCmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Create a profile",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runConfigProfileAdd,
+	}
+	profileAddCmd.Flags().StringVar(&profileAuthor, "author", "", "Copyright holder")
+	profileAddCmd.Flags().StringVar(&profileModule, "module-prefix", "", "Go module path prefix")
+	profileAddCmd.Flags().StringVar(&profileLicense, "license", "", "Default license")
+	profileAddCmd.Flags().StringVar(&profileTemplate, "template", "", "Template used when init is given none")
+	profileAddCmd.Flags().StringArrayVar(&profileComponents, "component", nil, "Component added to new projects (repeatable)")
+	profileAddCmd.Flags().StringArrayVar(&profileHooks, "hook", nil, "Shell command run in new projects (repeatable)")
+	profileCmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "List profiles",
+			Args:  cobra.NoArgs,
+			RunE:  runConfigProfileList,
+		},
+		profileAddCmd,
+		&cobra.Command{
+			Use:   "use <name>",
+			Short: "Make a profile the default",
+			Args:  cobra.ExactArgs(1),
+			RunE:  runConfigProfileUse,
+		},
+	)
+	configCmd.AddCommand(profileCmd)
+	configCmd.AddCommand(
+		&cobra.Command{
+			Use:   "set <key> <value>",
+			Short: "Set a configuration value",
+			Args:  cobra.ExactArgs(2),
+			RunE:  runConfigSet,
+		},
+		&cobra.Command{
+			Use:   "get <key>",
+			Short: "Print a configuration value",
+			Args:  cobra.ExactArgs(1),
+			RunE:  runConfigGet,
+		},
+		&cobra.Command{
+			Use:   "unset <key>",
+			Short: "Reset a configuration value to its default",
+			Args:  cobra.ExactArgs(1),
+			RunE:  runConfigUnset,
+		},
+		&cobra.Command{
+			Use:   "edit",
+			Short: "Open the config file in $EDITOR",
+			Args:  cobra.NoArgs,
+			RunE:  runConfigEdit,
+		},
+		&cobra.Command{
+			Use:   "path",
+			Short: "Print the config file location",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				path, err := config.Path()
+				if err != nil {
+					return err
+				}
+				fmt.Println(path)
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "validate",
+			Short: "Check the config file for unknown keys, wrong types and invalid values",
+			Args:  cobra.NoArgs,
+			RunE:  runConfigValidate,
+		},
+	)
+
 	addCmd := &cobra.Command{
 		Use:   "add <component>",
 		Short: "Add component to existing project",
 		Long: `Add reusable components to an existing project.
 
+Component files are filled in for the project in the current directory:
+the module path and Go version come from go.mod, binary names and main
+package paths from cmd/* (or the root package main).
+
 Available Components:
   dockerfile       Multi-stage Dockerfile for Go applications
   makefile         Common Makefile targets (build, test, lint, run)
@@ -151,13 +445,100 @@ Examples:
   scaffold add dockerfile       # Add multi-stage Dockerfile
   scaffold add middleware       # Add HTTP middleware collection
   scaffold add github-actions   # Add CI workflow
-  scaffold add dockerfile --force  # Overwrite existing files`,
+  scaffold add gitignore --conflict=merge     # Add missing lines to .gitignore
+  scaffold add makefile --conflict=merge      # Add missing Makefile targets
+  scaffold add readme --conflict=backup       # Keep the old one as README.md.orig
+  scaffold add dockerfile --conflict=prompt   # Show a diff and ask
+
+Existing files are never touched unless --conflict is given:
+  skip, overwrite, backup, prompt or merge
+
+Projects created by 'scaffold init' record the component in .scaffold.json.`,
 		Args: cobra.ExactArgs(1),
 		RunE: runAdd,
 	}
+	addCmd.Flags().StringVar(&conflictFlag, "conflict", "", "How to treat existing files: "+conflict.ModeNames())
 	addCmd.Flags().BoolVar(&force, "force", false, "Overwrite existing files")
+	addCmd.Flags().MarkDeprecated("force", "use --conflict=overwrite")
+	addCmd.Flags().BoolVar(&followSymlinks, "follow-symlinks", false, "Write through symbolic links in the project that stay inside it")
+	addOutputFlags(addCmd)
+
+	inspectCmd := &cobra.Command{
+		Use:   "inspect [dir]",
+		Short: "Describe an existing Go project",
+		Long: `Inspect a project directory (the current one by default) and report:
+  - the module path and Go version from go.mod
+  - main packages (cmd/* and the root package)
+  - the kind of project (http, grpc, cli), from the imports of its Go files
+  - an existing Dockerfile, Makefile and CI configuration
+  - a frontend folder (frontend/, web/, ui/, client/ or app/ with a package.json)
+  - the git branch and working tree status
+
+Examples:
+  scaffold inspect
+  scaffold inspect ../billing --json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runInspect,
+	}
+	inspectCmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the report as JSON")
+
+	diffCmd := &cobra.Command{
+		Use:   "diff [dir]",
+		Short: "Show how a project drifted from its template",
+		Long: `Regenerate a project in memory from its .scaffold.json (template, variables
+and components) and compare it with the files on disk.
+
+Each file is reported as:
+  unchanged       identical to what scaffold generates
+  user-modified   edited since it was generated (a unified diff follows)
+  missing         generated but deleted from the project
+  extra           in a generated directory but not generated
+
+go.mod and other files scaffold doesn't render are compared with the hash
+recorded in .scaffold.json.
+
+Files rendered with now, year or uuid differ on every run and always show
+as user-modified.
+
+Examples:
+  scaffold diff
+  scaffold diff services/billing --summary
+  scaffold diff --exit-code      # Exit with status 1 when the project drifted`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runDiff,
+	}
+	diffCmd.Flags().BoolVar(&diffSummary, "summary", false, "List file statuses without the diffs")
+	diffCmd.Flags().BoolVar(&diffExitCode, "exit-code", false, "Exit with status 1 when any file differs")
+
+	upgradeCmd := &cobra.Command{
+		Use:   "upgrade [dir]",
+		Short: "Merge a newer template version into a project",
+		Long: `Bring a project created by 'scaffold init' to a newer version of its template.
+
+The version recorded in .scaffold.json (base) and the new version (theirs)
+are rendered with the recorded variables and merged into the project files
+(yours), like git merges branches:
+  - files you never touched are replaced
+  - your edits and the template changes are combined
+  - overlapping changes get <<<<<<< yours / ======= / >>>>>>> markers
+  - files the template dropped are deleted, unless you changed them
+
+Files added by components are left alone. Everything works offline: earlier
+versions of built-in templates are embedded in scaffold, custom templates
+keep theirs in <template>/.history/<version>/. Commit your work first.
 
-	rootCmd.AddCommand(initCmd, listCmd, infoCmd, configCmd, addCmd)
+Examples:
+  scaffold upgrade --dry-run     # Show what would change
+  scaffold upgrade
+  scaffold upgrade services/billing --to 1.1.0`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runUpgrade,
+	}
+	upgradeCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would change without writing")
+	upgradeCmd.Flags().StringVar(&upgradeTo, "to", "", "Template version to upgrade to (default the current one)")
+	upgradeCmd.Flags().BoolVar(&followSymlinks, "follow-symlinks", false, "Write through symbolic links in the project that stay inside it")
+
+	rootCmd.AddCommand(initCmd, listCmd, infoCmd, inspectCmd, diffCmd, upgradeCmd, configCmd, addCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
@@ -167,77 +548,425 @@ Examples:
 func runInit(cmd *cobra.Command, args []string) error {
 	var cfg tui.ProjectConfig
 
+	reporter, err := newReporter()
+	if err != nil {
+		return err
+	}
+
+	userCfg := config.DefaultConfig()
+	origins := config.Origins{}
+	if !ignoreConfig {
+		loaded, loadedOrigins, err := config.LoadProfile(profileName)
+		if err != nil {
+			return fmt.Errorf("%w\n\nFix the configuration (see 'scaffold config validate') or run with --ignore-config", err)
+		}
+		userCfg, origins = loaded, loadedOrigins
+	}
+	profile, _ := userCfg.ActiveProfile()
+	if err := checkProfile(profile); err != nil {
+		return err
+	}
+
+	vars, err := parseVars(varArgs)
+	if err != nil {
+		return err
+	}
+	license := userCfg.DefaultLicense
+	if cmd.Flags().Changed("license") {
+		license = initLicense
+	}
+	if !config.IsValidLicense(license) {
+		return fmt.Errorf("unknown license %q, expected one of: %s", license, strings.Join(config.Licenses, ", "))
+	}
+
+	interactive := !nonInteractive && stdinIsTerminal()
+	mode, ask, err := conflictOptions(interactive)
+	if err != nil {
+		return err
+	}
+
+	hookOpts, err := hookOptions(userCfg, origins, interactive, reporter)
+	if err != nil {
+		return err
+	}
+	if profile, err = trustProfileHooks(profile, hookOpts); err != nil {
+		return err
+	}
+
+	templateName := profile.Template
 	if len(args) == 1 {
-		templateName := args[0]
+		templateName = args[0]
+	}
+	if hooksOnly {
+		return runHooksOnly(templateName, vars, hookOpts)
+	}
+
+	// Check the flags before asking anything
+	if initName != "" {
+		if err := safefs.ValidateName(initName); err != nil {
+			return fmt.Errorf("invalid project name %q: %w", initName, err)
+		}
+	}
+	if initModule != "" {
+		if err := project.CheckModulePath(initModule); err != nil {
+			return fmt.Errorf("invalid module path %q: %w", initModule, err)
+		}
+	}
+
+	if len(args) == 1 || (templateName != "" && !interactive) {
 		tmpl, err := templates.GetTemplate(templateName)
 		if err != nil {
 			return fmt.Errorf("template '%s' not found. Use 'scaffold list' to see available templates", templateName)
 		}
 
-		// Prompt for project name
-		var projectName string
-		fmt.Print("Project name: ")
-		fmt.Scanln(&projectName)
+		projectName := initName
 		if projectName == "" {
-			projectName = "my-project"
+			if !interactive {
+				return fmt.Errorf("project name is required in non-interactive mode, pass --name")
+			}
+			// Prompt for project name
+			fmt.Fprint(promptOut, "Project name: ")
+			fmt.Scanln(&projectName)
+			if projectName == "" {
+				projectName = "my-project"
+			}
 		}
 
 		cfg = tui.ProjectConfig{
 			ProjectName:   projectName,
 			TemplateName:  tmpl.Name,
-			License:       "MIT",
-			IncludeDocker: false,
-			InitGit:       true,
+			License:       license,
+			IncludeDocker: initDocker,
+			InitGit:       userCfg.AutoGit && !initNoGit,
+			Vars:          vars,
 		}
 	} else {
-		var err error
-		cfg, err = tui.Run()
+		if !interactive {
+			return fmt.Errorf("a template is required in non-interactive mode: scaffold init <template> --name <name>")
+		}
+		cfg, err = tui.RunWithDefaults(tui.ProjectConfig{
+			ProjectName:  initName,
+			TemplateName: templateName,
+			License:      license,
+			InitGit:      userCfg.AutoGit && !initNoGit,
+			Vars:         vars,
+		})
 		if err != nil {
 			return err
 		}
+		// Explicit flags win over interactive answers
+		if cmd.Flags().Changed("license") {
+			cfg.License = license
+		}
+		if cmd.Flags().Changed("docker") {
+			cfg.IncludeDocker = initDocker
+		}
+		if initNoGit {
+			cfg.InitGit = false
+		}
 	}
 
+	cfg.Author = userCfg.Author
+	cfg.ModuleName = initModule
+	if cfg.ModuleName == "" {
+		cfg.ModuleName = generator.ModulePath(userCfg.ModulePrefix, cfg.ProjectName)
+	}
+	cfg.OutputDir = initOutputDir
+
 	// Set generator options
-	opts := generator.Options{
-		DryRun: dryRun,
-		Force:  force,
+	opts := hookOpts
+	opts.DryRun = dryRun
+	opts.Conflict = mode
+	opts.Ask = ask
+	opts.FollowSymlinks = followSymlinks
+	opts.Tidy = userCfg.AutoInstall
+	if cmd.Flags().Changed("tidy") {
+		opts.Tidy = initTidy
 	}
 
-	return generator.GenerateWithOptions(cfg, opts)
+	if err := generator.GenerateWithOptions(cfg, opts); err != nil {
+		return err
+	}
+
+	// Profile components land in a project that was just generated, so by
+	// default they are merged with the template's files instead of failing
+	if mode == conflict.Fail {
+		mode = conflict.Merge
+	}
+	projectDir := filepath.Join(cfg.OutputDir, cfg.ProjectName)
+	return applyProfile(projectDir, profile, components.Options{Conflict: mode, Ask: ask, FollowSymlinks: followSymlinks}, reporter)
+}
+
+// conflictOptions turns --conflict, or the deprecated --force, into a mode
+// and for prompt mode an Asker reading answers from the terminal
+func conflictOptions(interactive bool) (conflict.Mode, conflict.Asker, error) {
+	if conflictFlag == "" {
+		if force {
+			return conflict.Overwrite, nil, nil
+		}
+		return conflict.Fail, nil, nil
+	}
+
+	mode, err := conflict.ParseMode(conflictFlag)
+	if err != nil {
+		return mode, nil, err
+	}
+	if mode != conflict.Prompt {
+		return mode, nil, nil
+	}
+	if !interactive {
+		return mode, nil, fmt.Errorf("--conflict=prompt needs a terminal, choose another mode")
+	}
+	return mode, conflict.NewPrompter(os.Stdin, promptOut), nil
+}
+
+// addOutputFlags adds the flags choosing how progress is shown
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Only show warnings and errors")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Also show unchanged files, file modes and the commands scaffold runs")
+	cmd.Flags().StringVar(&outputFormat, "output", "", "Progress format: pretty, plain or json (one event per line) (default pretty on a terminal)")
+	cmd.MarkFlagsMutuallyExclusive("quiet", "verbose")
+}
+
+// newReporter returns the reporter --output, --quiet and --verbose ask for.
+// Without --output, progress is pretty on a terminal and plain when stdout
+// is redirected or NO_COLOR is set.
+func newReporter() (generator.Reporter, error) {
+	format := progress.Pretty
+	if outputFormat != "" {
+		var err error
+		if format, err = progress.ParseFormat(outputFormat); err != nil {
+			return nil, err
+		}
+	} else if !stdoutIsTerminal() || os.Getenv("NO_COLOR") != "" {
+		format = progress.Plain
+	}
+	if format == progress.JSON {
+		promptOut = os.Stderr
+	}
+
+	level := progress.Normal
+	switch {
+	case quiet:
+		level = progress.Quiet
+	case verbose:
+		level = progress.Verbose
+	}
+	return progress.New(format, level, os.Stdout, os.Stderr), nil
+}
+
+// reportComponentResults reports what happened to each file of a component
+func reportComponentResults(r generator.Reporter, name string, results []components.FileResult) {
+	for _, res := range results {
+		r.Report(generator.Event{Kind: generator.FileResolved, Component: name, Path: res.Path, Action: res.Action, Backup: res.Backup, Reason: res.Reason})
+	}
+}
+
+// checkProfile fails early when the profile names a template or component
+// that doesn't exist, before anything is generated
+func checkProfile(p config.Profile) error {
+	if p.Template != "" {
+		if _, err := templates.GetTemplate(p.Template); err != nil {
+			return fmt.Errorf("profile template %q not found. Use 'scaffold list' to see available templates", p.Template)
+		}
+	}
+	for _, name := range p.Components {
+		if _, ok := components.GetComponent(name); !ok {
+			return fmt.Errorf("profile component %q not found", name)
+		}
+	}
+	return nil
+}
+
+// applyProfile adds the profile's components to the new project and runs its
+// hooks there, in order. In dry-run mode it only lists them.
+func applyProfile(projectDir string, p config.Profile, opts components.Options, r generator.Reporter) error {
+	if len(p.Components) == 0 && len(p.Hooks) == 0 {
+		return nil
+	}
+
+	if dryRun {
+		for _, name := range p.Components {
+			r.Report(generator.Event{Kind: generator.ComponentStarted, DryRun: true, Component: name})
+		}
+		if !noHooks {
+			for _, hook := range p.Hooks {
+				r.Report(generator.Event{Kind: generator.HookStarted, DryRun: true, Hook: hook.String(), Stage: "profile"})
+			}
+		}
+		return nil
+	}
+
+	for _, name := range p.Components {
+		r.Report(generator.Event{Kind: generator.ComponentStarted, Component: name})
+		results, err := components.AddComponentWithOptions(projectDir, name, opts)
+		if err != nil {
+			return fmt.Errorf("profile component %s: %w", name, err)
+		}
+		reportComponentResults(r, name, results)
+	}
+	if noHooks || len(p.Hooks) == 0 {
+		return nil
+	}
+	r.Report(generator.Event{Kind: generator.StepStarted, Step: generator.StepProfileHooks})
+	_, err := hooks.Runner{Dir: projectDir, Progress: generator.HookProgress("profile", r)}.Run(p.Hooks)
+	return err
+}
+
+// hookOptions returns generator options with the hooks from the config and
+// how to decide on hooks that need trust: custom templates and a
+// .scaffoldrc, which may come with a cloned repository
+func hookOptions(cfg *config.Config, origins config.Origins, interactive bool, r generator.Reporter) (generator.Options, error) {
+	opts := generator.Options{NoHooks: noHooks, Reporter: r}
+	switch {
+	case trustHooks:
+		opts.Trust = func(string, []hooks.Hook) (bool, error) { return true, nil }
+	case interactive:
+		opts.Trust = promptTrust
+	}
+	if noHooks {
+		return opts, nil
+	}
+
+	opts.PreHooks, opts.PostHooks = cfg.Hooks.Pre, cfg.Hooks.Post
+	trusted, err := trustConfigHooks(opts, origins["hooks"], slices.Concat(opts.PreHooks, opts.PostHooks))
+	if err != nil {
+		return opts, err
+	}
+	if !trusted {
+		opts.PreHooks, opts.PostHooks = nil, nil
+	}
+	return opts, nil
+}
+
+// trustProfileHooks asks, like for the hooks key, whether to run the hooks
+// of a profile defined in a .scaffoldrc, and drops them when they aren't
+// trusted
+func trustProfileHooks(p config.Profile, opts generator.Options) (config.Profile, error) {
+	if opts.NoHooks {
+		return p, nil
+	}
+	trusted, err := trustConfigHooks(opts, p.Source, p.Hooks)
+	if err != nil {
+		return p, err
+	}
+	if !trusted {
+		p.Hooks = nil
+	}
+	return p, nil
+}
+
+// trustConfigHooks reports whether hooks from the config file source may
+// run. Hooks of a .scaffoldrc, which may come with a cloned repository,
+// need opts.Trust, the user's own config is trusted.
+func trustConfigHooks(opts generator.Options, source string, hs []hooks.Hook) (bool, error) {
+	// A dry run only lists the hooks, so there is nothing to trust yet
+	if dryRun || len(hs) == 0 || filepath.Base(source) != config.ProjectFile {
+		return true, nil
+	}
+	if opts.Trust != nil {
+		trusted, err := opts.Trust(source, hs)
+		if err != nil || trusted {
+			return trusted, err
+		}
+	}
+	opts.Reporter.Report(generator.Event{Kind: generator.Warning, Message: fmt.Sprintf("Skipping the hooks of %s, they weren't trusted", source)})
+	return false, nil
+}
+
+// promptTrust shows the hooks from source and asks whether to run them
+func promptTrust(source string, hs []hooks.Hook) (bool, error) {
+	fmt.Fprintf(promptOut, "\n%s wants to run these commands:\n", source)
+	for _, h := range hs {
+		dir := valueOrDefault(h.Dir, ".")
+		fmt.Fprintf(promptOut, "  $ %s %s\n", h, dimStyle.Render("(in "+dir+")"))
+	}
+	fmt.Fprint(promptOut, "Run them? [y/N] ")
+	var answer string
+	fmt.Scanln(&answer)
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// runHooksOnly runs the hooks of an existing project's template, found in
+// its .scaffold.json unless given, without generating anything
+func runHooksOnly(templateName string, vars map[string]string, opts generator.Options) error {
+	dir := filepath.Join(initOutputDir, initName)
+	if dir == "" {
+		dir = "."
+	}
+	if st, err := os.Stat(dir); err != nil || !st.IsDir() {
+		return fmt.Errorf("--hooks-only runs in an existing project, %s is not a directory", dir)
+	}
+
+	cfg := tui.ProjectConfig{TemplateName: templateName, Vars: vars}
+	if lock, err := lockfile.Read(dir); err == nil {
+		if templateName == "" || templateName == lock.Template {
+			tmpl, err := templates.GetTemplate(lock.Template)
+			if err != nil {
+				return err
+			}
+			cfg = drift.ProjectConfig(lock, tmpl)
+		}
+	} else if templateName == "" {
+		return fmt.Errorf("%s has no readable %s, pass the template: scaffold init <template> --hooks-only", dir, lockfile.FileName)
+	}
+	if cfg.ProjectName == "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		cfg.ProjectName = filepath.Base(abs)
+	}
+	return generator.RunHooks(dir, cfg, opts)
+}
+
+// stdinIsTerminal reports whether prompts can be answered
+func stdinIsTerminal() bool {
+	fd := os.Stdin.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// stdoutIsTerminal reports whether output is seen by a person
+func stdoutIsTerminal() bool {
+	fd := os.Stdout.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
 }
 
 func runList(cmd *cobra.Command, args []string) error {
 	tmpls := templates.GetAllTemplates()
 
 	// Group templates by category
-	categories := map[string][]templates.Template{
-		"Project":   {},
-		"Learning":  {},
-		"Fullstack": {},
-		"Skill":     {},
-	}
-
+	categories := map[string][]templates.Template{}
 	for _, t := range tmpls {
-		switch {
-		case strings.HasPrefix(t.Name, "learn-"):
-			categories["Learning"] = append(categories["Learning"], t)
-		case t.Name == "fullstack":
-			categories["Fullstack"] = append(categories["Fullstack"], t)
-		case strings.HasPrefix(t.Name, "challenge-") ||
-			strings.HasPrefix(t.Name, "mini-") ||
-			strings.HasPrefix(t.Name, "refactoring-") ||
-			strings.HasPrefix(t.Name, "code-review-"):
-			categories["Skill"] = append(categories["Skill"], t)
-		default:
-			categories["Project"] = append(categories["Project"], t)
+		if listTag != "" && !slices.Contains(t.Tags, listTag) {
+			continue
 		}
+		category := valueOrDefault(t.Category, templates.CategoryProject)
+		categories[category] = append(categories[category], t)
+	}
+	if len(categories) == 0 {
+		return fmt.Errorf("no template is tagged %q", listTag)
 	}
 
 	fmt.Println(titleStyle.Render("📦 Available Templates"))
 	fmt.Println()
 
-	// Print in order
-	order := []string{"Project", "Fullstack", "Learning", "Skill"}
+	// Print in order, custom categories last
+	order := slices.Clone(templates.Categories)
+	var extra []string
+	for cat := range categories {
+		if !slices.Contains(order, cat) {
+			extra = append(extra, cat)
+		}
+	}
+	sort.Strings(extra)
+	order = append(order, extra...)
+
 	for _, cat := range order {
 		list := categories[cat]
 		if len(list) == 0 {
@@ -272,12 +1001,60 @@ func runInfo(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 	fmt.Println(tmpl.Description)
 	fmt.Println()
+	if tmpl.Version != "" {
+		fmt.Println(dimStyle.Render("Version: " + tmpl.Version))
+		if versions, err := templates.Versions(tmpl.Name); err == nil && len(versions) > 1 {
+			fmt.Println(dimStyle.Render("Available for upgrades: " + strings.Join(versions, ", ")))
+		}
+	}
+	if tmpl.Source != templates.SourceBuiltIn {
+		fmt.Println(dimStyle.Render("Source: " + tmpl.Source))
+	}
+	fmt.Println(dimStyle.Render("Category: " + valueOrDefault(tmpl.Category, templates.CategoryProject)))
+	if len(tmpl.Tags) > 0 {
+		fmt.Println(dimStyle.Render("Tags: " + strings.Join(tmpl.Tags, ", ")))
+	}
+	if tmpl.Extends != "" {
+		fmt.Println(dimStyle.Render("Extends: " + tmpl.Extends))
+	}
+	if len(tmpl.Includes) > 0 {
+		fmt.Println(dimStyle.Render("Includes: " + strings.Join(tmpl.Includes, ", ")))
+	}
+	fmt.Println()
+
+	// Paths are shown rendered, with the template path when it differs
+	vars, err := parseVars(varArgs)
+	if err != nil {
+		return err
+	}
+	paths, err := generator.RenderPaths(tmpl, tui.ProjectConfig{
+		ProjectName:  valueOrDefault(initName, "my-project"),
+		TemplateName: tmpl.Name,
+		Vars:         vars,
+	})
+	if err != nil {
+		fmt.Printf("⚠️  Paths can't be rendered: %v\n\n", err)
+	}
+	rendered := func(p string) (string, []string) {
+		if r, ok := paths[p]; ok && r != p {
+			return r, []string{"template path " + p}
+		}
+		return p, nil
+	}
 
 	// Show directories
 	if len(tmpl.Directories) > 0 {
 		fmt.Println(categoryStyle.Render("Directories:"))
 		for _, dir := range tmpl.Directories {
-			fmt.Printf("  📁 %s/\n", dir)
+			path, notes := rendered(dir)
+			if conds := tmpl.DirectoryConditions(dir); len(conds) > 0 {
+				notes = append(notes, "when "+strings.Join(conds, " && "))
+			}
+			if len(notes) > 0 {
+				fmt.Printf("  📁 %-40s %s\n", path+"/", dimStyle.Render(strings.Join(notes, ", ")))
+			} else {
+				fmt.Printf("  📁 %s/\n", path)
+			}
 		}
 		fmt.Println()
 	}
@@ -286,7 +1063,56 @@ func runInfo(cmd *cobra.Command, args []string) error {
 	if len(tmpl.Files) > 0 {
 		fmt.Println(categoryStyle.Render("Files:"))
 		for _, f := range tmpl.Files {
-			fmt.Printf("  📄 %s\n", f.Path)
+			// Files inherited or included say where they come from
+			path, notes := rendered(f.Path)
+			if f.Layer != "" && f.Layer != tmpl.Name {
+				notes = append(notes, "from "+f.Layer)
+			}
+			if f.Static {
+				notes = append(notes, "copied as is")
+			}
+			if mode := render.FileMode(f.Mode, f.Content); mode != 0644 {
+				notes = append(notes, fmt.Sprintf("mode %04o", mode))
+			}
+			conds := tmpl.DirectoryConditions(f.Path)
+			if f.When != "" {
+				conds = append(conds, f.When)
+			}
+			if len(conds) > 0 {
+				notes = append(notes, "when "+strings.Join(conds, " && "))
+			}
+			if len(notes) > 0 {
+				fmt.Printf("  📄 %-40s %s\n", path, dimStyle.Render(strings.Join(notes, ", ")))
+			} else {
+				fmt.Printf("  📄 %s\n", path)
+			}
+		}
+		fmt.Println()
+	}
+
+	// Show variables
+	if len(tmpl.Variables) > 0 {
+		fmt.Println(categoryStyle.Render("Variables:"))
+		for _, v := range tmpl.Variables {
+			fmt.Printf("  %-16s %-7s default: %-10s %s\n", v.Name, valueOrDefault(v.Type, templates.VarString), valueOrDefault(v.Default, "-"), dimStyle.Render(v.Help))
+		}
+		fmt.Println()
+	}
+
+	// Show modules required in go.mod
+	if len(tmpl.Dependencies) > 0 {
+		fmt.Println(categoryStyle.Render("Dependencies:"))
+		for _, d := range tmpl.Dependencies {
+			fmt.Printf("  %-40s %s\n", d.Path, d.Version)
+		}
+		fmt.Println()
+	}
+
+	// Show commands run after init
+	if len(tmpl.PostInit) > 0 {
+		fmt.Println(categoryStyle.Render("Post-init hooks:"))
+		for _, h := range tmpl.PostInit {
+			fmt.Printf("  $ %s %s\n", h, dimStyle.Render("(in "+valueOrDefault(h.Dir, ".")+"/)"))
 		}
 		fmt.Println()
 	}
@@ -299,23 +1125,460 @@ func runInfo(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runInspect(cmd *cobra.Command, args []string) error {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if st, err := os.Stat(dir); err != nil {
+		return err
+	} else if !st.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	info, err := project.Inspect(dir)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		out, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	fmt.Println(titleStyle.Render("🔎 Project: " + info.Dir))
+	fmt.Println()
+	fmt.Printf("  %-14s %s\n", "Module:", valueOrDefault(info.Module, dimStyle.Render("none (no go.mod)")))
+	fmt.Printf("  %-14s %s\n", "Go version:", valueOrDefault(info.GoVersion, "-"))
+	fmt.Printf("  %-14s %s\n", "Kind:", valueOrDefault(strings.Join(info.Kinds, ", "), "-"))
+	fmt.Printf("  %-14s %s\n", "Dockerfile:", yesNo(info.Dockerfile))
+	fmt.Printf("  %-14s %s\n", "Makefile:", yesNo(info.Makefile))
+	fmt.Printf("  %-14s %s\n", "CI:", valueOrDefault(strings.Join(info.CI, ", "), "-"))
+	fmt.Printf("  %-14s %s\n", "Frontend:", valueOrDefault(info.Frontend, "-"))
+
+	git := "not a git repository"
+	if info.Git != nil {
+		git = valueOrDefault(info.Git.Branch, "detached HEAD")
+		if info.Git.Clean() {
+			git += ", clean"
+		} else {
+			git += fmt.Sprintf(", %d modified, %d untracked", info.Git.Modified, info.Git.Untracked)
+		}
+	}
+	fmt.Printf("  %-14s %s\n", "Git:", git)
+	fmt.Println()
+
+	if len(info.MainPackages) > 0 {
+		fmt.Println(categoryStyle.Render("Main packages:"))
+		for _, m := range info.MainPackages {
+			fmt.Printf("  %-16s %s\n", m.Name, dimStyle.Render(m.Path))
+		}
+	}
+	return nil
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	report, err := drift.Check(dir)
+	if err != nil {
+		return err
+	}
+
+	lock := report.Lock
+	fmt.Println(titleStyle.Render(fmt.Sprintf("🔍 Comparing with %s %s", lock.Template, report.TemplateVersion)))
+	if lock.TemplateVersion != report.TemplateVersion {
+		fmt.Println(dimStyle.Render(fmt.Sprintf("Version %s the project was generated from is not available", valueOrDefault(lock.TemplateVersion, "-"))))
+	}
+	if report.LatestVersion != report.TemplateVersion {
+		fmt.Println(dimStyle.Render(fmt.Sprintf("Version %s is available, see 'scaffold upgrade'", report.LatestVersion)))
+	}
+	fmt.Println()
+
+	for _, f := range report.Files {
+		line := fmt.Sprintf("  %-14s %s", f.Status, f.Path)
+		if f.Status == drift.Unchanged {
+			line = dimStyle.Render(line)
+		}
+		fmt.Println(line)
+	}
+
+	if !diffSummary {
+		for _, f := range report.Files {
+			if d := f.Diff(); d != "" {
+				fmt.Println()
+				fmt.Print(d)
+			}
+		}
+	}
+
+	var counts []string
+	for _, s := range drift.Statuses {
+		counts = append(counts, fmt.Sprintf("%d %s", report.Count(s), s))
+	}
+	fmt.Println()
+	fmt.Println(strings.Join(counts, ", "))
+
+	if diffExitCode && report.Drifted() {
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		return errDrifted
+	}
+	return nil
+}
+
+func runUpgrade(cmd *cobra.Command, args []string) error {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	plan, err := upgrade.NewPlan(dir, upgradeTo)
+	if err != nil {
+		return err
+	}
+	plan.FollowSymlinks = followSymlinks
+
+	name := plan.Lock.Template
+	if plan.From == plan.To {
+		fmt.Printf("Already at %s %s\n", name, plan.To)
+		return nil
+	}
+	fmt.Println(titleStyle.Render(fmt.Sprintf("⬆️  Upgrading %s %s → %s", name, plan.From, plan.To)))
+	if dryRun {
+		fmt.Println(dimStyle.Render("Dry run, nothing is written"))
+	}
+	fmt.Println()
+
+	for _, d := range plan.Dirs {
+		fmt.Printf("  %-10s %s/\n", "create", d)
+	}
+	for _, f := range plan.Files {
+		line := fmt.Sprintf("  %-10s %s", f.Action, f.Path)
+		switch {
+		case f.Action == upgrade.Unchanged:
+			line = dimStyle.Render(line)
+		case f.Conflicts > 0:
+			line += fmt.Sprintf(" (%d conflicts)", f.Conflicts)
+		case f.Reason != "":
+			line += dimStyle.Render(" (" + f.Reason + ")")
+		}
+		fmt.Println(line)
+	}
+	fmt.Println()
+
+	if dryRun {
+		fmt.Println("💡 Remove --dry-run to apply the upgrade")
+		return nil
+	}
+	if err := plan.Apply(); err != nil {
+		return err
+	}
+
+	if n := plan.Conflicts(); n > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("upgraded to %s with %d conflicts in %d files, resolve the <<<<<<< markers", plan.To, n, plan.Count(upgrade.Conflict))
+	}
+	fmt.Printf("✅ Upgraded to %s %s\n", name, plan.To)
+	return nil
+}
+
+// errDrifted makes scaffold exit with status 1 without printing anything else
+var errDrifted = errors.New("project drifted from its template")
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
 func runConfig(cmd *cobra.Command, args []string) error {
-	cfg := config.Load()
+	cfg, origins, loadErr := config.LoadProfile(profileName)
 
 	fmt.Println(titleStyle.Render("⚙️  Configuration"))
 	fmt.Println()
-	fmt.Printf("  Author:         %s\n", valueOrDefault(cfg.Author, "(not set)"))
-	fmt.Printf("  Default License: %s\n", cfg.DefaultLicense)
-	fmt.Printf("  Module Prefix:  %s\n", cfg.ModulePrefix)
-	fmt.Printf("  Auto Git:       %v\n", cfg.AutoGit)
-	fmt.Printf("  Auto Install:   %v\n", cfg.AutoInstall)
+	rows := []struct{ label, key, value string }{
+		{"Author", "author", valueOrDefault(cfg.Author, "(not set)")},
+		{"Default License", "default_license", cfg.DefaultLicense},
+		{"Module Prefix", "module_prefix", cfg.ModulePrefix},
+		{"Auto Git", "auto_git", fmt.Sprint(cfg.AutoGit)},
+		{"Auto Install", "auto_install", fmt.Sprint(cfg.AutoInstall)},
+		{"Profile", "profile", valueOrDefault(cfg.Profile, "(none)")},
+	}
+	for _, r := range rows {
+		fmt.Printf("  %-16s %-24s %s\n", r.label+":", r.value, dimStyle.Render("("+origins[r.key]+")"))
+	}
+	if names := cfg.ProfileNames(); len(names) > 0 {
+		fmt.Printf("  %-16s %s\n", "Profiles:", strings.Join(names, ", "))
+	}
+	if n := len(cfg.Hooks.Pre) + len(cfg.Hooks.Post); n > 0 {
+		fmt.Printf("  %-16s %-24s %s\n", "Hooks:", fmt.Sprintf("%d pre, %d post", len(cfg.Hooks.Pre), len(cfg.Hooks.Post)), dimStyle.Render("("+origins["hooks"]+")"))
+	}
 	fmt.Println()
-	fmt.Println(dimStyle.Render("Config file: ~/.scaffold/config.json"))
-	fmt.Println(dimStyle.Render("Custom templates: ~/.scaffold/templates/"))
+	if path, err := config.Path(); err == nil {
+		fmt.Println(dimStyle.Render("Config file: " + path))
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		if rc := config.FindProjectFile(cwd); rc != "" {
+			fmt.Println(dimStyle.Render("Project file: " + rc))
+		}
+	}
+	fmt.Println(dimStyle.Render("Custom templates: " + config.GetCustomTemplatesDir()))
+	fmt.Println(dimStyle.Render("Precedence: default < config file < .scaffoldrc < profile < SCAFFOLD_* env < flags"))
+
+	if loadErr != nil {
+		fmt.Println()
+		fmt.Printf("⚠️  %v\n", loadErr)
+	}
+	return nil
+}
+
+// loadConfigForUpdate loads the user config file for set/unset, refusing to
+// rewrite a file that has problems since saving would drop the bad entries
+func loadConfigForUpdate() (*config.Config, error) {
+	path, err := config.Path()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w\n\nFix it with 'scaffold config edit' first", err)
+	}
+	return cfg, nil
+}
 
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfigForUpdate()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Set(args[0], args[1]); err != nil {
+		return err
+	}
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Printf("✓ %s = %s\n", args[0], args[1])
 	return nil
 }
 
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+	}
+	value, err := cfg.Get(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(value)
+	return nil
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfigForUpdate()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Unset(args[0]); err != nil {
+		return err
+	}
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	value, _ := cfg.Get(args[0])
+	fmt.Printf("✓ %s reset to default (%s)\n", args[0], valueOrDefault(value, "empty"))
+	return nil
+}
+
+// runConfigEdit opens the config file in $VISUAL or $EDITOR, creating it
+// with the current values first so there is something to edit
+func runConfigEdit(cmd *cobra.Command, args []string) error {
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := config.Save(config.DefaultConfig()); err != nil {
+			return fmt.Errorf("failed to create config: %w", err)
+		}
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	parts := strings.Fields(editor)
+	c := exec.Command(parts[0], append(parts[1:], path)...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", parts[0], err)
+	}
+
+	if _, err := config.LoadFile(path); err != nil {
+		return fmt.Errorf("config is invalid after editing: %w", err)
+	}
+	return nil
+}
+
+// runConfigValidate checks every layer: the user file, .scaffoldrc and env
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	if _, err := config.Load(); err != nil {
+		return err
+	}
+	fmt.Println("✓ Configuration is valid")
+	return nil
+}
+
+func runConfigProfileList(cmd *cobra.Command, args []string) error {
+	cfg, _, err := config.LoadProfile(profileName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+	}
+
+	names := cfg.ProfileNames()
+	if len(names) == 0 {
+		fmt.Println("No profiles defined. Create one with 'scaffold config profile add <name>'")
+		return nil
+	}
+
+	fmt.Println(titleStyle.Render("👤 Profiles"))
+	fmt.Println()
+	for _, name := range names {
+		p := cfg.Profiles[name]
+		marker := " "
+		if name == cfg.Profile {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\n", marker, categoryStyle.Render(name))
+		details := []struct{ label, value string }{
+			{"Author", p.Author},
+			{"Module Prefix", p.ModulePrefix},
+			{"License", p.License},
+			{"Template", p.Template},
+			{"Components", strings.Join(p.Components, ", ")},
+			{"Hooks", joinHooks(p.Hooks)},
+		}
+		for _, d := range details {
+			if d.value != "" {
+				fmt.Printf("    %-14s %s\n", d.label+":", d.value)
+			}
+		}
+	}
+	fmt.Println()
+	fmt.Println(dimStyle.Render("* active profile"))
+	return nil
+}
+
+// commandHooks turns shell commands, e.g. from --hook, into hooks
+func commandHooks(commands []string) []hooks.Hook {
+	hs := make([]hooks.Hook, len(commands))
+	for i, c := range commands {
+		hs[i] = hooks.Hook{Run: c}
+	}
+	return hs
+}
+
+// joinHooks lists hooks by name or command line
+func joinHooks(hs []hooks.Hook) string {
+	names := make([]string, len(hs))
+	for i, h := range hs {
+		names[i] = h.String()
+	}
+	return strings.Join(names, "; ")
+}
+
+func runConfigProfileAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := config.ValidateProfileName(name); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfigForUpdate()
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Profiles[name]; ok {
+		return fmt.Errorf("profile %q already exists, change it with 'scaffold config edit'", name)
+	}
+
+	p := config.Profile{
+		Author:       profileAuthor,
+		ModulePrefix: profileModule,
+		License:      profileLicense,
+		Template:     profileTemplate,
+		Components:   profileComponents,
+		Hooks:        commandHooks(profileHooks),
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if err := checkProfile(p); err != nil {
+		return err
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]config.Profile{}
+	}
+	cfg.Profiles[name] = p
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Printf("✓ Added profile %s (activate with 'scaffold config profile use %s')\n", name, name)
+	return nil
+}
+
+// runConfigProfileUse stores the default profile in the user config file.
+// The profile itself may come from any layer, such as a .scaffoldrc.
+func runConfigProfileUse(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	merged, _ := config.Load()
+	if _, ok := merged.Profiles[name]; !ok {
+		return fmt.Errorf("unknown profile %q, see 'scaffold config profile list'", name)
+	}
+
+	cfg, err := loadConfigForUpdate()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Set("profile", name); err != nil {
+		return err
+	}
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Printf("✓ Using profile %s\n", name)
+	return nil
+}
+
+// loadCustomTemplates registers templates from the custom templates directory.
+// Broken templates are reported but don't stop the command.
+func loadCustomTemplates() {
+	dir := config.GetCustomTemplatesDir()
+	if dir == "" {
+		return
+	}
+	if err := templates.LoadCustomTemplates(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+	}
+}
+
 func getProjectNameFromCwd() string {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -324,6 +1587,19 @@ func getProjectNameFromCwd() string {
 	return filepath.Base(cwd)
 }
 
+// parseVars turns repeated --var key=value flags into a map
+func parseVars(args []string) (map[string]string, error) {
+	vars := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --var %q, expected key=value", arg)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
 func valueOrDefault(val, def string) string {
 	if val == "" {
 		return def
@@ -343,31 +1619,36 @@ func runAdd(cmd *cobra.Command, args []string) error {
 
 	// Check if component exists
 	comp, found := components.GetComponent(componentName)
+	// The listing is part of the error, so it goes to stderr and keeps
+	// stdout for --output json
 	if !found {
-		fmt.Printf("Error: Unknown component '%s'\n\n", componentName)
-		fmt.Println("Available components:")
+		var b strings.Builder
+		fmt.Fprintf(&b, "unknown component '%s'\n\nAvailable components:", componentName)
 		for _, c := range components.GetAllComponents() {
-			fmt.Printf("  %-15s  %s\n", c.Name, c.Description)
+			fmt.Fprintf(&b, "\n  %-15s  %s", c.Name, c.Description)
 		}
-		return fmt.Errorf("component not found")
+		return errors.New(b.String())
 	}
 
-	// Add the component
-	fmt.Printf("Adding component: %s\n", titleStyle.Render(comp.Name))
-	fmt.Printf("Description: %s\n\n", comp.Description)
-
-	err = components.AddComponent(cwd, componentName, force)
+	reporter, err := newReporter()
+	if err != nil {
+		return err
+	}
+	mode, ask, err := conflictOptions(stdinIsTerminal())
 	if err != nil {
 		return err
 	}
 
-	// Show created files
-	fmt.Println(categoryStyle.Render("Created files:"))
-	for _, f := range comp.Files {
-		fmt.Printf("  ✓ %s\n", f.Path)
+	// Add the component
+	reporter.Report(generator.Event{Kind: generator.ComponentStarted, Component: comp.Name, Message: comp.Description})
+	results, err := components.AddComponentWithOptions(cwd, componentName, components.Options{Conflict: mode, Ask: ask, FollowSymlinks: followSymlinks})
+	if err != nil {
+		return err
 	}
-	fmt.Println()
-	fmt.Println(dimStyle.Render("Tip: Review TODO comments in generated files for customization"))
+
+	// Show what happened to each file
+	reportComponentResults(reporter, comp.Name, results)
+	reporter.Report(generator.Event{Kind: generator.Info, Message: "Tip: Review TODO comments in generated files for customization"})
 
 	return nil
 }
@@ -8,9 +8,18 @@ package components
 
 import (
 	"embed"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"github.com/purnama/scaffold/internal/conflict"
+	"github.com/purnama/scaffold/internal/lockfile"
+	"github.com/purnama/scaffold/internal/project"
+	"github.com/purnama/scaffold/internal/render"
+	"github.com/purnama/scaffold/internal/safefs"
 )
 
 //go:embed embedded/*
@@ -29,8 +38,9 @@ type Component struct {
 
 // ComponentFile represents a single file within a component.
 type ComponentFile struct {
-	Path    string // Relative path from project root
-	Content string // File content (template)
+	Path    string      // Relative path from project root
+	Content string      // File content, a text/template rendered with render.Data
+	Mode    fs.FileMode // Permissions, see render.FileMode; set on rendered files
 }
 
 // -----------------------------------------------------------------------------
@@ -136,33 +146,199 @@ func GetAllComponents() []Component {
 	return components
 }
 
-// AddComponent adds a component's files to the specified directory.
-// If force is false, it will not overwrite existing files.
+// AddComponent adds a component's files to the specified directory. It is
+// AddComponentWithOptions with conflict.Overwrite when force is set, and
+// conflict.Fail otherwise, which writes nothing when a file already exists.
 func AddComponent(targetDir, componentName string, force bool) error {
+	opts := Options{Conflict: conflict.Fail}
+	if force {
+		opts.Conflict = conflict.Overwrite
+	}
+	_, err := AddComponentWithOptions(targetDir, componentName, opts)
+	return err
+}
+
+// Options controls how AddComponentWithOptions treats existing files.
+type Options struct {
+	Conflict conflict.Mode  // What to do with files that already exist
+	Ask      conflict.Asker // Asks per file when Conflict is conflict.Prompt
+	Data     *render.Data   // Template data, detected from targetDir when nil
+
+	FollowSymlinks bool // Write through symbolic links in targetDir that stay inside it
+}
+
+// FileResult reports what happened to a single component file.
+type FileResult struct {
+	Path   string          // Relative path from project root
+	Action conflict.Action // Created, merged, skipped, ...
+	Backup string          // Where the previous content was saved, if anything
+	Reason string          // Why a merge kept the existing file
+}
+
+// AddComponentWithOptions adds a component's files to targetDir, resolving
+// existing files with opts.Conflict. Every file is resolved before anything
+// is written, so a refusal or a cancelled prompt leaves the project as it was.
+func AddComponentWithOptions(targetDir, componentName string, opts Options) ([]FileResult, error) {
 	comp, found := GetComponent(componentName)
 	if !found {
-		return fmt.Errorf("unknown component: %s", componentName)
+		return nil, fmt.Errorf("unknown component: %s", componentName)
+	}
+
+	data := opts.Data
+	if data == nil {
+		detected, err := ProjectData(targetDir)
+		if err != nil {
+			return nil, err
+		}
+		data = &detected
+	}
+
+	rendered, err := renderFiles(comp, *data)
+	if err != nil {
+		return nil, err
+	}
+
+	root := safefs.Root{Dir: targetDir, FollowSymlinks: opts.FollowSymlinks}
+	resolutions := make([]conflict.Resolution, len(rendered))
+	for i, file := range rendered {
+		if _, err := root.Path(file.Path); err != nil {
+			return nil, err
+		}
+		res, err := conflict.ResolveFile(opts.Conflict, targetDir, file.Path, []byte(file.Content), opts.Ask)
+		if errors.Is(err, conflict.ErrExists) {
+			return nil, fmt.Errorf("%w (use --conflict=%s to choose what to do)", err, conflict.ModeNames())
+		}
+		if err != nil {
+			return nil, err
+		}
+		resolutions[i] = res
 	}
 
-	for _, file := range comp.Files {
-		targetPath := filepath.Join(targetDir, file.Path)
+	results := make([]FileResult, 0, len(comp.Files))
+	for i, file := range comp.Files {
+		res := resolutions[i]
 
-		// Check if file exists
-		if _, err := os.Stat(targetPath); err == nil && !force {
-			return fmt.Errorf("file already exists: %s (use --force to overwrite)", file.Path)
+		if res.Write() {
+			targetPath, err := root.Path(file.Path)
+			if err != nil {
+				return results, err
+			}
+
+			// Create parent directories
+			dir := filepath.Dir(targetPath)
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return results, fmt.Errorf("failed to create directory %s: %w", dir, err)
+			}
+
+			// Keep the previous version next to the file
+			if res.Backup != "" {
+				backupPath, err := root.Path(res.Backup)
+				if err != nil {
+					return results, err
+				}
+				if err := os.Rename(targetPath, backupPath); err != nil {
+					return results, fmt.Errorf("failed to back up %s: %w", file.Path, err)
+				}
+			}
+
+			// Write file, an existing one keeps its mode
+			if err := os.WriteFile(targetPath, res.Content, rendered[i].Mode); err != nil {
+				return results, fmt.Errorf("failed to write file %s: %w", file.Path, err)
+			}
 		}
 
-		// Create parent directories
-		dir := filepath.Dir(targetPath)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		results = append(results, FileResult{Path: file.Path, Action: res.Action, Backup: res.Backup, Reason: res.Reason})
+	}
+
+	if err := recordComponent(targetDir, comp, resolutions); err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+// RenderComponent renders the files of a component with data, without
+// writing anything.
+func RenderComponent(name string, data render.Data) ([]ComponentFile, error) {
+	comp, found := GetComponent(name)
+	if !found {
+		return nil, fmt.Errorf("unknown component: %s", name)
+	}
+	return renderFiles(comp, data)
+}
+
+// renderFiles renders every file of comp, failing on the first error.
+func renderFiles(comp Component, data render.Data) ([]ComponentFile, error) {
+	files := make([]ComponentFile, len(comp.Files))
+	for i, file := range comp.Files {
+		content, err := render.Execute(file.Path, file.Content, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", file.Path, err)
 		}
+		files[i] = ComponentFile{Path: file.Path, Content: content, Mode: render.FileMode(file.Mode, content)}
+	}
+	return files, nil
+}
+
+// recordComponent adds the component to the project's lockfile, if it has
+// one. Hashes are updated for files that now hold the generated content;
+// skipped and merged files keep whatever was recorded before.
+func recordComponent(targetDir string, comp Component, resolutions []conflict.Resolution) error {
+	lock, err := lockfile.Read(targetDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
 
-		// Write file
-		if err := os.WriteFile(targetPath, []byte(file.Content), 0644); err != nil {
-			return fmt.Errorf("failed to write file %s: %w", file.Path, err)
+	files := make([]string, len(comp.Files))
+	for i, file := range comp.Files {
+		files[i] = file.Path
+		switch resolutions[i].Action {
+		case conflict.Created, conflict.Overwritten, conflict.BackedUp:
+			lock.SetFile(file.Path, resolutions[i].Content)
 		}
 	}
+	lock.AddComponent(comp.Name, files)
 
+	if err := lockfile.Write(targetDir, lock); err != nil {
+		return fmt.Errorf("failed to update %s: %w", lockfile.FileName, err)
+	}
 	return nil
 }
+
+// -----------------------------------------------------------------------------
+// Project Detection
+// -----------------------------------------------------------------------------
+
+// ProjectData builds the template data for the project in dir: the module
+// path and Go version from go.mod and the binaries from cmd/* or the root
+// main package. Without a go.mod the module path is left empty, components
+// skip the lines that need it.
+func ProjectData(dir string) (render.Data, error) {
+	info, err := project.Inspect(dir)
+	if err != nil {
+		return render.Data{}, fmt.Errorf("failed to inspect %s: %w", dir, err)
+	}
+
+	name := info.Name()
+	data := render.Data{
+		ProjectName: name,
+		PackageName: render.PackageName(name),
+		ModuleName:  info.Module,
+		GoVersion:   info.GoVersion,
+		Kinds:       info.Kinds,
+	}
+	if data.GoVersion == "" {
+		data.GoVersion = render.DefaultGoVersion
+	}
+	for _, m := range info.MainPackages {
+		b := render.Binary{Name: m.Name, Path: m.Path}
+		if data.ModuleName != "" {
+			b.Import = data.ModuleName + strings.TrimPrefix(m.Path, ".")
+		}
+		data.Binaries = append(data.Binaries, b)
+	}
+	return data, nil
+}
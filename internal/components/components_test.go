@@ -7,10 +7,16 @@
 package components
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/purnama/scaffold/internal/conflict"
+	"github.com/purnama/scaffold/internal/lockfile"
+	"github.com/purnama/scaffold/internal/render"
+	"github.com/purnama/scaffold/internal/safefs"
 )
 
 // -----------------------------------------------------------------------------
@@ -238,6 +244,339 @@ func TestAddComponentCreatesDirectories(t *testing.T) {
 	}
 }
 
+// -----------------------------------------------------------------------------
+// Test: AddComponentWithOptions conflict modes
+// -----------------------------------------------------------------------------
+func TestAddComponentConflictModes(t *testing.T) {
+	existing := "# mine\nbin/\n"
+
+	tests := []struct {
+		mode       conflict.Mode
+		wantAction conflict.Action
+		check      func(t *testing.T, dir, content string)
+	}{
+		{conflict.Skip, conflict.Skipped, func(t *testing.T, dir, content string) {
+			if content != existing {
+				t.Errorf("skip changed the file: %q", content)
+			}
+		}},
+		{conflict.Overwrite, conflict.Overwritten, func(t *testing.T, dir, content string) {
+			if strings.Contains(content, "# mine") {
+				t.Error("overwrite kept the old content")
+			}
+		}},
+		{conflict.Backup, conflict.BackedUp, func(t *testing.T, dir, content string) {
+			orig, err := os.ReadFile(filepath.Join(dir, ".gitignore.orig"))
+			if err != nil || string(orig) != existing {
+				t.Errorf("expected .gitignore.orig with the old content, got %q, %v", orig, err)
+			}
+		}},
+		{conflict.Merge, conflict.Merged, func(t *testing.T, dir, content string) {
+			if !strings.HasPrefix(content, existing) || !strings.Contains(content, "*.test") {
+				t.Errorf("merge should keep existing lines and add missing ones, got:\n%s", content)
+			}
+			if strings.Count("\n"+content, "\nbin/\n") != 1 {
+				t.Errorf("merge duplicated a line:\n%s", content)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			dir := t.TempDir()
+			os.WriteFile(filepath.Join(dir, ".gitignore"), []byte(existing), 0644)
+
+			results, err := AddComponentWithOptions(dir, "gitignore", Options{Conflict: tt.mode})
+			if err != nil {
+				t.Fatalf("AddComponentWithOptions() error = %v", err)
+			}
+			if len(results) != 1 || results[0].Action != tt.wantAction {
+				t.Fatalf("results = %+v, want action %q", results, tt.wantAction)
+			}
+
+			content, _ := os.ReadFile(filepath.Join(dir, ".gitignore"))
+			tt.check(t, dir, string(content))
+		})
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test: AddComponentWithOptions writes nothing when one file is refused
+// -----------------------------------------------------------------------------
+func TestAddComponentFailWritesNothing(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "internal", "middleware"), 0755)
+	os.WriteFile(filepath.Join(dir, "internal", "middleware", "ratelimit.go"), []byte("package middleware"), 0644)
+
+	_, err := AddComponentWithOptions(dir, "middleware", Options{})
+	if !errors.Is(err, conflict.ErrExists) {
+		t.Fatalf("expected ErrExists, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "internal", "middleware", "logging.go")); !os.IsNotExist(err) {
+		t.Error("files were written before the conflict was detected")
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test: AddComponentWithOptions doesn't write through symbolic links
+// -----------------------------------------------------------------------------
+func TestAddComponentSymlinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		follow bool
+		err    error
+	}{
+		{"not followed", false, safefs.ErrSymlink},
+		{"followed", true, safefs.ErrOutside},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			outside := t.TempDir()
+			if err := os.Symlink(outside, filepath.Join(dir, "internal")); err != nil {
+				t.Skipf("symlinks not supported: %v", err)
+			}
+
+			_, err := AddComponentWithOptions(dir, "middleware", Options{Conflict: conflict.Overwrite, FollowSymlinks: tt.follow})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+			if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+				t.Errorf("files written outside the project: %v", entries)
+			}
+		})
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test: rendered component files get their mode
+// -----------------------------------------------------------------------------
+func TestRenderFilesMode(t *testing.T) {
+	comp := Component{Name: "scripts", Files: []ComponentFile{
+		{Path: "Makefile", Content: "build:\n\tgo build\n"},
+		{Path: "scripts/release.sh", Content: "#!/bin/sh\necho {{.ProjectName}}\n"},
+		{Path: ".env", Content: "TOKEN=\n", Mode: 0600},
+	}}
+
+	files, err := renderFiles(comp, render.Data{ProjectName: "app"})
+	if err != nil {
+		t.Fatalf("renderFiles() error = %v", err)
+	}
+	for i, want := range []os.FileMode{0644, 0755, 0600} {
+		if files[i].Mode != want {
+			t.Errorf("%s: Mode = %04o, want %04o", files[i].Path, files[i].Mode, want)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test: AddComponentWithOptions asks per file in prompt mode
+// -----------------------------------------------------------------------------
+func TestAddComponentPrompt(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "Makefile"), []byte("build:\n\tgo build .\n"), 0644)
+
+	var asked []string
+	ask := func(path string, existing, proposed []byte) (conflict.Mode, error) {
+		asked = append(asked, path)
+		return conflict.Merge, nil
+	}
+
+	results, err := AddComponentWithOptions(dir, "makefile", Options{Conflict: conflict.Prompt, Ask: ask})
+	if err != nil {
+		t.Fatalf("AddComponentWithOptions() error = %v", err)
+	}
+	if len(asked) != 1 || asked[0] != "Makefile" {
+		t.Errorf("asked about %v", asked)
+	}
+	if results[0].Action != conflict.Merged {
+		t.Errorf("Action = %q, want merged", results[0].Action)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(dir, "Makefile"))
+	if strings.Count("\n"+string(content), "\nbuild:") != 1 {
+		t.Errorf("merge should not add a second build target:\n%s", content)
+	}
+	if !strings.Contains(string(content), "test:") {
+		t.Errorf("merge should add the missing test target:\n%s", content)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test: Component files are rendered with data detected from the project
+// -----------------------------------------------------------------------------
+func TestAddComponentUsesProjectData(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/acme/billing\n\ngo 1.23\n"), 0644)
+	for _, name := range []string{"billing-api", "billing-worker"} {
+		os.MkdirAll(filepath.Join(dir, "cmd", name), 0755)
+		os.WriteFile(filepath.Join(dir, "cmd", name, "main.go"), []byte("package main\n"), 0644)
+	}
+
+	for _, name := range []string{"makefile", "dockerfile", "readme", "github-actions"} {
+		if err := AddComponent(dir, name, false); err != nil {
+			t.Fatalf("AddComponent(%s) error = %v", name, err)
+		}
+	}
+
+	checks := map[string][]string{
+		"Makefile": {
+			"BINARY_NAME := billing-api\n",
+			"MAIN_PATH := ./cmd/billing-api\n",
+			"build-billing-worker:\n\t$(GO) build $(GOFLAGS) $(LDFLAGS) -o bin/billing-worker ./cmd/billing-worker\n",
+		},
+		"Dockerfile": {
+			"FROM golang:1.23-alpine AS builder",
+			"-o /app/billing-api ./cmd/billing-api",
+			`ENTRYPOINT ["./billing-api"]`,
+		},
+		"README.md": {
+			"# billing\n",
+			"go install example.com/acme/billing/cmd/billing-worker@latest",
+		},
+		".github/workflows/ci.yml": {
+			"GO_VERSION: '1.23'",
+			"go-version: ${{ env.GO_VERSION }}",
+		},
+	}
+	for file, wants := range checks {
+		content, _ := os.ReadFile(filepath.Join(dir, file))
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s should contain %q, got:\n%s", file, want, content)
+			}
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test: The Dockerfile only exposes ports for servers
+// -----------------------------------------------------------------------------
+func TestDockerfileFollowsProjectKind(t *testing.T) {
+	tests := []struct {
+		name   string
+		main   string
+		want   []string
+		absent []string
+	}{
+		{"http", "package main\n\nimport \"net/http\"\n", []string{"EXPOSE 8080", "HEALTHCHECK"}, nil},
+		{"grpc", "package main\n\nimport \"google.golang.org/grpc\"\n", []string{"EXPOSE 50051"}, []string{"HEALTHCHECK"}},
+		{"cli", "package main\n\nimport \"github.com/spf13/cobra\"\n", []string{"COPY --from=builder /app/app .\n\nENTRYPOINT"}, []string{"EXPOSE 8080", "HEALTHCHECK"}},
+		{"unknown", "package main\n", []string{"EXPOSE 8080", "HEALTHCHECK"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.23\n"), 0644)
+			os.WriteFile(filepath.Join(dir, "main.go"), []byte(tt.main), 0644)
+
+			if err := AddComponent(dir, "dockerfile", false); err != nil {
+				t.Fatalf("AddComponent() error = %v", err)
+			}
+			content, _ := os.ReadFile(filepath.Join(dir, "Dockerfile"))
+			for _, want := range tt.want {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("Dockerfile should contain %q, got:\n%s", want, content)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(string(content), absent) {
+					t.Errorf("Dockerfile should not contain %q, got:\n%s", absent, content)
+				}
+			}
+		})
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test: Components are recorded in the project's lockfile
+// -----------------------------------------------------------------------------
+func TestAddComponentUpdatesLockfile(t *testing.T) {
+	dir := t.TempDir()
+
+	// Projects without a lockfile don't get one
+	if err := AddComponent(dir, "gitignore", false); err != nil {
+		t.Fatalf("AddComponent() error = %v", err)
+	}
+	if _, err := os.Stat(lockfile.Path(dir)); !os.IsNotExist(err) {
+		t.Fatalf("lockfile should not be created, stat error = %v", err)
+	}
+
+	lock := lockfile.New()
+	lock.Template = "go-api"
+	lock.SetFile("Makefile", []byte("generated\n"))
+	if err := lockfile.Write(dir, lock); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "Makefile"), []byte("custom:\n\ttrue\n"), 0644)
+
+	if _, err := AddComponentWithOptions(dir, "makefile", Options{Conflict: conflict.Merge}); err != nil {
+		t.Fatalf("AddComponentWithOptions(makefile) error = %v", err)
+	}
+	if err := AddComponent(dir, "dockerfile", false); err != nil {
+		t.Fatalf("AddComponent(dockerfile) error = %v", err)
+	}
+
+	got, err := lockfile.Read(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.HasComponent("makefile") || !got.HasComponent("dockerfile") || got.HasComponent("gitignore") {
+		t.Errorf("components = %+v", got.Components)
+	}
+
+	// Merged files keep their hash, written ones get the generated content's
+	if got.Files["Makefile"] != lockfile.Hash([]byte("generated\n")) {
+		t.Errorf("Makefile hash changed to %q", got.Files["Makefile"])
+	}
+	dockerfile, _ := os.ReadFile(filepath.Join(dir, "Dockerfile"))
+	if got.Files["Dockerfile"] != lockfile.Hash(dockerfile) {
+		t.Errorf("Dockerfile hash = %q, want %q", got.Files["Dockerfile"], lockfile.Hash(dockerfile))
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test: Every component renders in a directory without go.mod
+// -----------------------------------------------------------------------------
+func TestAllComponentsRenderWithoutGoMod(t *testing.T) {
+	for _, comp := range GetAllComponents() {
+		t.Run(comp.Name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "plain")
+			os.Mkdir(dir, 0755)
+			if err := AddComponent(dir, comp.Name, false); err != nil {
+				t.Fatalf("AddComponent() error = %v", err)
+			}
+		})
+	}
+
+	data, err := ProjectData(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.GoVersion != render.DefaultGoVersion || data.MainPath() != "." || data.ModuleName != "" {
+		t.Errorf("unexpected defaults: %+v", data)
+	}
+
+	// Without a module there is nothing to link to or install
+	dir := filepath.Join(t.TempDir(), "myapp")
+	os.MkdirAll(filepath.Join(dir, "cmd", "x"), 0755)
+	os.WriteFile(filepath.Join(dir, "cmd", "x", "main.go"), []byte("package main\n"), 0644)
+	if err := AddComponent(dir, "readme", false); err != nil {
+		t.Fatalf("AddComponent() error = %v", err)
+	}
+	readme, _ := os.ReadFile(filepath.Join(dir, "README.md"))
+	for _, absent := range []string{"https://myapp", "go install", "go get", "git clone"} {
+		if strings.Contains(string(readme), absent) {
+			t.Errorf("README.md without a module shouldn't contain %q:\n%s", absent, readme)
+		}
+	}
+	if !strings.Contains(string(readme), "# myapp\n\n[![License") {
+		t.Errorf("README.md should start with the title and the license badge:\n%s", readme)
+	}
+}
+
 // -----------------------------------------------------------------------------
 // Benchmark: GetComponent performance
 // -----------------------------------------------------------------------------
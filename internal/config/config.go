@@ -2,10 +2,24 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+
+	"github.com/purnama/scaffold/internal/hooks"
 )
 
+// Licenses lists the licenses scaffold can generate
+var Licenses = []string{"MIT", "Apache 2.0", "GPL 3.0", "None"}
+
+// IsValidLicense reports whether name is one of Licenses
+func IsValidLicense(name string) bool {
+	return slices.Contains(Licenses, name)
+}
+
 // Config holds user configuration
 type Config struct {
 	Author         string `json:"author"`
@@ -13,6 +27,17 @@ type Config struct {
 	ModulePrefix   string `json:"module_prefix"`
 	AutoGit        bool   `json:"auto_git"`
 	AutoInstall    bool   `json:"auto_install"`
+
+	Profile  string             `json:"profile,omitempty"` // Profile used when --profile isn't given
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+	Hooks    Hooks              `json:"hooks,omitzero"` // Run in every new project, after the template's
+}
+
+// Hooks are commands run in new projects. Pre hooks run in the staged
+// project before it is moved into place, post hooks in the final one.
+type Hooks struct {
+	Pre  []hooks.Hook `json:"pre,omitempty"`
+	Post []hooks.Hook `json:"post,omitempty"`
 }
 
 // DefaultConfig returns the default configuration
@@ -26,24 +51,116 @@ func DefaultConfig() *Config {
 	}
 }
 
-// Load loads configuration from ~/.scaffold/config.json
-func Load() *Config {
+// Load returns the configuration merged from every layer, lowest precedence
+// first: built-in defaults, the user config file, the nearest .scaffoldrc,
+// the selected profile and SCAFFOLD_* environment variables. Command line
+// flags are applied on top by the caller. Missing files are not an error.
+// Problems in any layer are reported as *FileError values while every valid
+// entry is still applied.
+func Load() (*Config, error) {
+	cfg, _, err := LoadProfile("")
+	return cfg, err
+}
+
+// LoadWithOrigins is Load that also reports which layer set each key
+func LoadWithOrigins() (*Config, Origins, error) {
+	return LoadProfile("")
+}
+
+// LoadProfile is LoadWithOrigins with the profile chosen on the command
+// line. An empty name selects SCAFFOLD_PROFILE, then the profile key.
+func LoadProfile(name string) (*Config, Origins, error) {
 	cfg := DefaultConfig()
+	origins := Origins{}
+	for _, key := range Keys() {
+		origins[key] = SourceDefault
+	}
+
+	var errs []error
+	if path, err := Path(); err != nil {
+		errs = append(errs, err)
+	} else if err := applyFile(cfg, origins, path); err != nil {
+		errs = append(errs, err)
+	}
+
+	if cwd, err := os.Getwd(); err == nil {
+		if rc := FindProjectFile(cwd); rc != "" {
+			if err := applyFile(cfg, origins, rc); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	// The profile sits between the files and the environment so that
+	// SCAFFOLD_AUTHOR still beats the profile's author
+	origin := "--profile"
+	if name == "" {
+		origin = EnvVar("profile")
+		name = os.Getenv(origin)
+	}
+	if name == "" {
+		name, origin = cfg.Profile, origins["profile"]
+	}
+	if name != "" {
+		if err := applyProfile(cfg, origins, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := applyEnv(cfg, origins); err != nil {
+		errs = append(errs, err)
+	}
+	if name != "" {
+		cfg.Profile = name
+		origins["profile"] = origin
+	}
+
+	return cfg, origins, errors.Join(errs...)
+}
 
-	configPath := getConfigPath()
-	data, err := os.ReadFile(configPath)
+// LoadFile loads a single config file on top of the defaults, ignoring all
+// other layers. It is what "scaffold config set" edits.
+func LoadFile(path string) (*Config, error) {
+	cfg := DefaultConfig()
+	if err := applyFile(cfg, Origins{}, path); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// applyFile merges the entries of the config file at path into cfg
+func applyFile(cfg *Config, origins Origins, path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
 	if err != nil {
-		return cfg
+		return &FileError{Path: path, Err: err}
 	}
 
-	json.Unmarshal(data, cfg)
-	return cfg
+	fileErr := decode(data, cfg, func(key string) { origins[key] = path })
+	// The profiles this file defined replaced earlier ones, they have no
+	// source yet
+	for name, p := range cfg.Profiles {
+		if p.Source == "" {
+			p.Source = path
+			cfg.Profiles[name] = p
+		}
+	}
+	if fileErr != nil {
+		fileErr.Path = path
+		return fileErr
+	}
+	return nil
 }
 
-// Save saves configuration to ~/.scaffold/config.json
+// Save saves configuration to the user config file returned by Path
 func Save(cfg *Config) error {
-	configDir := getConfigDir()
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return err
 	}
 
@@ -52,19 +169,54 @@ func Save(cfg *Config) error {
 		return err
 	}
 
-	return os.WriteFile(getConfigPath(), data, 0644)
+	return os.WriteFile(path, data, 0644)
+}
+
+// Path returns the location of the user config file
+func Path() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
 }
 
-// GetCustomTemplatesDir returns the custom templates directory path
+// GetCustomTemplatesDir returns the custom templates directory path, or ""
+// when the home directory can't be determined
 func GetCustomTemplatesDir() string {
-	return filepath.Join(getConfigDir(), "templates")
+	dir, err := getConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "templates")
 }
 
-func getConfigDir() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".scaffold")
+// getConfigDir picks $XDG_CONFIG_HOME/scaffold (~/.config/scaffold when
+// unset) if it has a config file, then the legacy ~/.scaffold if it exists,
+// so older setups and their custom templates keep working. New setups get
+// the XDG directory.
+func getConfigDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate config directory: %w", err)
+	}
+
+	xdgDir := filepath.Join(home, ".config", "scaffold")
+	if xdgHome := os.Getenv("XDG_CONFIG_HOME"); xdgHome != "" {
+		xdgDir = filepath.Join(xdgHome, "scaffold")
+	}
+	legacyDir := filepath.Join(home, ".scaffold")
+
+	if fileExists(filepath.Join(xdgDir, "config.json")) {
+		return xdgDir, nil
+	}
+	if fileExists(legacyDir) {
+		return legacyDir, nil
+	}
+	return xdgDir, nil
 }
 
-func getConfigPath() string {
-	return filepath.Join(getConfigDir(), "config.json")
-}
\ No newline at end of file
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -37,7 +39,10 @@ func TestLoadDefaultWhenFileNotExists(t *testing.T) {
 	defer os.Setenv("HOME", originalHome)
 	os.Setenv("HOME", tmpDir)
 
-	cfg := Load()
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
 
 	if cfg == nil {
 		t.Fatal("Load returned nil")
@@ -68,14 +73,17 @@ func TestSaveAndLoad(t *testing.T) {
 		t.Fatalf("Save failed: %v", err)
 	}
 
-	// Verify config file was created
-	configPath := filepath.Join(tmpDir, ".scaffold", "config.json")
+	// Verify config file was created, new setups use the XDG location
+	configPath := filepath.Join(tmpDir, ".config", "scaffold", "config.json")
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		t.Errorf("config file not created at %s", configPath)
 	}
 
 	// Load config
-	loadedCfg := Load()
+	loadedCfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
 
 	if loadedCfg.Author != "John Doe" {
 		t.Errorf("Author = %q, want John Doe", loadedCfg.Author)
@@ -106,7 +114,7 @@ func TestGetCustomTemplatesDir(t *testing.T) {
 
 	dir := GetCustomTemplatesDir()
 
-	expectedDir := filepath.Join(tmpDir, ".scaffold", "templates")
+	expectedDir := filepath.Join(tmpDir, ".config", "scaffold", "templates")
 	if dir != expectedDir {
 		t.Errorf("GetCustomTemplatesDir = %s, want %s", dir, expectedDir)
 	}
@@ -130,10 +138,10 @@ func TestConfigFileStructure(t *testing.T) {
 		t.Fatalf("Save failed: %v", err)
 	}
 
-	// Verify .scaffold directory was created
-	scaffoldDir := filepath.Join(tmpDir, ".scaffold")
+	// Verify the config directory was created
+	scaffoldDir := filepath.Join(tmpDir, ".config", "scaffold")
 	if _, err := os.Stat(scaffoldDir); os.IsNotExist(err) {
-		t.Errorf(".scaffold directory not created")
+		t.Errorf(".config/scaffold directory not created")
 	}
 
 	// Verify config.json was created
@@ -160,7 +168,10 @@ func TestPartialConfigLoad(t *testing.T) {
 	}
 
 	// Load config - should use defaults for missing fields
-	loadedCfg := Load()
+	loadedCfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
 
 	if loadedCfg.Author != "Partial Author" {
 		t.Errorf("Author = %q, want Partial Author", loadedCfg.Author)
@@ -170,3 +181,62 @@ func TestPartialConfigLoad(t *testing.T) {
 		t.Errorf("DefaultLicense = %q, want GPL 3.0", loadedCfg.DefaultLicense)
 	}
 }
+
+func TestLoadReportsProblems(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	configDir := filepath.Join(tmpDir, ".scaffold")
+	os.MkdirAll(configDir, 0755)
+	os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{
+  "author": "Jane Doe",
+  "auto_git": "yes",
+  "module_prefx": "github.com/jane",
+  "default_license": "BSD",
+  "auto_install": true
+}`), 0644)
+
+	cfg, err := Load()
+	var fileErr *FileError
+	if !errors.As(err, &fileErr) {
+		t.Fatalf("expected *FileError, got %v", err)
+	}
+
+	wantKeys := []string{"auto_git", "default_license", "module_prefx"}
+	if len(fileErr.Fields) != len(wantKeys) {
+		t.Fatalf("expected %d field errors, got %v", len(wantKeys), fileErr.Fields)
+	}
+	for i, key := range wantKeys {
+		if fileErr.Fields[i].Key != key {
+			t.Errorf("field error %d is for %q, want %q", i, fileErr.Fields[i].Key, key)
+		}
+	}
+	if !strings.Contains(err.Error(), "config.json") {
+		t.Errorf("error should name the file: %v", err)
+	}
+
+	// Valid entries are still applied, bad ones keep their defaults
+	if cfg.Author != "Jane Doe" || !cfg.AutoInstall {
+		t.Errorf("valid entries not applied: %+v", cfg)
+	}
+	if cfg.DefaultLicense != "MIT" || !cfg.AutoGit || cfg.ModulePrefix != "github.com/user" {
+		t.Errorf("invalid entries should keep defaults: %+v", cfg)
+	}
+}
+
+func TestLoadReportsSyntaxErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	configDir := filepath.Join(tmpDir, ".scaffold")
+	os.MkdirAll(configDir, 0755)
+	os.WriteFile(filepath.Join(configDir, "config.json"), []byte("{\n  \"author\": \"x\",\n}"), 0644)
+
+	cfg, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected syntax error with line number, got %v", err)
+	}
+	if cfg == nil || cfg.DefaultLicense != "MIT" {
+		t.Errorf("expected defaults alongside the error, got %+v", cfg)
+	}
+}
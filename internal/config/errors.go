@@ -0,0 +1,203 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/purnama/scaffold/internal/hooks"
+)
+
+// FieldError describes a problem with a single key in the config file
+type FieldError struct {
+	Key     string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Key + ": " + e.Message
+}
+
+// fieldErrors lets a key such as profiles report problems with the keys
+// nested in it, e.g. profiles.work.licence
+type fieldErrors []FieldError
+
+func (e fieldErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, f := range e {
+		msgs[i] = f.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// FileError reports everything wrong with a config file. Err is set when the
+// file couldn't be read or parsed at all; otherwise Fields lists the bad keys.
+type FileError struct {
+	Path   string
+	Err    error
+	Fields []FieldError
+}
+
+func (e *FileError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Path, e.Err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s has %d problem(s):", e.Path, len(e.Fields))
+	for _, f := range e.Fields {
+		b.WriteString("\n  " + f.Error())
+	}
+	return b.String()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// decode applies every valid entry in data to cfg and reports the rest.
+// setKey is called for each key that was applied.
+func decode(data []byte, cfg *Config, setKey func(key string)) *FileError {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return &FileError{Err: syntaxError(data, err)}
+	}
+
+	var problems []FieldError
+	for _, key := range sortedKeys(raw) {
+		if err := decodeField(cfg, key, raw[key]); err != nil {
+			var nested fieldErrors
+			if errors.As(err, &nested) {
+				problems = append(problems, nested...)
+			} else {
+				problems = append(problems, FieldError{Key: key, Message: err.Error()})
+			}
+			continue
+		}
+		setKey(key)
+	}
+	if len(problems) > 0 {
+		return &FileError{Fields: problems}
+	}
+	return nil
+}
+
+func decodeField(cfg *Config, key string, value json.RawMessage) error {
+	switch key {
+	case "profiles":
+		return decodeProfiles(cfg, value)
+	case "hooks":
+		return decodeHooks(cfg, value)
+	}
+
+	f, err := lookup(key)
+	if err != nil {
+		return fmt.Errorf("unknown key, expected one of: %s, hooks, profiles", strings.Join(Keys(), ", "))
+	}
+
+	if f.isBool {
+		var b bool
+		if err := json.Unmarshal(value, &b); err != nil {
+			return fmt.Errorf("expected true or false, got %s", jsonKind(value))
+		}
+		return f.set(cfg, fmt.Sprint(b))
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return fmt.Errorf("expected a string, got %s", jsonKind(value))
+	}
+	return f.set(cfg, s)
+}
+
+// jsonKind names the JSON type of a raw value for error messages
+func jsonKind(value json.RawMessage) string {
+	v := bytes.TrimSpace(value)
+	if len(v) == 0 {
+		return "nothing"
+	}
+	switch v[0] {
+	case '"':
+		return "a string"
+	case '{':
+		return "an object"
+	case '[':
+		return "an array"
+	case 't', 'f':
+		return "a boolean"
+	case 'n':
+		return "null"
+	default:
+		return "a number"
+	}
+}
+
+// syntaxError adds the line and column to JSON syntax errors
+func syntaxError(data []byte, err error) error {
+	var se *json.SyntaxError
+	if !errors.As(err, &se) {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	line := 1 + bytes.Count(data[:se.Offset], []byte("\n"))
+	col := int(se.Offset) - bytes.LastIndexByte(data[:se.Offset], '\n') - 1
+	return fmt.Errorf("invalid JSON at line %d, column %d: %w", line, col, err)
+}
+
+// decodeHooks replaces the hooks of lower layers with the ones in value
+func decodeHooks(cfg *Config, value json.RawMessage) error {
+	var raw struct {
+		Pre  json.RawMessage `json:"pre"`
+		Post json.RawMessage `json:"post"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(value))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&raw); err != nil {
+		return fmt.Errorf("expected an object with pre and post lists of hooks: %w", err)
+	}
+
+	var h Hooks
+	var err error
+	if h.Pre, err = decodeHookList(raw.Pre); err != nil {
+		return fmt.Errorf("pre: %w", err)
+	}
+	if h.Post, err = decodeHookList(raw.Post); err != nil {
+		return fmt.Errorf("post: %w", err)
+	}
+	cfg.Hooks = h
+	return nil
+}
+
+// decodeHookList decodes and validates a list of hooks, shared by the hooks
+// key and profiles. An entry is a hook object or a string, short for a hook
+// that runs it with sh -c.
+func decodeHookList(value json.RawMessage) ([]hooks.Hook, error) {
+	if len(value) == 0 || string(value) == "null" {
+		return nil, nil
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(value, &entries); err != nil {
+		return nil, fmt.Errorf("expected a list of hooks, got %s", jsonKind(value))
+	}
+
+	list := make([]hooks.Hook, 0, len(entries))
+	for i, entry := range entries {
+		var run string
+		if json.Unmarshal(entry, &run) == nil {
+			list = append(list, hooks.Hook{Run: run})
+			continue
+		}
+		var h hooks.Hook
+		dec := json.NewDecoder(bytes.NewReader(entry))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&h); err != nil {
+			return nil, fmt.Errorf("hook %d: expected a command or a hook object: %w", i+1, err)
+		}
+		list = append(list, h)
+	}
+	if err := hooks.Validate(list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/purnama/scaffold/internal/project"
+)
+
+// field maps a config key to its accessors
+type field struct {
+	key    string
+	isBool bool // Stored as a JSON boolean rather than a string
+	get    func(c *Config) string
+	set    func(c *Config, value string) error
+}
+
+var fields = []field{
+	{
+		key: "author",
+		get: func(c *Config) string { return c.Author },
+		set: func(c *Config, v string) error { c.Author = v; return nil },
+	},
+	{
+		key: "default_license",
+		get: func(c *Config) string { return c.DefaultLicense },
+		set: func(c *Config, v string) error {
+			if !IsValidLicense(v) {
+				return fmt.Errorf("unknown license %q, expected one of: %s", v, strings.Join(Licenses, ", "))
+			}
+			c.DefaultLicense = v
+			return nil
+		},
+	},
+	{
+		key: "module_prefix",
+		get: func(c *Config) string { return c.ModulePrefix },
+		set: func(c *Config, v string) error {
+			if err := ValidateModulePrefix(v); err != nil {
+				return err
+			}
+			c.ModulePrefix = v
+			return nil
+		},
+	},
+	{
+		key:    "auto_git",
+		isBool: true,
+		get:    func(c *Config) string { return strconv.FormatBool(c.AutoGit) },
+		set:    func(c *Config, v string) error { return parseBool(v, &c.AutoGit) },
+	},
+	{
+		key:    "auto_install",
+		isBool: true,
+		get:    func(c *Config) string { return strconv.FormatBool(c.AutoInstall) },
+		set:    func(c *Config, v string) error { return parseBool(v, &c.AutoInstall) },
+	},
+	{
+		key: "profile",
+		get: func(c *Config) string { return c.Profile },
+		set: func(c *Config, v string) error {
+			if v != "" {
+				if err := ValidateProfileName(v); err != nil {
+					return err
+				}
+			}
+			c.Profile = v
+			return nil
+		},
+	},
+}
+
+// Keys returns the configuration keys in display order
+func Keys() []string {
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.key
+	}
+	return keys
+}
+
+func lookup(key string) (field, error) {
+	for _, f := range fields {
+		if f.key == key {
+			return f, nil
+		}
+	}
+	return field{}, fmt.Errorf("unknown config key %q, expected one of: %s", key, strings.Join(Keys(), ", "))
+}
+
+// Get returns the value of key formatted as a string
+func (c *Config) Get(key string) (string, error) {
+	f, err := lookup(key)
+	if err != nil {
+		return "", err
+	}
+	return f.get(c), nil
+}
+
+// Set validates value and assigns it to key
+func (c *Config) Set(key, value string) error {
+	f, err := lookup(key)
+	if err != nil {
+		return err
+	}
+	return f.set(c, value)
+}
+
+// Unset resets key to its default value
+func (c *Config) Unset(key string) error {
+	f, err := lookup(key)
+	if err != nil {
+		return err
+	}
+	return f.set(c, f.get(DefaultConfig()))
+}
+
+// Validate checks every value the way Set would
+func (c *Config) Validate() error {
+	check := *c
+	for _, f := range fields {
+		if err := f.set(&check, f.get(c)); err != nil {
+			return fmt.Errorf("%s: %w", f.key, err)
+		}
+	}
+	return nil
+}
+
+func parseBool(value string, dst *bool) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q, expected true or false", value)
+	}
+	*dst = b
+	return nil
+}
+
+// ValidateModulePrefix checks that prefix is usable as the start of a Go
+// module path, such as "github.com/jane" or "gitlab.example.com/team".
+// An empty prefix is allowed and makes the project name the module path.
+func ValidateModulePrefix(prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	if err := project.CheckModulePath(prefix); err != nil {
+		return fmt.Errorf("invalid module prefix %q: %w", prefix, err)
+	}
+	return nil
+}
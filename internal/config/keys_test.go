@@ -0,0 +1,109 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetAndGet(t *testing.T) {
+	cfg := DefaultConfig()
+
+	tests := []struct {
+		key   string
+		value string
+	}{
+		{"author", "Jane Doe"},
+		{"default_license", "Apache 2.0"},
+		{"module_prefix", "gitlab.example.com/team"},
+		{"auto_git", "false"},
+		{"auto_install", "true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if err := cfg.Set(tt.key, tt.value); err != nil {
+				t.Fatalf("Set(%q, %q) failed: %v", tt.key, tt.value, err)
+			}
+			got, err := cfg.Get(tt.key)
+			if err != nil {
+				t.Fatalf("Get(%q) failed: %v", tt.key, err)
+			}
+			if got != tt.value {
+				t.Errorf("Get(%q) = %q, want %q", tt.key, got, tt.value)
+			}
+		})
+	}
+
+	if cfg.Author != "Jane Doe" || cfg.AutoGit || !cfg.AutoInstall {
+		t.Errorf("fields not updated: %+v", cfg)
+	}
+}
+
+func TestSetRejectsInvalidValues(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+	}{
+		{"default_license", "BSD"},
+		{"module_prefix", "/github.com/jane"},
+		{"module_prefix", "github.com//jane"},
+		{"module_prefix", "github.com/jane doe"},
+		{"module_prefix", "github.com/../etc"},
+		{"auto_git", "yes please"},
+		{"profile", "work laptop"},
+		{"unknown_key", "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			cfg := DefaultConfig()
+			if err := cfg.Set(tt.key, tt.value); err == nil {
+				t.Errorf("Set(%q, %q) should fail", tt.key, tt.value)
+			}
+			if !reflect.DeepEqual(cfg, DefaultConfig()) {
+				t.Errorf("failed Set modified config: %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestUnset(t *testing.T) {
+	cfg := &Config{Author: "Jane", DefaultLicense: "GPL 3.0", ModulePrefix: "github.com/jane"}
+
+	for _, key := range []string{"author", "default_license", "module_prefix", "auto_git"} {
+		if err := cfg.Unset(key); err != nil {
+			t.Fatalf("Unset(%q) failed: %v", key, err)
+		}
+	}
+
+	if !reflect.DeepEqual(cfg, DefaultConfig()) {
+		t.Errorf("Unset should restore defaults, got %+v", cfg)
+	}
+	if err := cfg.Unset("nope"); err == nil {
+		t.Error("Unset of unknown key should fail")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("default config should be valid: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.ModulePrefix = "not a module"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for invalid module prefix")
+	}
+}
+
+func TestKeys(t *testing.T) {
+	keys := Keys()
+	if len(keys) != 6 {
+		t.Errorf("expected 6 keys, got %v", keys)
+	}
+	for _, k := range keys {
+		if _, err := DefaultConfig().Get(k); err != nil {
+			t.Errorf("Get(%q) failed: %v", k, err)
+		}
+	}
+}
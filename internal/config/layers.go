@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProjectFile is the repo-local config file, found by walking up from the cwd
+const ProjectFile = ".scaffoldrc"
+
+// EnvPrefix starts the environment variable for each key, e.g.
+// SCAFFOLD_MODULE_PREFIX overrides module_prefix
+const EnvPrefix = "SCAFFOLD_"
+
+// SourceDefault is the origin of values nobody overrode
+const SourceDefault = "default"
+
+// Origins maps each config key to the layer that set it: SourceDefault, the
+// path of a config file or the name of an environment variable
+type Origins map[string]string
+
+// EnvVar returns the environment variable that overrides key
+func EnvVar(key string) string {
+	return EnvPrefix + strings.ToUpper(key)
+}
+
+// FindProjectFile returns the nearest .scaffoldrc in dir or its parents,
+// or "" when there is none
+func FindProjectFile(dir string) string {
+	for {
+		path := filepath.Join(dir, ProjectFile)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// applyEnv merges SCAFFOLD_* environment variables into cfg
+func applyEnv(cfg *Config, origins Origins) error {
+	var problems []FieldError
+	for _, f := range fields {
+		name := EnvVar(f.key)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := f.set(cfg, value); err != nil {
+			problems = append(problems, FieldError{Key: name, Message: err.Error()})
+			continue
+		}
+		origins[f.key] = name
+	}
+	if len(problems) > 0 {
+		return &FileError{Path: "environment", Fields: problems}
+	}
+	return nil
+}
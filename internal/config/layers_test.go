@@ -0,0 +1,227 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain keeps the developer's own XDG_CONFIG_HOME, SCAFFOLD_* variables
+// and any .scaffoldrc above the checkout out of the tests
+func TestMain(m *testing.M) {
+	os.Unsetenv("XDG_CONFIG_HOME")
+	for _, key := range Keys() {
+		os.Unsetenv(EnvVar(key))
+	}
+
+	dir, err := os.MkdirTemp("", "scaffold-config-test-*")
+	if err != nil {
+		panic(err)
+	}
+	os.Chdir(dir)
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLayerPrecedence(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeFile(t, filepath.Join(home, ".scaffold", "config.json"), `{
+  "author": "Jane Doe",
+  "module_prefix": "github.com/jane",
+  "default_license": "GPL 3.0"
+}`)
+
+	repo := filepath.Join(home, "src", "monorepo")
+	writeFile(t, filepath.Join(repo, ProjectFile), `{"module_prefix": "corp.example.com/mono"}`)
+	work := filepath.Join(repo, "services", "billing")
+	os.MkdirAll(work, 0755)
+	t.Chdir(work)
+
+	t.Setenv("SCAFFOLD_DEFAULT_LICENSE", "Apache 2.0")
+
+	cfg, origins, err := LoadWithOrigins()
+	if err != nil {
+		t.Fatalf("LoadWithOrigins failed: %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		value  string
+		origin string
+	}{
+		{"author", "Jane Doe", filepath.Join(home, ".scaffold", "config.json")},
+		{"module_prefix", "corp.example.com/mono", filepath.Join(repo, ProjectFile)},
+		{"default_license", "Apache 2.0", "SCAFFOLD_DEFAULT_LICENSE"},
+		{"auto_git", "true", SourceDefault},
+	}
+	for _, tt := range tests {
+		got, _ := cfg.Get(tt.key)
+		if got != tt.value {
+			t.Errorf("%s = %q, want %q", tt.key, got, tt.value)
+		}
+		if origins[tt.key] != tt.origin {
+			t.Errorf("%s origin = %q, want %q", tt.key, origins[tt.key], tt.origin)
+		}
+	}
+}
+
+func TestEnvErrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SCAFFOLD_AUTO_GIT", "sometimes")
+
+	cfg, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "SCAFFOLD_AUTO_GIT") {
+		t.Errorf("expected error naming SCAFFOLD_AUTO_GIT, got %v", err)
+	}
+	if !cfg.AutoGit {
+		t.Error("invalid env value should keep the default")
+	}
+}
+
+func TestProjectFileErrorsAreReported(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ProjectFile), `{"module_prefix": 1}`)
+	t.Chdir(dir)
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), ProjectFile) {
+		t.Errorf("expected error naming %s, got %v", ProjectFile, err)
+	}
+}
+
+func TestFindProjectFile(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b", "c")
+	os.MkdirAll(nested, 0755)
+
+	if got := FindProjectFile(nested); got != "" {
+		t.Errorf("expected no project file, got %s", got)
+	}
+
+	writeFile(t, filepath.Join(root, "a", ProjectFile), `{}`)
+	if got := FindProjectFile(nested); got != filepath.Join(root, "a", ProjectFile) {
+		t.Errorf("FindProjectFile = %q, want the nearest parent's file", got)
+	}
+}
+
+func TestConfigDirSelection(t *testing.T) {
+	tests := []struct {
+		name     string
+		xdg      bool // Set XDG_CONFIG_HOME
+		files    []string
+		expected string
+	}{
+		{"nothing exists", false, nil, ".config/scaffold"},
+		{"legacy templates only", false, []string{".scaffold/templates/svc/template.json"}, ".scaffold"},
+		{"nothing exists with XDG_CONFIG_HOME", true, nil, "xdg/scaffold"},
+		{"legacy only", true, []string{".scaffold/config.json"}, ".scaffold"},
+		{"default XDG location", false, []string{".config/scaffold/config.json"}, ".config/scaffold"},
+		{"XDG wins over legacy", true, []string{".scaffold/config.json", "xdg/scaffold/config.json"}, "xdg/scaffold"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			if tt.xdg {
+				t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+			}
+			for _, f := range tt.files {
+				writeFile(t, filepath.Join(home, f), `{}`)
+			}
+
+			path, err := Path()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := filepath.Join(home, tt.expected, "config.json")
+			if path != want {
+				t.Errorf("Path() = %s, want %s", path, want)
+			}
+		})
+	}
+}
+
+func TestHooks(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeFile(t, filepath.Join(home, ".scaffold", "config.json"), `{
+  "hooks": {
+    "pre": [{"run": "make check"}],
+    "post": [{"command": ["go", "mod", "tidy"], "timeout": "1m"}]
+  }
+}`)
+	t.Chdir(t.TempDir())
+
+	cfg, origins, err := LoadWithOrigins()
+	if err != nil {
+		t.Fatalf("LoadWithOrigins failed: %v", err)
+	}
+	if len(cfg.Hooks.Pre) != 1 || cfg.Hooks.Pre[0].Run != "make check" {
+		t.Errorf("Hooks.Pre = %+v, want make check", cfg.Hooks.Pre)
+	}
+	if len(cfg.Hooks.Post) != 1 || cfg.Hooks.Post[0].String() != "go mod tidy" {
+		t.Errorf("Hooks.Post = %+v, want go mod tidy", cfg.Hooks.Post)
+	}
+	if origins["hooks"] != filepath.Join(home, ".scaffold", "config.json") {
+		t.Errorf("hooks origin = %q, want the config file", origins["hooks"])
+	}
+
+	// A .scaffoldrc replaces the hooks of the user config
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, ProjectFile), `{"hooks": {"post": [{"run": "npm ci"}]}}`)
+	t.Chdir(repo)
+	cfg, origins, err = LoadWithOrigins()
+	if err != nil {
+		t.Fatalf("LoadWithOrigins failed: %v", err)
+	}
+	if len(cfg.Hooks.Pre) != 0 || len(cfg.Hooks.Post) != 1 || cfg.Hooks.Post[0].Run != "npm ci" {
+		t.Errorf("Hooks = %+v, want only npm ci", cfg.Hooks)
+	}
+	if origins["hooks"] != filepath.Join(repo, ProjectFile) {
+		t.Errorf("hooks origin = %q, want %s", origins["hooks"], ProjectFile)
+	}
+}
+
+func TestInvalidHooks(t *testing.T) {
+	tests := []struct {
+		name  string
+		hooks string
+		want  string
+	}{
+		{"unknown field", `{"post": [{"run": "x", "shell": "bash"}]}`, `unknown field "shell"`},
+		{"no command", `{"pre": [{"name": "empty"}]}`, "set exactly one of run and command"},
+		{"bad timeout", `{"post": [{"run": "x", "timeout": "forever"}]}`, `invalid timeout "forever"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			writeFile(t, filepath.Join(home, ".scaffold", "config.json"), `{"author": "Jane", "hooks": `+tt.hooks+`}`)
+			t.Chdir(t.TempDir())
+
+			cfg, err := Load()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load error = %v, want %q", err, tt.want)
+			}
+			if cfg.Author != "Jane" || len(cfg.Hooks.Pre)+len(cfg.Hooks.Post) != 0 {
+				t.Errorf("got %+v, want the author applied and the hooks dropped", cfg)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/purnama/scaffold/internal/hooks"
+)
+
+// Profile is a named set of defaults, e.g. "work" and "personal", selected
+// with --profile, SCAFFOLD_PROFILE or the profile key. Empty fields fall
+// back to the top-level config.
+type Profile struct {
+	Author       string       `json:"author,omitempty"`
+	ModulePrefix string       `json:"module_prefix,omitempty"`
+	License      string       `json:"license,omitempty"`
+	Template     string       `json:"template,omitempty"`   // Used by init when no template is given
+	Components   []string     `json:"components,omitempty"` // Added to every new project
+	Hooks        []hooks.Hook `json:"hooks,omitempty"`      // Run in the new project, a string is short for {"run": ...}
+
+	Source string `json:"-"` // Config file the profile was read from
+}
+
+// UnmarshalJSON decodes the hooks like the hooks key, so they can be
+// commands or hook objects
+func (p *Profile) UnmarshalJSON(data []byte) error {
+	type profile Profile
+	var raw struct {
+		profile
+		Hooks json.RawMessage `json:"hooks"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	hs, err := decodeHookList(raw.Hooks)
+	if err != nil {
+		return fmt.Errorf("hooks: %w", err)
+	}
+	*p = Profile(raw.profile)
+	p.Hooks = hs
+	return nil
+}
+
+// profileKeys are the keys a profile may set
+var profileKeys = []string{"author", "module_prefix", "license", "template", "components", "hooks"}
+
+var profileNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// ValidateProfileName checks that name can be used as a profile name
+func ValidateProfileName(name string) error {
+	if !profileNameRe.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// Validate checks the profile's license and module prefix
+func (p Profile) Validate() error {
+	if p.License != "" && !IsValidLicense(p.License) {
+		return fmt.Errorf("unknown license %q", p.License)
+	}
+	return ValidateModulePrefix(p.ModulePrefix)
+}
+
+// ProfileNames returns the defined profile names, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ActiveProfile returns the selected profile, or false when none is selected
+func (c *Config) ActiveProfile() (Profile, bool) {
+	if c.Profile == "" {
+		return Profile{}, false
+	}
+	p, ok := c.Profiles[c.Profile]
+	return p, ok
+}
+
+// applyProfile copies the non-empty fields of the named profile over cfg
+func applyProfile(cfg *Config, origins Origins, name string) error {
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+
+	origin := "profile " + name
+	if p.Author != "" {
+		cfg.Author = p.Author
+		origins["author"] = origin
+	}
+	if p.ModulePrefix != "" {
+		cfg.ModulePrefix = p.ModulePrefix
+		origins["module_prefix"] = origin
+	}
+	if p.License != "" {
+		cfg.DefaultLicense = p.License
+		origins["default_license"] = origin
+	}
+	return nil
+}
+
+// decodeProfiles merges the profiles object of a config file into cfg.
+// Profiles with the same name in a later layer replace earlier ones.
+func decodeProfiles(cfg *Config, value json.RawMessage) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(value, &raw); err != nil {
+		return fmt.Errorf("expected an object of profiles, got %s", jsonKind(value))
+	}
+
+	var problems fieldErrors
+	profiles := make(map[string]Profile, len(raw))
+	for _, name := range sortedKeys(raw) {
+		key := "profiles." + name
+		if err := ValidateProfileName(name); err != nil {
+			problems = append(problems, FieldError{Key: key, Message: err.Error()})
+			continue
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw[name], &fields); err != nil {
+			problems = append(problems, FieldError{Key: key, Message: "expected an object, got " + jsonKind(raw[name])})
+			continue
+		}
+		known := true
+		for _, k := range sortedKeys(fields) {
+			if !slices.Contains(profileKeys, k) {
+				problems = append(problems, FieldError{Key: key + "." + k, Message: "unknown key, expected one of: " + strings.Join(profileKeys, ", ")})
+				known = false
+			}
+		}
+		if !known {
+			continue
+		}
+
+		var p Profile
+		if err := json.Unmarshal(raw[name], &p); err != nil {
+			problems = append(problems, FieldError{Key: key, Message: err.Error()})
+			continue
+		}
+		if err := p.Validate(); err != nil {
+			problems = append(problems, FieldError{Key: key, Message: err.Error()})
+			continue
+		}
+		profiles[name] = p
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]Profile{}
+	}
+	for name, p := range profiles {
+		cfg.Profiles[name] = p
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
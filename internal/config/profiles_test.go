@@ -0,0 +1,210 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const profilesConfig = `{
+  "author": "Jane Doe",
+  "module_prefix": "github.com/jane",
+  "profile": "personal",
+  "profiles": {
+    "personal": {"license": "MIT", "template": "go-cli"},
+    "work": {
+      "author": "ACME Corp",
+      "module_prefix": "git.acme.com/platform",
+      "license": "Apache 2.0",
+      "template": "go-api",
+      "components": ["makefile", "github-actions"],
+      "hooks": ["make setup"]
+    }
+  }
+}`
+
+func TestLoadProfile(t *testing.T) {
+	tests := []struct {
+		name       string
+		flag       string
+		env        string
+		wantAuthor string
+		wantPrefix string
+		wantLic    string
+		wantActive string
+		wantOrigin string
+	}{
+		{"profile key", "", "", "Jane Doe", "github.com/jane", "MIT", "personal", "profile personal"},
+		{"env", "", "work", "ACME Corp", "git.acme.com/platform", "Apache 2.0", "work", "profile work"},
+		{"flag beats env", "personal", "work", "Jane Doe", "github.com/jane", "MIT", "personal", "profile personal"},
+		{"flag", "work", "", "ACME Corp", "git.acme.com/platform", "Apache 2.0", "work", "profile work"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			writeFile(t, filepath.Join(home, ".scaffold", "config.json"), profilesConfig)
+			if tt.env != "" {
+				t.Setenv("SCAFFOLD_PROFILE", tt.env)
+			}
+
+			cfg, origins, err := LoadProfile(tt.flag)
+			if err != nil {
+				t.Fatalf("LoadProfile failed: %v", err)
+			}
+			if cfg.Author != tt.wantAuthor || cfg.ModulePrefix != tt.wantPrefix || cfg.DefaultLicense != tt.wantLic {
+				t.Errorf("got author=%q prefix=%q license=%q", cfg.Author, cfg.ModulePrefix, cfg.DefaultLicense)
+			}
+			if cfg.Profile != tt.wantActive {
+				t.Errorf("Profile = %q, want %q", cfg.Profile, tt.wantActive)
+			}
+			if origins["default_license"] != tt.wantOrigin {
+				t.Errorf("default_license origin = %q, want %q", origins["default_license"], tt.wantOrigin)
+			}
+
+			p, ok := cfg.ActiveProfile()
+			if !ok {
+				t.Fatal("ActiveProfile returned false")
+			}
+			if tt.wantActive == "work" && (len(p.Components) != 2 || len(p.Hooks) != 1 || p.Template != "go-api") {
+				t.Errorf("unexpected work profile: %+v", p)
+			}
+		})
+	}
+}
+
+func TestEnvBeatsProfile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeFile(t, filepath.Join(home, ".scaffold", "config.json"), profilesConfig)
+	t.Setenv("SCAFFOLD_AUTHOR", "Someone Else")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Author != "Someone Else" {
+		t.Errorf("Author = %q, SCAFFOLD_AUTHOR should beat the profile", cfg.Author)
+	}
+}
+
+func TestUnknownProfile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeFile(t, filepath.Join(home, ".scaffold", "config.json"), profilesConfig)
+
+	_, _, err := LoadProfile("nope")
+	if err == nil || !strings.Contains(err.Error(), `unknown profile "nope"`) {
+		t.Errorf("expected unknown profile error, got %v", err)
+	}
+}
+
+func TestProfilesAreMergedAcrossLayers(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeFile(t, filepath.Join(home, ".scaffold", "config.json"), profilesConfig)
+
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, ProjectFile), `{
+  "profile": "oss",
+  "profiles": {"oss": {"license": "GPL 3.0"}}
+}`)
+	t.Chdir(repo)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.ProfileNames(); strings.Join(got, ",") != "oss,personal,work" {
+		t.Errorf("ProfileNames = %v", got)
+	}
+	if cfg.DefaultLicense != "GPL 3.0" {
+		t.Errorf("DefaultLicense = %q, want the .scaffoldrc profile's license", cfg.DefaultLicense)
+	}
+
+	// Hooks of a profile from a .scaffoldrc need trust, so each profile
+	// knows where it came from
+	for name, want := range map[string]string{
+		"oss":      filepath.Join(repo, ProjectFile),
+		"personal": filepath.Join(home, ".scaffold", "config.json"),
+	} {
+		if got := cfg.Profiles[name].Source; got != want {
+			t.Errorf("profile %s Source = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestInvalidProfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"not an object", `{"profiles": []}`, "expected an object of profiles"},
+		{"bad name", `{"profiles": {"my work": {}}}`, "invalid profile name"},
+		{"bad license", `{"profiles": {"work": {"license": "WTFPL"}}}`, "unknown license"},
+		{"bad prefix", `{"profiles": {"work": {"module_prefix": "/abs"}}}`, "invalid module prefix"},
+		{"unknown key", `{"profiles": {"work": {"licence": "MIT"}}}`, "profiles.work.licence: unknown key"},
+		{"not an object profile", `{"profiles": {"work": "MIT"}}`, "profiles.work: expected an object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			writeFile(t, path, tt.content)
+
+			_, err := LoadFile(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestProfileHooks(t *testing.T) {
+	tests := []struct {
+		name  string
+		hooks string
+		err   string
+	}{
+		{"command", `["make setup"]`, ""},
+		{"object", `[{"name": "lint", "command": ["golangci-lint", "run"], "dir": "api", "timeout": "1m", "requires": ["golangci-lint"], "continue_on_error": true}]`, ""},
+		{"mixed", `["make setup", {"run": "npm ci", "env": {"CI": "1"}}]`, ""},
+		{"invalid hook", `[{"name": "both", "run": "true", "command": ["true"]}]`, "set exactly one of run and command"},
+		{"unknown field", `[{"run": "true", "shell": "bash"}]`, "unknown field"},
+		{"not a list", `"make setup"`, "expected a list of hooks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			writeFile(t, filepath.Join(home, ".scaffold", "config.json"), `{"profile": "work", "profiles": {"work": {"hooks": `+tt.hooks+`}}}`)
+
+			cfg, _, err := LoadProfile("")
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("LoadProfile error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadProfile failed: %v", err)
+			}
+			p, _ := cfg.ActiveProfile()
+			if len(p.Hooks) == 0 {
+				t.Fatal("profile has no hooks")
+			}
+			if tt.name == "object" {
+				h := p.Hooks[0]
+				if h.String() != "lint" || h.Dir != "api" || h.Timeout != "1m" || len(h.Requires) != 1 || !h.ContinueOnError {
+					t.Errorf("hook = %+v, want every field decoded", h)
+				}
+			}
+			if tt.name == "mixed" && (p.Hooks[0].Run != "make setup" || p.Hooks[1].Env["CI"] != "1") {
+				t.Errorf("hooks = %+v", p.Hooks)
+			}
+		})
+	}
+}
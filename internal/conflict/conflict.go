@@ -0,0 +1,159 @@
+// Package conflict decides what happens when a generated file already exists
+package conflict
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Mode is the strategy for files that already exist
+type Mode string
+
+const (
+	Fail      Mode = ""          // Refuse to touch existing files
+	Skip      Mode = "skip"      // Keep the existing file
+	Overwrite Mode = "overwrite" // Replace the existing file
+	Backup    Mode = "backup"    // Save the existing file as <name>.orig, then replace it
+	Prompt    Mode = "prompt"    // Show a diff and ask for each file
+	Merge     Mode = "merge"     // Add missing lines or Makefile targets, keep everything else
+)
+
+// Modes lists the modes accepted by --conflict
+var Modes = []Mode{Skip, Overwrite, Backup, Prompt, Merge}
+
+// ParseMode validates a --conflict value
+func ParseMode(s string) (Mode, error) {
+	for _, m := range Modes {
+		if string(m) == s {
+			return m, nil
+		}
+	}
+	return Fail, fmt.Errorf("unknown conflict mode %q, expected one of: %s", s, ModeNames())
+}
+
+// ModeNames returns the modes joined for help and error messages
+func ModeNames() string {
+	names := make([]string, len(Modes))
+	for i, m := range Modes {
+		names[i] = string(m)
+	}
+	return strings.Join(names, "|")
+}
+
+// ErrExists is returned in Fail mode for files that already exist
+var ErrExists = errors.New("file already exists")
+
+// Action is what was decided for a single file
+type Action string
+
+const (
+	Created     Action = "created"
+	Unchanged   Action = "unchanged" // Existing content is identical or already has everything
+	Overwritten Action = "overwritten"
+	Skipped     Action = "skipped"
+	BackedUp    Action = "backed up"
+	Merged      Action = "merged"
+)
+
+// Asker chooses a mode for one file in Prompt mode. It must return Skip,
+// Overwrite, Backup or Merge.
+type Asker func(path string, existing, proposed []byte) (Mode, error)
+
+// Resolution is the decision for one file
+type Resolution struct {
+	Action  Action
+	Content []byte // To write, nil when the file must be left alone
+	Backup  string // Where the existing content must be saved first, relative like the file
+	Reason  string // Why a merge fell back to keeping the file
+}
+
+// Write reports whether the file has to be written
+func (r Resolution) Write() bool {
+	return r.Content != nil
+}
+
+// ResolveFile decides how to write proposed to rel inside root, reading the
+// existing file if there is one
+func ResolveFile(mode Mode, root, rel string, proposed []byte, ask Asker) (Resolution, error) {
+	target := filepath.Join(root, rel)
+	info, err := os.Lstat(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Resolution{Action: Created, Content: proposed}, nil
+	}
+	if err != nil {
+		return Resolution{}, err
+	}
+	if info.IsDir() {
+		return Resolution{}, fmt.Errorf("%s: a directory with that name exists", rel)
+	}
+
+	existing, err := os.ReadFile(target)
+	if err != nil {
+		return Resolution{}, err
+	}
+	res, err := Resolve(mode, rel, existing, proposed, ask)
+	if err != nil {
+		return Resolution{}, err
+	}
+	if res.Action == BackedUp {
+		res.Backup = backupPath(root, rel)
+	}
+	return res, nil
+}
+
+// Resolve decides how to replace existing with proposed at path
+func Resolve(mode Mode, path string, existing, proposed []byte, ask Asker) (Resolution, error) {
+	if bytes.Equal(existing, proposed) {
+		return Resolution{Action: Unchanged}, nil
+	}
+
+	switch mode {
+	case Fail:
+		return Resolution{}, fmt.Errorf("%w: %s", ErrExists, path)
+	case Skip:
+		return Resolution{Action: Skipped}, nil
+	case Overwrite:
+		return Resolution{Action: Overwritten, Content: proposed}, nil
+	case Backup:
+		return Resolution{Action: BackedUp, Content: proposed}, nil
+	case Merge:
+		merged, ok := MergeContent(path, existing, proposed)
+		if !ok {
+			return Resolution{Action: Skipped, Reason: "can't merge this kind of file"}, nil
+		}
+		if bytes.Equal(merged, existing) {
+			return Resolution{Action: Unchanged}, nil
+		}
+		return Resolution{Action: Merged, Content: merged}, nil
+	case Prompt:
+		if ask == nil {
+			return Resolution{}, fmt.Errorf("%s exists and there is no way to ask what to do", path)
+		}
+		choice, err := ask(path, existing, proposed)
+		if err != nil {
+			return Resolution{}, err
+		}
+		if choice == Prompt || choice == Fail {
+			return Resolution{}, fmt.Errorf("invalid choice %q for %s", choice, path)
+		}
+		return Resolve(choice, path, existing, proposed, nil)
+	default:
+		return Resolution{}, fmt.Errorf("unknown conflict mode %q", mode)
+	}
+}
+
+// backupPath returns <rel>.orig, or <rel>.orig.N when that is taken
+func backupPath(root, rel string) string {
+	candidate := rel + ".orig"
+	for n := 1; ; n++ {
+		if _, err := os.Lstat(filepath.Join(root, candidate)); errors.Is(err, fs.ErrNotExist) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s.orig.%d", rel, n)
+	}
+}
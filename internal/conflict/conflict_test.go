@@ -0,0 +1,126 @@
+package conflict
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	for _, m := range Modes {
+		if got, err := ParseMode(string(m)); err != nil || got != m {
+			t.Errorf("ParseMode(%q) = %q, %v", m, got, err)
+		}
+	}
+	if _, err := ParseMode("clobber"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	existing := []byte("bin/\n")
+	proposed := []byte("bin/\n*.out\n")
+
+	tests := []struct {
+		mode        Mode
+		path        string
+		wantAction  Action
+		wantContent string
+	}{
+		{Skip, ".gitignore", Skipped, ""},
+		{Overwrite, ".gitignore", Overwritten, "bin/\n*.out\n"},
+		{Backup, ".gitignore", BackedUp, "bin/\n*.out\n"},
+		{Merge, ".gitignore", Merged, "bin/\n\n*.out\n"},
+		{Merge, "main.go", Skipped, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode)+" "+tt.path, func(t *testing.T) {
+			res, err := Resolve(tt.mode, tt.path, existing, proposed, nil)
+			if err != nil {
+				t.Fatalf("Resolve failed: %v", err)
+			}
+			if res.Action != tt.wantAction {
+				t.Errorf("Action = %q, want %q", res.Action, tt.wantAction)
+			}
+			if string(res.Content) != tt.wantContent || res.Write() != (tt.wantContent != "") {
+				t.Errorf("Content = %q, want %q", res.Content, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestResolveFail(t *testing.T) {
+	_, err := Resolve(Fail, "main.go", []byte("a"), []byte("b"), nil)
+	if !errors.Is(err, ErrExists) {
+		t.Errorf("expected ErrExists, got %v", err)
+	}
+
+	res, err := Resolve(Fail, "main.go", []byte("same"), []byte("same"), nil)
+	if err != nil || res.Action != Unchanged {
+		t.Errorf("identical content should be unchanged, got %v, %v", res.Action, err)
+	}
+}
+
+func TestResolvePrompt(t *testing.T) {
+	var asked string
+	ask := func(path string, existing, proposed []byte) (Mode, error) {
+		asked = path
+		return Backup, nil
+	}
+
+	res, err := Resolve(Prompt, "README.md", []byte("mine"), []byte("theirs"), ask)
+	if err != nil || res.Action != BackedUp {
+		t.Errorf("expected the answer to be applied, got %v, %v", res.Action, err)
+	}
+	if asked != "README.md" {
+		t.Errorf("asked about %q", asked)
+	}
+
+	if _, err := Resolve(Prompt, "README.md", []byte("mine"), []byte("theirs"), nil); err == nil {
+		t.Error("prompt without an Asker should fail")
+	}
+}
+
+func TestResolveFile(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "README.md"), []byte("mine"), 0644)
+	os.WriteFile(filepath.Join(root, "README.md.orig"), []byte("older"), 0644)
+
+	res, err := ResolveFile(Backup, root, "README.md", []byte("new"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Backup != "README.md.orig.1" {
+		t.Errorf("Backup = %q, want a free name", res.Backup)
+	}
+
+	res, err = ResolveFile(Fail, root, "LICENSE", []byte("new"), nil)
+	if err != nil || res.Action != Created {
+		t.Errorf("missing file should be created, got %v, %v", res.Action, err)
+	}
+}
+
+func TestPrompter(t *testing.T) {
+	var out strings.Builder
+	ask := NewPrompter(strings.NewReader("x\nm\n\n"), &out)
+
+	mode, err := ask(".gitignore", []byte("a\n"), []byte("b\n"))
+	if err != nil || mode != Merge {
+		t.Errorf("expected merge after an invalid answer, got %q, %v", mode, err)
+	}
+	if !strings.Contains(out.String(), "-a\n+b\n") {
+		t.Errorf("prompt should show the diff, got:\n%s", out.String())
+	}
+
+	mode, err = ask("main.go", []byte("a\n"), []byte("b\n"))
+	if err != nil || mode != Skip {
+		t.Errorf("empty answer should skip, got %q, %v", mode, err)
+	}
+
+	if _, err := ask("main.go", nil, []byte("b")); err == nil {
+		t.Error("expected error at end of input")
+	}
+}
@@ -0,0 +1,222 @@
+package conflict
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/purnama/scaffold/internal/diff"
+)
+
+// lineFiles are merged line by line
+var lineFiles = map[string]bool{
+	".gitignore":      true,
+	".dockerignore":   true,
+	".gitattributes":  true,
+	".helmignore":     true,
+	".npmignore":      true,
+	".prettierignore": true,
+	".eslintignore":   true,
+	"CODEOWNERS":      true,
+}
+
+// Mergeable reports whether MergeContent knows how to combine path
+func Mergeable(path string) bool {
+	return isMakefile(path) || isLineFile(path)
+}
+
+func isLineFile(path string) bool {
+	base := filepath.Base(path)
+	return lineFiles[base] || base == ".env" || strings.HasPrefix(base, ".env.")
+}
+
+func isMakefile(path string) bool {
+	base := filepath.Base(path)
+	return base == "Makefile" || base == "makefile" || base == "GNUmakefile" || filepath.Ext(base) == ".mk"
+}
+
+// MergeContent adds what proposed has and existing lacks to the end of
+// existing: missing lines for line-based files such as .gitignore, missing
+// variables and targets for Makefiles. It returns false for other files.
+func MergeContent(path string, existing, proposed []byte) ([]byte, bool) {
+	switch {
+	case isMakefile(path):
+		return []byte(mergeMakefile(string(existing), string(proposed))), true
+	case isLineFile(path):
+		return []byte(mergeLines(string(existing), string(proposed))), true
+	default:
+		return nil, false
+	}
+}
+
+// mergeLines appends the lines of proposed missing from existing. Comments
+// directly above a missing line come along; blank lines separate groups.
+func mergeLines(existing, proposed string) string {
+	have := map[string]bool{}
+	for _, l := range diff.SplitLines(existing) {
+		have[strings.TrimSpace(l)] = true
+	}
+
+	var added []string
+	var comments []string
+	gap := false
+	for _, l := range diff.SplitLines(proposed) {
+		line := strings.TrimSpace(l)
+		switch {
+		case line == "":
+			comments = nil
+			gap = len(added) > 0
+		case strings.HasPrefix(line, "#"):
+			comments = append(comments, line)
+		case !have[line]:
+			if gap {
+				added = append(added, "")
+				gap = false
+			}
+			for _, c := range comments {
+				if !have[c] {
+					added = append(added, c)
+					have[c] = true
+				}
+			}
+			comments = nil
+			added = append(added, line)
+			have[line] = true
+		default:
+			comments = nil
+		}
+	}
+	return appendBlock(existing, added)
+}
+
+var (
+	makeVarRe    = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*(\?=|:=|::=|\+=|!=|=)`)
+	makeTargetRe = regexp.MustCompile(`^([^\s#:=][^:=]*):([^=]|$)`)
+)
+
+// makeRule is a Makefile target with its recipe and leading comments
+type makeRule struct {
+	targets []string
+	lines   []string
+}
+
+// parseMakefile returns the variable assignments, .PHONY targets and rules
+// of a Makefile. Lines belonging to neither are ignored.
+func parseMakefile(s string) (vars map[string]string, phony map[string]bool, rules []makeRule) {
+	vars = map[string]string{}
+	phony = map[string]bool{}
+
+	var comments []string
+	var current *makeRule
+	for _, l := range diff.SplitLines(s) {
+		line := strings.TrimRight(l, "\r\n")
+
+		if current != nil && (strings.HasPrefix(line, "\t") || strings.HasSuffix(current.lines[len(current.lines)-1], "\\")) {
+			current.lines = append(current.lines, line)
+			continue
+		}
+		current = nil
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			comments = nil
+		case strings.HasPrefix(line, "#"):
+			comments = append(comments, line)
+		case strings.HasPrefix(line, ".PHONY:"):
+			for _, t := range strings.Fields(strings.TrimPrefix(line, ".PHONY:")) {
+				phony[t] = true
+			}
+			comments = nil
+		case makeVarRe.MatchString(line):
+			vars[makeVarRe.FindStringSubmatch(line)[1]] = line
+			comments = nil
+		case makeTargetRe.MatchString(line):
+			rules = append(rules, makeRule{
+				targets: strings.Fields(makeTargetRe.FindStringSubmatch(line)[1]),
+				lines:   append(comments, line),
+			})
+			current = &rules[len(rules)-1]
+			comments = nil
+		default:
+			comments = nil
+		}
+	}
+	return vars, phony, rules
+}
+
+// mergeMakefile appends the variables and targets of proposed that existing
+// doesn't define. Existing rules are never changed.
+func mergeMakefile(existing, proposed string) string {
+	haveVars, _, haveRules := parseMakefile(existing)
+	have := map[string]bool{}
+	for _, r := range haveRules {
+		for _, t := range r.targets {
+			have[t] = true
+		}
+	}
+
+	newVars, newPhony, newRules := parseMakefile(proposed)
+
+	var varLines []string
+	for _, l := range diff.SplitLines(proposed) {
+		m := makeVarRe.FindStringSubmatch(strings.TrimRight(l, "\r\n"))
+		if m != nil && haveVars[m[1]] == "" && newVars[m[1]] != "" {
+			varLines = append(varLines, newVars[m[1]])
+			haveVars[m[1]] = newVars[m[1]]
+		}
+	}
+
+	var phony []string
+	var ruleLines []string
+	for _, r := range newRules {
+		missing := false
+		for _, t := range r.targets {
+			if !have[t] {
+				missing = true
+			}
+		}
+		if !missing {
+			continue
+		}
+		for _, t := range r.targets {
+			have[t] = true
+			if newPhony[t] {
+				phony = append(phony, t)
+			}
+		}
+		if len(ruleLines) > 0 {
+			ruleLines = append(ruleLines, "")
+		}
+		ruleLines = append(ruleLines, r.lines...)
+	}
+
+	var added []string
+	added = append(added, varLines...)
+	if len(phony) > 0 {
+		if len(added) > 0 {
+			added = append(added, "")
+		}
+		added = append(added, ".PHONY: "+strings.Join(phony, " "))
+	}
+	if len(ruleLines) > 0 {
+		if len(added) > 0 {
+			added = append(added, "")
+		}
+		added = append(added, ruleLines...)
+	}
+	return appendBlock(existing, added)
+}
+
+// appendBlock adds lines to the end of s, separated by a blank line
+func appendBlock(s string, lines []string) string {
+	if len(lines) == 0 {
+		return s
+	}
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	if s != "" && !strings.HasSuffix(s, "\n\n") {
+		s += "\n"
+	}
+	return s + strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,95 @@
+package conflict
+
+import "testing"
+
+func TestMergeLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		proposed string
+		want     string
+	}{
+		{"nothing missing", "bin/\n*.test\n", "*.test\nbin/\n", "bin/\n*.test\n"},
+		{"appends missing", "bin/\n", "bin/\n*.out\n", "bin/\n\n*.out\n"},
+		{"no trailing newline", "bin/", "vendor/\n", "bin/\n\nvendor/\n"},
+		{"empty existing", "", "bin/\n", "bin/\n"},
+		{
+			"keeps comments and groups",
+			"bin/\n",
+			"# Binaries\nbin/\n\n# Test output\n*.out\ncoverage.html\n\n# IDE\n.idea/\n",
+			"bin/\n\n# Test output\n*.out\ncoverage.html\n\n# IDE\n.idea/\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeLines(tt.existing, tt.proposed); got != tt.want {
+				t.Errorf("mergeLines() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeMakefile(t *testing.T) {
+	existing := `BINARY=app
+
+.PHONY: build
+build:
+	go build -o bin/$(BINARY) .
+`
+	proposed := `BINARY=tool
+GOFLAGS ?= -trimpath
+
+.PHONY: build test lint
+
+## build: compile
+build:
+	go build $(GOFLAGS) -o bin/$(BINARY) .
+
+## test: run tests
+test:
+	go test ./... \
+		-race
+
+lint:
+	golangci-lint run
+`
+	want := existing + `
+GOFLAGS ?= -trimpath
+
+.PHONY: test lint
+
+## test: run tests
+test:
+	go test ./... \
+		-race
+
+lint:
+	golangci-lint run
+`
+
+	if got := mergeMakefile(existing, proposed); got != want {
+		t.Errorf("mergeMakefile() =\n%s\nwant\n%s", got, want)
+	}
+	if got := mergeMakefile(want, proposed); got != want {
+		t.Errorf("merging twice should change nothing, got\n%s", got)
+	}
+}
+
+func TestMergeable(t *testing.T) {
+	tests := map[string]bool{
+		".gitignore":         true,
+		"web/.dockerignore":  true,
+		".env.example":       true,
+		"Makefile":           true,
+		"build/rules.mk":     true,
+		"README.md":          false,
+		"main.go":            false,
+		"docker-compose.yml": false,
+	}
+	for path, want := range tests {
+		if got := Mergeable(path); got != want {
+			t.Errorf("Mergeable(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
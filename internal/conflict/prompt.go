@@ -0,0 +1,48 @@
+package conflict
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/purnama/scaffold/internal/diff"
+)
+
+// NewPrompter returns an Asker that shows the diff between the existing and
+// the new file on out and reads the answer from in
+func NewPrompter(in io.Reader, out io.Writer) Asker {
+	reader := bufio.NewReader(in)
+	return func(path string, existing, proposed []byte) (Mode, error) {
+		fmt.Fprintf(out, "\n%s already exists:\n", path)
+		fmt.Fprint(out, diff.Unified(path+" (existing)", path+" (new)", string(existing), string(proposed), 3))
+
+		options := "[o]verwrite, [s]kip, [b]ackup"
+		if Mergeable(path) {
+			options += ", [m]erge"
+		}
+		for {
+			fmt.Fprintf(out, "%s? (s) ", options)
+			answer, err := reader.ReadString('\n')
+			if err != nil && answer == "" {
+				return Fail, fmt.Errorf("no answer for %s: %w", path, err)
+			}
+
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "", "s", "skip":
+				return Skip, nil
+			case "o", "overwrite":
+				return Overwrite, nil
+			case "b", "backup":
+				return Backup, nil
+			case "m", "merge":
+				if Mergeable(path) {
+					return Merge, nil
+				}
+			}
+			if err != nil {
+				return Fail, fmt.Errorf("no answer for %s: %w", path, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,189 @@
+// Package diff compares texts line by line and formats unified diffs
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind says whether a line is shared, removed or added
+type Kind int
+
+const (
+	Equal Kind = iota
+	Delete
+	Insert
+)
+
+// Line is one step of an edit script
+type Line struct {
+	Kind Kind
+	Text string // Includes the trailing newline, if any
+}
+
+// SplitLines splits s after each newline. The last line has no newline when
+// s doesn't end with one.
+func SplitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Lines returns the shortest edit script turning a into b
+func Lines(a, b []string) []Line {
+	// Common prefix and suffix don't need the quadratic part
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var script []Line
+	for _, l := range a[:prefix] {
+		script = append(script, Line{Equal, l})
+	}
+	script = append(script, lcs(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, l := range a[len(a)-suffix:] {
+		script = append(script, Line{Equal, l})
+	}
+	return script
+}
+
+// lcs builds the edit script from a longest common subsequence table
+func lcs(a, b []string) []Line {
+	n, m := len(a), len(b)
+	table := make([]int32, (n+1)*(m+1))
+	at := func(i, j int) int32 { return table[i*(m+1)+j] }
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i*(m+1)+j] = at(i+1, j+1) + 1
+			} else {
+				table[i*(m+1)+j] = max(at(i+1, j), at(i, j+1))
+			}
+		}
+	}
+
+	script := make([]Line, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			script = append(script, Line{Equal, a[i]})
+			i++
+			j++
+		case at(i+1, j) >= at(i, j+1):
+			script = append(script, Line{Delete, a[i]})
+			i++
+		default:
+			script = append(script, Line{Insert, b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		script = append(script, Line{Delete, a[i]})
+	}
+	for ; j < m; j++ {
+		script = append(script, Line{Insert, b[j]})
+	}
+	return script
+}
+
+// Unified returns the unified diff from a to b with the given number of
+// context lines, or "" when they are equal
+func Unified(fromName, toName, a, b string, context int) string {
+	if a == b {
+		return ""
+	}
+	script := Lines(SplitLines(a), SplitLines(b))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+
+	for start := 0; start < len(script); {
+		// Find the next change and extend the hunk while changes are close
+		first := start
+		for first < len(script) && script[first].Kind == Equal {
+			first++
+		}
+		if first == len(script) {
+			break
+		}
+		hunkStart := max(first-context, start)
+		end := first
+		for {
+			for end < len(script) && script[end].Kind != Equal {
+				end++
+			}
+			next := end
+			for next < len(script) && script[next].Kind == Equal {
+				next++
+			}
+			if next == len(script) || next-end > 2*context {
+				break
+			}
+			end = next
+		}
+		hunkEnd := min(end+context, len(script))
+
+		writeHunk(&out, script, hunkStart, hunkEnd)
+		start = hunkEnd
+	}
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, script []Line, start, end int) {
+	// Line numbers of the hunk start in both texts
+	aLine, bLine := 1, 1
+	for _, l := range script[:start] {
+		if l.Kind != Insert {
+			aLine++
+		}
+		if l.Kind != Delete {
+			bLine++
+		}
+	}
+	aCount, bCount := 0, 0
+	for _, l := range script[start:end] {
+		if l.Kind != Insert {
+			aCount++
+		}
+		if l.Kind != Delete {
+			bCount++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(aLine, aCount), hunkRange(bLine, bCount))
+
+	for _, l := range script[start:end] {
+		prefix := " "
+		switch l.Kind {
+		case Delete:
+			prefix = "-"
+		case Insert:
+			prefix = "+"
+		}
+		out.WriteString(prefix + l.Text)
+		if !strings.HasSuffix(l.Text, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(line, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", line-1)
+	case 1:
+		return fmt.Sprint(line)
+	default:
+		return fmt.Sprintf("%d,%d", line, count)
+	}
+}
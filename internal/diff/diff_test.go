@@ -0,0 +1,95 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\n", []string{"a\n"}},
+		{"a\nb", []string{"a\n", "b"}},
+		{"a\n\nb\n", []string{"a\n", "\n", "b\n"}},
+	}
+
+	for _, tt := range tests {
+		got := SplitLines(tt.input)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("SplitLines(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestLinesIsMinimal(t *testing.T) {
+	a := SplitLines("a\nb\nc\nd\n")
+	b := SplitLines("a\nc\nd\ne\n")
+
+	var edits int
+	var rebuilt strings.Builder
+	for _, l := range Lines(a, b) {
+		if l.Kind != Equal {
+			edits++
+		}
+		if l.Kind != Delete {
+			rebuilt.WriteString(l.Text)
+		}
+	}
+	if edits != 2 {
+		t.Errorf("expected 2 edits, got %d", edits)
+	}
+	if rebuilt.String() != "a\nc\nd\ne\n" {
+		t.Errorf("script doesn't rebuild b: %q", rebuilt.String())
+	}
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"equal", "x\n", "x\n", ""},
+		{
+			"change in the middle",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			"1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			"--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			"insert into empty",
+			"",
+			"new\n",
+			"--- a\n+++ b\n@@ -0,0 +1 @@\n+new\n",
+		},
+		{
+			"missing newline",
+			"a\nb",
+			"a\nb\n",
+			"--- a\n+++ b\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			"two hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			"one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			"--- a\n+++ b\n@@ -1,2 +1,2 @@\n-1\n+one\n 2\n@@ -9,2 +9,2 @@\n 9\n-10\n+ten\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			context := 3
+			if tt.name == "two hunks" {
+				context = 1
+			}
+			got := Unified("a", "b", tt.a, tt.b, context)
+			if got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package diff
+
+import "strings"
+
+// Merge3 applies the changes from base to ours and from base to theirs
+// together. Where both changed the same lines differently, both versions are
+// kept between conflict markers labelled oursName and theirsName. It returns
+// the merged text and the number of conflicts.
+func Merge3(base, ours, theirs, oursName, theirsName string) (string, int) {
+	b, o, t := SplitLines(base), SplitLines(ours), SplitLines(theirs)
+	inOurs, inTheirs := matches(b, o), matches(b, t)
+
+	var out strings.Builder
+	conflicts := 0
+	i, j, k := 0, 0, 0
+	for {
+		// Lines all three agree on
+		for i < len(b) && inOurs[i] == j && inTheirs[i] == k {
+			out.WriteString(b[i])
+			i, j, k = i+1, j+1, k+1
+		}
+		if i == len(b) && j == len(o) && k == len(t) {
+			return out.String(), conflicts
+		}
+
+		// The changed chunk ends at the next base line both sides kept
+		n := i
+		for n < len(b) && (inOurs[n] < 0 || inTheirs[n] < 0) {
+			n++
+		}
+		oEnd, tEnd := len(o), len(t)
+		if n < len(b) {
+			oEnd, tEnd = inOurs[n], inTheirs[n]
+		}
+
+		baseChunk := strings.Join(b[i:n], "")
+		oursChunk := strings.Join(o[j:oEnd], "")
+		theirsChunk := strings.Join(t[k:tEnd], "")
+		switch {
+		case oursChunk == theirsChunk, theirsChunk == baseChunk:
+			out.WriteString(oursChunk)
+		case oursChunk == baseChunk:
+			out.WriteString(theirsChunk)
+		default:
+			conflicts++
+			out.WriteString("<<<<<<< " + oursName + "\n")
+			out.WriteString(withNewline(oursChunk))
+			out.WriteString("=======\n")
+			out.WriteString(withNewline(theirsChunk))
+			out.WriteString(">>>>>>> " + theirsName + "\n")
+		}
+		i, j, k = n, oEnd, tEnd
+	}
+}
+
+// matches maps each line of a to its index in b in the edit script from a
+// to b, or -1 when it was deleted
+func matches(a, b []string) []int {
+	m := make([]int, len(a))
+	ia, ib := 0, 0
+	for _, l := range Lines(a, b) {
+		switch l.Kind {
+		case Equal:
+			m[ia] = ib
+			ia, ib = ia+1, ib+1
+		case Delete:
+			m[ia] = -1
+			ia++
+		case Insert:
+			ib++
+		}
+	}
+	return m
+}
+
+func withNewline(s string) string {
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		return s + "\n"
+	}
+	return s
+}
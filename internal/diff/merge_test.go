@@ -0,0 +1,50 @@
+package diff
+
+import "testing"
+
+func TestMerge3(t *testing.T) {
+	const base = "a\nb\nc\nd\ne\n"
+	tests := []struct {
+		name          string
+		ours, theirs  string
+		want          string
+		wantConflicts int
+	}{
+		{"nobody changed", base, base, base, 0},
+		{"only ours", "a\nB\nc\nd\ne\n", base, "a\nB\nc\nd\ne\n", 0},
+		{"only theirs", base, "a\nb\nc\nD\ne\n", "a\nb\nc\nD\ne\n", 0},
+		{"separate changes", "a\nB\nc\nd\ne\n", "a\nb\nc\nD\ne\n", "a\nB\nc\nD\ne\n", 0},
+		{"same change", "a\nX\nc\nd\ne\n", "a\nX\nc\nd\ne\n", "a\nX\nc\nd\ne\n", 0},
+		{"insert and delete", "start\na\nb\nc\nd\ne\n", "a\nb\nc\nd\n", "start\na\nb\nc\nd\n", 0},
+		{"both append", base + "ours\n", base + "theirs\n",
+			base + "<<<<<<< ours\nours\n=======\ntheirs\n>>>>>>> theirs\n", 1},
+		{"conflict", "a\nb\nOURS\nd\ne\n", "a\nb\nTHEIRS\nd\ne\n",
+			"a\nb\n<<<<<<< ours\nOURS\n=======\nTHEIRS\n>>>>>>> theirs\nd\ne\n", 1},
+		{"conflict and clean change", "A\nb\nOURS\nd\ne\n", "a\nb\nTHEIRS\nd\nE\n",
+			"A\nb\n<<<<<<< ours\nOURS\n=======\nTHEIRS\n>>>>>>> theirs\nd\nE\n", 1},
+		{"deleted by us, edited by them", "a\nb\nd\ne\n", "a\nb\nC\nd\ne\n",
+			"a\nb\n<<<<<<< ours\n=======\nC\n>>>>>>> theirs\nd\ne\n", 1},
+		{"missing final newline", "a\nb\nc\nd\nOURS", "a\nb\nc\nd\nTHEIRS",
+			"a\nb\nc\nd\n<<<<<<< ours\nOURS\n=======\nTHEIRS\n>>>>>>> theirs\n", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Merge3(base, tt.ours, tt.theirs, "ours", "theirs")
+			if got != tt.want || conflicts != tt.wantConflicts {
+				t.Errorf("Merge3() = %q, %d conflicts\nwant %q, %d conflicts", got, conflicts, tt.want, tt.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestMerge3EmptyBase(t *testing.T) {
+	got, conflicts := Merge3("", "x\n", "x\n", "ours", "theirs")
+	if got != "x\n" || conflicts != 0 {
+		t.Errorf("identical additions: %q, %d conflicts", got, conflicts)
+	}
+	got, conflicts = Merge3("", "", "new\n", "ours", "theirs")
+	if got != "new\n" || conflicts != 0 {
+		t.Errorf("added by them: %q, %d conflicts", got, conflicts)
+	}
+}
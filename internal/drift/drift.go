@@ -0,0 +1,279 @@
+// Package drift compares a generated project with what its template and
+// components generate today
+package drift
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/purnama/scaffold/internal/components"
+	"github.com/purnama/scaffold/internal/diff"
+	"github.com/purnama/scaffold/internal/generator"
+	"github.com/purnama/scaffold/internal/lockfile"
+	"github.com/purnama/scaffold/internal/render"
+	"github.com/purnama/scaffold/internal/templates"
+	"github.com/purnama/scaffold/internal/tui"
+)
+
+// Status is how a file on disk relates to the generated one
+type Status string
+
+const (
+	Unchanged Status = "unchanged"
+	Modified  Status = "user-modified"
+	Missing   Status = "missing"
+	Extra     Status = "extra" // On disk, in a generated directory, but not generated
+)
+
+// Statuses lists every status in display order
+var Statuses = []Status{Unchanged, Modified, Missing, Extra}
+
+// File is the comparison for one file
+type File struct {
+	Path      string // Slash-separated, relative to the project
+	Status    Status
+	Source    string // "template", "component:<name>", or "lockfile" when only the hash is known
+	Generated []byte // nil for extra files and files only known by hash
+	Current   []byte // nil when missing
+}
+
+// Diff returns the unified diff from the generated file to the one on disk,
+// or "" when there is nothing to show
+func (f File) Diff() string {
+	if f.Generated == nil || f.Status == Unchanged {
+		return ""
+	}
+	if render.IsBinary(f.Generated) || render.IsBinary(f.Current) {
+		return fmt.Sprintf("Binary files %s (generated) and %s (project) differ\n", f.Path, f.Path)
+	}
+	return diff.Unified(f.Path+" (generated)", f.Path+" (project)", string(f.Generated), string(f.Current), 3)
+}
+
+// Report is the result of Check
+type Report struct {
+	Lock            *lockfile.Lock
+	TemplateVersion string // Version of the template the project was compared with
+	LatestVersion   string // Current version of the template
+	Files           []File // Sorted by path
+}
+
+// Count returns the number of files with the given status
+func (r *Report) Count(s Status) int {
+	n := 0
+	for _, f := range r.Files {
+		if f.Status == s {
+			n++
+		}
+	}
+	return n
+}
+
+// Drifted reports whether any file differs from the generated project
+func (r *Report) Drifted() bool {
+	return r.Count(Unchanged) != len(r.Files)
+}
+
+// ProjectConfig rebuilds the init choices recorded in a lockfile for a
+// version of its template. Variables the version doesn't declare are
+// dropped, new ones get their default.
+func ProjectConfig(l *lockfile.Lock, tmpl templates.Template) tui.ProjectConfig {
+	license := l.License
+	if license == "" {
+		license = "None"
+	}
+	vars := map[string]string{}
+	for _, v := range tmpl.Variables {
+		if value, ok := l.Vars[v.Name]; ok {
+			vars[v.Name] = value
+		}
+	}
+	return tui.ProjectConfig{
+		ProjectName:   l.ProjectName,
+		TemplateName:  l.Template,
+		License:       license,
+		IncludeDocker: l.Docker,
+		Vars:          vars,
+		ModuleName:    l.Module,
+		Author:        l.Author,
+	}
+}
+
+// RecordedTemplate returns the template version a project was generated
+// from, or the current version when that one isn't available or wasn't
+// recorded
+func RecordedTemplate(l *lockfile.Lock) (templates.Template, error) {
+	if l.TemplateVersion != "" {
+		if tmpl, err := templates.GetTemplateVersion(l.Template, l.TemplateVersion); err == nil {
+			return tmpl, nil
+		}
+	}
+	return templates.GetTemplate(l.Template)
+}
+
+// generated is a file the template or a component produces
+type generated struct {
+	content []byte
+	source  string
+}
+
+// Check regenerates the project in dir from its lockfile, in memory, and
+// compares every file with the one on disk. The recorded template version
+// is used when it is still available. Component files replace
+// template files with the same path, in the order components were added.
+//
+// Templates that render now, year or uuid produce different content on
+// every run, so the files using them always report as user-modified.
+func Check(dir string) (*Report, error) {
+	lock, err := lockfile.Read(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s has no %s, only projects created by scaffold init can be compared", dir, lockfile.FileName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := RecordedTemplate(lock)
+	if err != nil {
+		return nil, err
+	}
+	out, err := generator.RenderTemplate(tmpl, ProjectConfig(lock, tmpl))
+	if err != nil {
+		return nil, fmt.Errorf("failed to regenerate %s: %w", lock.Template, err)
+	}
+
+	files := map[string]generated{}
+	for _, f := range out.Files {
+		files[f.Path] = generated{[]byte(f.Content), "template"}
+	}
+	if len(lock.Components) > 0 {
+		data, err := components.ProjectData(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range lock.Components {
+			rendered, err := components.RenderComponent(c.Name, data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to regenerate component %s: %w", c.Name, err)
+			}
+			for _, f := range rendered {
+				files[f.Path] = generated{[]byte(f.Content), "component:" + c.Name}
+			}
+		}
+	}
+
+	current, err := templates.GetTemplate(lock.Template)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Lock: lock, TemplateVersion: tmpl.Version, LatestVersion: current.Version}
+	for p, g := range files {
+		current, err := readFile(dir, p)
+		if err != nil {
+			return nil, err
+		}
+		f := File{Path: p, Source: g.source, Generated: g.content, Current: current}
+		switch {
+		case current == nil:
+			f.Status = Missing
+		case string(current) == string(g.content):
+			f.Status = Unchanged
+		default:
+			f.Status = Modified
+		}
+		report.Files = append(report.Files, f)
+	}
+
+	// Files such as go.mod aren't rendered, their hash tells whether they
+	// were edited
+	for p, hash := range lock.Files {
+		if _, ok := files[p]; ok {
+			continue
+		}
+		current, err := readFile(dir, p)
+		if err != nil {
+			return nil, err
+		}
+		f := File{Path: p, Source: "lockfile", Current: current}
+		switch {
+		case current == nil:
+			f.Status = Missing
+		case lockfile.Hash(current) == hash:
+			f.Status = Unchanged
+		default:
+			f.Status = Modified
+		}
+		report.Files = append(report.Files, f)
+	}
+
+	extra, err := extraFiles(dir, out.Directories, files, lock)
+	if err != nil {
+		return nil, err
+	}
+	report.Files = append(report.Files, extra...)
+
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].Path < report.Files[j].Path
+	})
+	return report, nil
+}
+
+// extraFiles lists the files directly inside generated directories that
+// are neither generated nor recorded in the lockfile
+func extraFiles(dir string, dirs []string, files map[string]generated, lock *lockfile.Lock) ([]File, error) {
+	seen := map[string]bool{".": true}
+	for _, d := range dirs {
+		seen[path.Clean(d)] = true
+	}
+	for p := range files {
+		seen[path.Dir(p)] = true
+	}
+
+	var extra []File
+	for d := range seen {
+		entries, err := os.ReadDir(filepath.Join(dir, filepath.FromSlash(d)))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			p := path.Join(d, e.Name())
+			if e.IsDir() || p == lockfile.FileName {
+				continue
+			}
+			if _, ok := files[p]; ok {
+				continue
+			}
+			if _, ok := lock.Files[p]; ok {
+				continue
+			}
+			current, err := readFile(dir, p)
+			if err != nil {
+				return nil, err
+			}
+			extra = append(extra, File{Path: p, Status: Extra, Current: current})
+		}
+	}
+	return extra, nil
+}
+
+// readFile returns the content of a project file, nil when it doesn't exist
+func readFile(dir, p string) ([]byte, error) {
+	content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(p)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if content == nil {
+		content = []byte{}
+	}
+	return content, nil
+}
@@ -0,0 +1,171 @@
+package drift
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/purnama/scaffold/internal/components"
+	"github.com/purnama/scaffold/internal/generator"
+	"github.com/purnama/scaffold/internal/lockfile"
+	"github.com/purnama/scaffold/internal/templates"
+	"github.com/purnama/scaffold/internal/tui"
+)
+
+// generate creates a go-api project with a custom port and returns its directory
+func generate(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	config := tui.ProjectConfig{
+		ProjectName:  "billing",
+		TemplateName: "go-api",
+		License:      "MIT",
+		OutputDir:    tmpDir,
+		Vars:         map[string]string{"port": "9090"},
+	}
+	if err := generator.GenerateWithOptions(config, generator.Options{}); err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+	return filepath.Join(tmpDir, "billing")
+}
+
+func statuses(r *Report) map[string]Status {
+	m := map[string]Status{}
+	for _, f := range r.Files {
+		m[f.Path] = f.Status
+	}
+	return m
+}
+
+func TestCheckFreshProject(t *testing.T) {
+	dir := generate(t)
+
+	report, err := Check(dir)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if report.Drifted() {
+		for _, f := range report.Files {
+			if f.Status != Unchanged {
+				t.Errorf("%s is %s\n%s", f.Path, f.Status, f.Diff())
+			}
+		}
+	}
+	got := statuses(report)
+	for _, p := range []string{"pkg/config/config.go", "LICENSE", "go.mod"} {
+		if got[p] != Unchanged {
+			t.Errorf("%s status = %q, want unchanged", p, got[p])
+		}
+	}
+}
+
+func TestCheckDrift(t *testing.T) {
+	dir := generate(t)
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("# billing\n"), 0644)
+	os.Remove(filepath.Join(dir, "LICENSE"))
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("todo\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "internal", "handler", "extra.go"), []byte("package handler\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, "docs", "deep"), 0755)
+	os.WriteFile(filepath.Join(dir, "docs", "deep", "ignored.md"), []byte("outside generated dirs\n"), 0644)
+	f, _ := os.OpenFile(filepath.Join(dir, "go.mod"), os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("\nrequire example.com/x v1.0.0\n")
+	f.Close()
+
+	report, err := Check(dir)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	want := map[string]Status{
+		"README.md":                 Modified,
+		"LICENSE":                   Missing,
+		"notes.txt":                 Extra,
+		"internal/handler/extra.go": Extra,
+		"go.mod":                    Modified,
+		"pkg/config/config.go":      Unchanged,
+		"docs/deep/ignored.md":      "",
+		".scaffold.json":            "",
+	}
+	got := statuses(report)
+	for p, s := range want {
+		if got[p] != s {
+			t.Errorf("%s status = %q, want %q", p, got[p], s)
+		}
+	}
+	if !report.Drifted() || report.Count(Extra) != 2 {
+		t.Errorf("Drifted() = %v, Count(Extra) = %d", report.Drifted(), report.Count(Extra))
+	}
+
+	for _, f := range report.Files {
+		switch f.Path {
+		case "README.md":
+			if d := f.Diff(); !strings.Contains(d, "+++ README.md (project)") || !strings.Contains(d, "-## License") {
+				t.Errorf("README.md diff:\n%s", d)
+			}
+		case "go.mod":
+			if f.Source != "lockfile" || f.Diff() != "" {
+				t.Errorf("go.mod should only be compared by hash, source %q", f.Source)
+			}
+		}
+	}
+}
+
+func TestCheckComponents(t *testing.T) {
+	dir := generate(t)
+	if err := components.AddComponent(dir, "github-actions", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := components.AddComponentWithOptions(dir, "gitignore", components.Options{Conflict: "overwrite"}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Check(dir)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	for _, f := range report.Files {
+		if f.Status != Unchanged {
+			t.Errorf("%s is %s\n%s", f.Path, f.Status, f.Diff())
+		}
+		if f.Path == ".gitignore" && f.Source != "component:gitignore" {
+			t.Errorf(".gitignore source = %q, the component replaced the template's", f.Source)
+		}
+	}
+}
+
+func TestCheckWithoutLockfile(t *testing.T) {
+	_, err := Check(t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), ".scaffold.json") {
+		t.Errorf("Check() error = %v, want a missing .scaffold.json error", err)
+	}
+}
+
+func TestCheckUsesRecordedVersion(t *testing.T) {
+	dir := generate(t)
+	old, err := templates.GetTemplateVersion("go-api", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock, _ := lockfile.Read(dir)
+	out, err := generator.RenderTemplate(old, ProjectConfig(lock, old))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range out.Files {
+		os.WriteFile(filepath.Join(dir, f.Path), []byte(f.Content), 0644)
+	}
+	lock.TemplateVersion = "1.0.0"
+	lockfile.Write(dir, lock)
+
+	report, err := Check(dir)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	current, _ := templates.GetTemplate("go-api")
+	if report.TemplateVersion != "1.0.0" || report.LatestVersion != current.Version {
+		t.Errorf("versions = %s, %s, want 1.0.0, %s", report.TemplateVersion, report.LatestVersion, current.Version)
+	}
+	if statuses(report)["Dockerfile"] != Unchanged {
+		t.Error("Dockerfile should match version 1.0.0")
+	}
+}
@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+
+	"github.com/purnama/scaffold/internal/conflict"
+	"github.com/purnama/scaffold/internal/hooks"
+)
+
+// EventKind is what an Event reports
+type EventKind string
+
+const (
+	ProjectStarted   EventKind = "project_started"   // Project, Dir and Template
+	StepStarted      EventKind = "step_started"      // Step, and details such as the conflict mode in Message
+	DirCreated       EventKind = "dir_created"       // Path
+	FileWritten      EventKind = "file_written"      // Path and Mode, details such as the license in Message
+	FileResolved     EventKind = "file_resolved"     // Path of an existing file, Action, and Backup or Reason
+	DependencyAdded  EventKind = "dependency_added"  // Module and Version required in go.mod
+	CommandRun       EventKind = "command_run"       // Command that succeeded, such as go mod tidy
+	HookStarted      EventKind = "hook_started"      // Hook and Stage, for a dry run with a note in Message
+	HookOutput       EventKind = "hook_output"       // Hook and a line it printed in Message
+	HookFinished     EventKind = "hook_finished"     // Hook, and Err when it failed
+	HookSkipped      EventKind = "hook_skipped"      // Hook and Reason
+	ComponentStarted EventKind = "component_started" // Component and its description in Message
+	Info             EventKind = "info"              // Message
+	Debug            EventKind = "debug"             // Message with details only worth showing on request
+	Warning          EventKind = "warning"           // Message about something that failed without stopping generation
+	ProjectCreated   EventKind = "project_created"   // Project, Dir and NextSteps
+)
+
+// Step is a stage of generation, announced by a StepStarted event
+type Step string
+
+const (
+	StepDirectories  Step = "directories"
+	StepFiles        Step = "files"
+	StepGoMod        Step = "go_mod"
+	StepDockerfile   Step = "dockerfile"
+	StepLicense      Step = "license"
+	StepPreInit      Step = "pre_init"
+	StepConflicts    Step = "conflicts"
+	StepLockfile     Step = "lockfile"
+	StepGit          Step = "git"
+	StepPostInit     Step = "post_init"
+	StepProfileHooks Step = "profile_hooks" // Hooks of the config profile, run by the caller after generation
+)
+
+// Event is a step of progress while a project is generated or changed. Only
+// the fields its Kind describes are set.
+type Event struct {
+	Kind      EventKind       `json:"event"`
+	DryRun    bool            `json:"dry_run,omitempty"` // Nothing is written, the event previews what would be
+	Project   string          `json:"project,omitempty"`
+	Dir       string          `json:"dir,omitempty"`
+	Template  string          `json:"template,omitempty"`
+	Component string          `json:"component,omitempty"`
+	Step      Step            `json:"step,omitempty"`
+	Path      string          `json:"path,omitempty"` // Slash-separated, relative to the project
+	Mode      fs.FileMode     `json:"-"`              // Written as an octal string such as "0755"
+	Action    conflict.Action `json:"action,omitempty"`
+	Backup    string          `json:"backup,omitempty"`
+	Reason    string          `json:"reason,omitempty"`
+	Module    string          `json:"module,omitempty"`
+	Version   string          `json:"version,omitempty"`
+	Command   string          `json:"command,omitempty"`
+	Hook      string          `json:"hook,omitempty"`
+	Stage     string          `json:"stage,omitempty"` // Hook stage: pre-init, post-init or profile
+	Message   string          `json:"message,omitempty"`
+	Err       error           `json:"-"` // Written as its message
+	NextSteps []string        `json:"next_steps,omitempty"`
+}
+
+// MarshalJSON writes the mode in octal and the error as its message
+func (e Event) MarshalJSON() ([]byte, error) {
+	type event Event
+	out := struct {
+		event
+		Mode  string `json:"mode,omitempty"`
+		Error string `json:"error,omitempty"`
+	}{event: event(e)}
+	if e.Mode != 0 {
+		out.Mode = fmt.Sprintf("%04o", e.Mode.Perm())
+	}
+	if e.Err != nil {
+		out.Error = e.Err.Error()
+	}
+	return json.Marshal(out)
+}
+
+// Reporter receives the events of generation. The generator doesn't print
+// anything itself, showing progress is up to the reporter.
+type Reporter interface {
+	Report(Event)
+}
+
+// ReporterFunc adapts a function to a Reporter
+type ReporterFunc func(Event)
+
+// Report calls f(e)
+func (f ReporterFunc) Report(e Event) { f(e) }
+
+// HookProgress turns the progress of hooks run at stage into events for r,
+// for a hooks.Runner
+func HookProgress(stage string, r Reporter) func(hooks.Update) {
+	return func(u hooks.Update) {
+		if r == nil {
+			return
+		}
+		e := Event{Hook: u.Hook.String(), Stage: stage}
+		switch u.Status {
+		case hooks.Started:
+			e.Kind = HookStarted
+		case hooks.Output:
+			e.Kind, e.Message = HookOutput, u.Line
+		case hooks.Finished:
+			e.Kind, e.Err = HookFinished, u.Err
+		case hooks.Skipped:
+			e.Kind, e.Reason = HookSkipped, u.Reason
+		}
+		r.Report(e)
+	}
+}
@@ -0,0 +1,207 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/purnama/scaffold/internal/conflict"
+	"github.com/purnama/scaffold/internal/hooks"
+	"github.com/purnama/scaffold/internal/templates"
+	"github.com/purnama/scaffold/internal/tui"
+)
+
+// recorder collects the events it is reported
+type recorder struct{ events []Event }
+
+func (r *recorder) Report(e Event) { r.events = append(r.events, e) }
+
+// find returns the first event of kind for path, if any
+func (r *recorder) find(kind EventKind, path string) (Event, bool) {
+	for _, e := range r.events {
+		if e.Kind == kind && e.Path == path {
+			return e, true
+		}
+	}
+	return Event{}, false
+}
+
+// captureStdout returns what fn prints to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan []byte)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- b
+	}()
+	fn()
+	w.Close()
+	return string(<-out)
+}
+
+func TestGenerateReportsEvents(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := tui.ProjectConfig{ProjectName: "tool", TemplateName: "go-cli", License: "MIT", OutputDir: tmpDir}
+
+	for _, dryRun := range []bool{false, true} {
+		rec := &recorder{}
+		var err error
+		printed := captureStdout(t, func() {
+			err = GenerateWithOptions(config, Options{DryRun: dryRun, Reporter: rec, Conflict: conflict.Overwrite})
+		})
+		if err != nil {
+			t.Fatalf("GenerateWithOptions(dry run %v) failed: %v", dryRun, err)
+		}
+		if printed != "" {
+			t.Errorf("dry run %v printed %q, want everything reported", dryRun, printed)
+		}
+
+		if len(rec.events) < 2 || rec.events[0].Kind != ProjectStarted || rec.events[len(rec.events)-1].Kind != ProjectCreated {
+			t.Fatalf("dry run %v: events should start and end with the project, got %v", dryRun, rec.events)
+		}
+		if got := rec.events[0]; got.Project != "tool" || got.Template != "go-cli" {
+			t.Errorf("dry run %v: %s = %+v", dryRun, got.Kind, got)
+		}
+		for _, e := range rec.events {
+			if e.DryRun != dryRun {
+				t.Errorf("dry run %v: %s event has DryRun %v", dryRun, e.Kind, e.DryRun)
+			}
+		}
+		for _, path := range []string{"main.go", "go.mod", "LICENSE", ".scaffold.json"} {
+			e, ok := rec.find(FileWritten, path)
+			if !ok {
+				t.Errorf("dry run %v: no %s event for %s", dryRun, FileWritten, path)
+			} else if e.Mode != 0644 {
+				t.Errorf("dry run %v: %s mode = %o, want 0644", dryRun, path, e.Mode)
+			}
+		}
+		if e, _ := rec.find(FileWritten, "LICENSE"); e.Message != "MIT" {
+			t.Errorf("dry run %v: LICENSE message = %q, want the license", dryRun, e.Message)
+		}
+	}
+}
+
+func TestGenerateReportsNextSteps(t *testing.T) {
+	rec := &recorder{}
+	config := tui.ProjectConfig{ProjectName: "tool", TemplateName: "go-cli", License: "None", OutputDir: t.TempDir()}
+	if err := GenerateWithOptions(config, Options{Reporter: rec}); err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+
+	tmpl, _ := templates.GetTemplate("go-cli")
+	last := rec.events[len(rec.events)-1]
+	if last.Kind != ProjectCreated || !slices.Equal(last.NextSteps, tmpl.NextSteps) {
+		t.Errorf("last event = %+v, want the project created with the template's next steps", last)
+	}
+	if want := filepath.Join(config.OutputDir, "tool"); last.Dir != want {
+		t.Errorf("Dir = %q, want %q", last.Dir, want)
+	}
+	if _, ok := rec.find(FileResolved, "main.go"); ok {
+		t.Error("a new directory has no conflicts to report")
+	}
+}
+
+func TestGenerateReportsConflicts(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := tui.ProjectConfig{ProjectName: "tool", TemplateName: "go-cli", License: "None", OutputDir: tmpDir}
+	if err := GenerateWithOptions(config, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(tmpDir, "tool", "README.md"), []byte("my notes\n"), 0644)
+
+	rec := &recorder{}
+	if err := GenerateWithOptions(config, Options{Conflict: conflict.Backup, Reporter: rec}); err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+	if e, ok := rec.find(FileResolved, "README.md"); !ok || e.Backup != "README.md.orig" {
+		t.Errorf("README.md resolution = %+v, want a backup", e)
+	}
+	if e, ok := rec.find(FileResolved, "main.go"); !ok || e.Action != conflict.Unchanged {
+		t.Errorf("main.go resolution = %+v, want it unchanged", e)
+	}
+}
+
+func TestGenerateReportsHooks(t *testing.T) {
+	loadHookTemplate(t)
+
+	tests := []struct {
+		name  string
+		trust Truster
+		want  []EventKind
+	}{
+		{"trusted", trustAll, []EventKind{HookStarted, HookOutput, HookFinished}},
+		{"not trusted", nil, []EventKind{Warning}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			config := tui.ProjectConfig{ProjectName: "app", TemplateName: "hooked", OutputDir: t.TempDir()}
+			opts := Options{
+				Trust:     tt.trust,
+				Reporter:  rec,
+				PostHooks: []hooks.Hook{{Name: "hello", Run: "echo hello"}},
+			}
+			if err := GenerateWithOptions(config, opts); err != nil {
+				t.Fatalf("GenerateWithOptions failed: %v", err)
+			}
+
+			var got []EventKind
+			for _, e := range rec.events {
+				switch e.Kind {
+				case HookStarted, HookOutput, HookFinished, HookSkipped, Warning:
+					if e.Hook == "hello" || e.Kind == Warning {
+						got = append(got, e.Kind)
+					}
+					if e.Hook == "hello" && e.Stage != "post-init" {
+						t.Errorf("%s stage = %q, want post-init", e.Kind, e.Stage)
+					}
+					if e.Kind == HookOutput && e.Message != "hello" {
+						t.Errorf("hook output = %q", e.Message)
+					}
+				}
+			}
+			want := tt.want
+			if tt.trust == nil {
+				// The hook from the options still runs
+				want = append(want, HookStarted, HookOutput, HookFinished)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("hook events = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestEventJSON(t *testing.T) {
+	tests := []struct {
+		event Event
+		want  string
+	}{
+		{Event{Kind: FileWritten, Path: "scripts/setup.sh", Mode: 0755}, `{"event":"file_written","path":"scripts/setup.sh","mode":"0755"}`},
+		{Event{Kind: HookFinished, Hook: "lint", Stage: "post-init", Err: errors.New("exit status 1")}, `{"event":"hook_finished","hook":"lint","stage":"post-init","error":"exit status 1"}`},
+		{Event{Kind: ProjectCreated, DryRun: true, Project: "app", Dir: "app", NextSteps: []string{"go test ./..."}}, `{"event":"project_created","dry_run":true,"project":"app","dir":"app","next_steps":["go test ./..."]}`},
+	}
+
+	for _, tt := range tests {
+		got, err := json.Marshal(tt.event)
+		if err != nil {
+			t.Fatalf("Marshal(%+v) failed: %v", tt.event, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("Marshal(%+v) =\n%s\nwant\n%s", tt.event, got, tt.want)
+		}
+	}
+}
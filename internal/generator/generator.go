@@ -1,22 +1,55 @@
 package generator
 
 import (
-	"bytes"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
-	"text/template"
-
+	"time"
+
+	"github.com/purnama/scaffold/internal/conflict"
+	"github.com/purnama/scaffold/internal/hooks"
+	"github.com/purnama/scaffold/internal/lockfile"
+	"github.com/purnama/scaffold/internal/project"
+	"github.com/purnama/scaffold/internal/render"
+	"github.com/purnama/scaffold/internal/safefs"
 	"github.com/purnama/scaffold/internal/templates"
 	"github.com/purnama/scaffold/internal/tui"
 )
 
 // Options holds generator options
 type Options struct {
-	DryRun bool
-	Force  bool
+	DryRun   bool
+	Force    bool           // Deprecated: same as Conflict: conflict.Overwrite
+	Conflict conflict.Mode  // What to do with files in an existing project directory
+	Ask      conflict.Asker // Asks per file when Conflict is conflict.Prompt
+	Tidy     bool           // Run go mod tidy after writing go.mod, which needs the module cache or a proxy
+
+	NoHooks   bool         // Run no hooks at all
+	PreHooks  []hooks.Hook // Run after the template's pre-init hooks, e.g. from the config
+	PostHooks []hooks.Hook // Run after the template's post-init hooks
+	Trust     Truster      // Asked before running hooks of custom templates, which are skipped without it
+
+	FollowSymlinks bool // Write through symbolic links in an existing project directory that stay inside it
+
+	Reporter Reporter // Receives progress, nothing is shown without it
+}
+
+// report sends e to the reporter, if there is one
+func (o Options) report(e Event) {
+	if o.Reporter != nil {
+		o.Reporter.Report(e)
+	}
+}
+
+// conflictMode returns the effective conflict mode, honoring the old Force
+func (o Options) conflictMode() conflict.Mode {
+	if o.Conflict == conflict.Fail && o.Force {
+		return conflict.Overwrite
+	}
+	return o.Conflict
 }
 
 // Generate creates the project structure (backward compatibility)
@@ -26,332 +59,456 @@ func Generate(config tui.ProjectConfig) error {
 
 // GenerateWithOptions creates the project structure with options
 func GenerateWithOptions(config tui.ProjectConfig, opts Options) error {
+	if err := safefs.ValidateName(config.ProjectName); err != nil {
+		return fmt.Errorf("invalid project name %q: %w", config.ProjectName, err)
+	}
+	if config.ModuleName != "" {
+		if err := project.CheckModulePath(config.ModuleName); err != nil {
+			return fmt.Errorf("invalid module path %q: %w", config.ModuleName, err)
+		}
+	}
 	if opts.DryRun {
-		return previewProject(config)
+		return previewProject(config, opts)
 	}
 
-	fmt.Printf("\n🚀 Creating project: %s\n", config.ProjectName)
-
 	// Get the template
 	tmpl, err := templates.GetTemplate(config.TemplateName)
 	if err != nil {
 		return err
 	}
+	displayDir := filepath.Join(config.OutputDir, config.ProjectName)
+	opts.report(Event{Kind: ProjectStarted, Project: config.ProjectName, Dir: displayDir, Template: tmpl.Name})
 
-	// Get current directory
-	cwd, err := os.Getwd()
+	// Template data for file content substitution
+	tmpl, data, err := newTemplateData(tmpl, config)
 	if err != nil {
 		return err
 	}
 
-	// Create project directory
-	projectDir := filepath.Join(cwd, config.ProjectName)
+	// Ask about hooks before anything is written
+	preHooks, postHooks, err := projectHooks(tmpl, opts)
+	if err != nil {
+		return err
+	}
 
-	// Check if directory exists
-	if _, err := os.Stat(projectDir); err == nil {
-		if !opts.Force {
-			return fmt.Errorf("directory '%s' already exists. Use --force to overwrite", config.ProjectName)
+	// Check the project directory
+	baseDir := config.OutputDir
+	if baseDir == "" {
+		baseDir, err = os.Getwd()
+		if err != nil {
+			return err
 		}
-		fmt.Println("⚠️  Directory exists, overwriting...")
 	}
+	projectDir := filepath.Join(baseDir, config.ProjectName)
 
-	if err := os.MkdirAll(projectDir, 0755); err != nil {
-		return fmt.Errorf("failed to create project directory: %w", err)
+	_, statErr := os.Stat(projectDir)
+	exists := statErr == nil
+	mode := opts.conflictMode()
+	if exists && mode == conflict.Fail {
+		return fmt.Errorf("directory '%s' already exists. Use --conflict=%s to update it", config.ProjectName, conflict.ModeNames())
 	}
-	fmt.Printf("📁 Created project directory: %s/\n", config.ProjectName)
 
-	// Template data for file content substitution
-	data := TemplateData{
-		ProjectName: config.ProjectName,
-		PackageName: sanitizePackageName(config.ProjectName),
-		ModuleName:  fmt.Sprintf("github.com/user/%s", config.ProjectName),
-		Description: fmt.Sprintf("%s - Generated by scaffold", config.ProjectName),
-		License:     config.License,
+	// Render every path and file before touching the disk
+	dirs, err := renderDirectories(tmpl, data)
+	if err != nil {
+		return err
+	}
+	files, err := renderFiles(tmpl, data)
+	if err != nil {
+		return err
 	}
 
+	// Build the project in a staging directory and move it into place only
+	// when everything succeeded
+	st, err := newStage(projectDir, opts.FollowSymlinks)
+	if err != nil {
+		return err
+	}
+	opts.report(Event{Kind: Debug, Message: "staging the project in " + st.dir})
+	committed := false
+	defer func() {
+		if !committed {
+			st.discard()
+		}
+	}()
+
 	// Create directories inside project directory
-	fmt.Println("📁 Creating directories...")
-	for _, dir := range tmpl.Directories {
-		dirPath := filepath.Join(projectDir, dir)
-		if err := os.MkdirAll(dirPath, 0755); err != nil {
+	opts.report(Event{Kind: StepStarted, Step: StepDirectories})
+	for _, dir := range dirs {
+		if err := st.mkdir(dir); err != nil {
 			return fmt.Errorf("failed to create directory %s: %w", dir, err)
 		}
-		fmt.Printf("   ✓ %s/\n", dir)
+		opts.report(Event{Kind: DirCreated, Path: dir})
 	}
 
 	// Create files inside project directory
-	fmt.Println("📄 Creating files...")
-	for _, f := range tmpl.Files {
-		filePath := processPath(f.Path, data)
-		fullPath := filepath.Join(projectDir, filePath)
-
-		// Ensure parent directory exists
-		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
-			return fmt.Errorf("failed to create parent dir for %s: %w", filePath, err)
-		}
-
-		// Process template content
-		content, err := processTemplate(f.Content, data)
-		if err != nil {
-			return fmt.Errorf("failed to process template for %s: %w", filePath, err)
+	opts.report(Event{Kind: StepStarted, Step: StepFiles})
+	for _, f := range files {
+		if err := st.writeFile(f.Path, []byte(f.Content), f.Mode); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", f.Path, err)
 		}
-
-		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
-			return fmt.Errorf("failed to write file %s: %w", filePath, err)
-		}
-		fmt.Printf("   ✓ %s\n", filePath)
+		opts.report(Event{Kind: FileWritten, Path: f.Path, Mode: f.Mode})
 	}
 
-	// Initialize go.mod inside project directory (skip for fullstack frontend and learn-frontend)
-	if config.TemplateName != "fullstack" && config.TemplateName != "learn-frontend" {
-		fmt.Println("📦 Initializing Go module...")
-		cmd := exec.Command("go", "mod", "init", data.ModuleName)
-		cmd.Dir = projectDir
-		if output, err := cmd.CombinedOutput(); err != nil {
-			fmt.Printf("   ⚠ go mod init: %s\n", string(output))
-		} else {
-			fmt.Printf("   ✓ go.mod\n")
-		}
+	// Initialize go.mod inside project directory
+	tidied := false
+	if !tmpl.SkipGoMod {
+		opts.report(Event{Kind: StepStarted, Step: StepGoMod})
+		tidied = initGoMod(st.dir, data.ModuleName, tmpl.Dependencies, opts.Tidy, opts.report)
 	}
 
 	// Add Dockerfile if requested
 	if config.IncludeDocker {
-		fmt.Println("🐳 Adding Dockerfile...")
-		dockerContent := generateDockerfile(config.TemplateName, data)
-		dockerPath := filepath.Join(projectDir, "Dockerfile")
-		if err := os.WriteFile(dockerPath, []byte(dockerContent), 0644); err != nil {
+		opts.report(Event{Kind: StepStarted, Step: StepDockerfile})
+		dockerContent := generateDockerfile(tmpl.DockerfileProfile, data)
+		if err := st.writeFile("Dockerfile", []byte(dockerContent), 0644); err != nil {
 			return fmt.Errorf("failed to write Dockerfile: %w", err)
 		}
-		fmt.Printf("   ✓ Dockerfile\n")
+		opts.report(Event{Kind: FileWritten, Path: "Dockerfile", Mode: 0644})
 	}
 
 	// Add license if specified
 	if config.License != "None" && config.License != "" {
-		fmt.Println("📜 Adding license...")
-		licenseContent := generateLicense(config.License, data.ProjectName)
-		licensePath := filepath.Join(projectDir, "LICENSE")
-		if err := os.WriteFile(licensePath, []byte(licenseContent), 0644); err != nil {
+		opts.report(Event{Kind: StepStarted, Step: StepLicense})
+		licenseContent := generateLicense(config.License, copyrightHolder(data))
+		if err := st.writeFile("LICENSE", []byte(licenseContent), 0644); err != nil {
 			return fmt.Errorf("failed to write LICENSE: %w", err)
 		}
-		fmt.Printf("   ✓ LICENSE (%s)\n", config.License)
+		opts.report(Event{Kind: FileWritten, Path: "LICENSE", Mode: 0644, Message: config.License})
+	}
+
+	// Pre-init hooks see the staged project; a failure leaves nothing behind
+	if err := runHooks(StepPreInit, st.dir, preHooks, config, data, opts); err != nil {
+		return err
+	}
+
+	// Hash what was generated before conflicts change anything
+	lock, err := newLock(tmpl, config, data, st.dir)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		opts.report(Event{Kind: StepStarted, Step: StepConflicts, Message: string(mode)})
+		resolved, err := st.resolve(mode, opts.Ask)
+		if err != nil {
+			return err
+		}
+		for _, r := range resolved {
+			opts.report(Event{Kind: FileResolved, Path: r.Path, Action: r.Action, Backup: r.Backup, Reason: r.Reason})
+		}
+	}
+	opts.report(Event{Kind: StepStarted, Step: StepLockfile})
+	if err := lockfile.Write(st.dir, lock); err != nil {
+		return fmt.Errorf("failed to write %s: %w", lockfile.FileName, err)
 	}
+	opts.report(Event{Kind: FileWritten, Path: lockfile.FileName, Mode: 0644})
+
+	if err := st.commit(); err != nil {
+		return err
+	}
+	committed = true
+	opts.report(Event{Kind: Debug, Message: "moved the project into " + projectDir})
 
 	// Initialize git if requested
 	if config.InitGit {
-		fmt.Println("🔧 Initializing git repository...")
+		opts.report(Event{Kind: StepStarted, Step: StepGit})
 		cmd := exec.Command("git", "init")
 		cmd.Dir = projectDir
 		if output, err := cmd.CombinedOutput(); err != nil {
-			fmt.Printf("   ⚠ git init: %s\n", string(output))
+			opts.report(Event{Kind: Warning, Message: "git init: " + strings.TrimSpace(string(output))})
 		} else {
-			fmt.Printf("   ✓ .git/\n")
+			opts.report(Event{Kind: DirCreated, Path: ".git"})
 		}
 	}
 
 	// Run post-init hooks
-	runPostInitHooks(projectDir, config.TemplateName)
-
-	fmt.Printf("\n✅ Project '%s' created successfully!\n", config.ProjectName)
-	printNextSteps(config)
+	if err := runHooks(StepPostInit, projectDir, postHooks, config, data, opts); err != nil {
+		return fmt.Errorf("project '%s' was created but %w", config.ProjectName, err)
+	}
 
+	opts.report(Event{Kind: ProjectCreated, Project: config.ProjectName, Dir: displayDir, NextSteps: nextSteps(tmpl, tidied)})
 	return nil
 }
 
+// Output is a project rendered in memory
+type Output struct {
+	Directories []string
+	Files       []File // Template files, then the Dockerfile and LICENSE when asked for
+}
+
+// Render generates the project for config in memory, without go.mod, git
+// or hooks, which need the project on disk
+func Render(config tui.ProjectConfig) (*Output, error) {
+	tmpl, err := templates.GetTemplate(config.TemplateName)
+	if err != nil {
+		return nil, err
+	}
+	return RenderTemplate(tmpl, config)
+}
+
+// RenderTemplate is Render with a given template, such as an earlier
+// version from templates.GetTemplateVersion
+func RenderTemplate(tmpl templates.Template, config tui.ProjectConfig) (*Output, error) {
+	tmpl, data, err := newTemplateData(tmpl, config)
+	if err != nil {
+		return nil, err
+	}
+	files, err := renderFiles(tmpl, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.IncludeDocker {
+		files = append(files, File{Path: "Dockerfile", Content: generateDockerfile(tmpl.DockerfileProfile, data), Mode: 0644})
+	}
+	if config.License != "None" && config.License != "" {
+		files = append(files, File{Path: "LICENSE", Content: generateLicense(config.License, copyrightHolder(data)), Mode: 0644})
+	}
+
+	dirs, err := renderDirectories(tmpl, data)
+	if err != nil {
+		return nil, err
+	}
+	return &Output{Directories: dirs, Files: files}, nil
+}
+
 // previewProject shows what would be created without actually creating
-func previewProject(config tui.ProjectConfig) error {
+func previewProject(config tui.ProjectConfig, opts Options) error {
 	tmpl, err := templates.GetTemplate(config.TemplateName)
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("\n🔍 DRY RUN - Preview of what will be created:")
-	fmt.Printf("\n📦 Project: %s/\n", config.ProjectName)
+	// Render like a real run would, so template errors show up here too
+	tmpl, data, err := newTemplateData(tmpl, config)
+	if err != nil {
+		return err
+	}
+	dirs, err := renderDirectories(tmpl, data)
+	if err != nil {
+		return err
+	}
+	files, err := renderFiles(tmpl, data)
+	if err != nil {
+		return err
+	}
+
+	// Every event of a dry run is a preview
+	report := func(e Event) {
+		e.DryRun = true
+		opts.report(e)
+	}
+	displayDir := filepath.Join(config.OutputDir, config.ProjectName)
+	report(Event{Kind: ProjectStarted, Project: config.ProjectName, Dir: displayDir, Template: tmpl.Name})
 
-	for _, dir := range tmpl.Directories {
-		fmt.Printf("   📁 %s/\n", dir)
+	for _, dir := range dirs {
+		report(Event{Kind: DirCreated, Path: dir})
 	}
-	for _, f := range tmpl.Files {
-		fmt.Printf("   📄 %s\n", f.Path)
+	for _, f := range files {
+		report(Event{Kind: FileWritten, Path: f.Path, Mode: f.Mode})
 	}
 
-	if config.TemplateName != "fullstack" && config.TemplateName != "learn-frontend" {
-		fmt.Println("\n   📄 go.mod")
+	if !tmpl.SkipGoMod {
+		report(Event{Kind: FileWritten, Path: "go.mod", Mode: 0644})
+		for _, d := range tmpl.Dependencies {
+			report(Event{Kind: DependencyAdded, Module: d.Path, Version: d.Version})
+		}
+		if opts.Tidy {
+			report(Event{Kind: CommandRun, Command: "go mod tidy"})
+		}
 	}
 	if config.IncludeDocker {
-		fmt.Println("   🐳 Dockerfile")
+		report(Event{Kind: FileWritten, Path: "Dockerfile", Mode: 0644})
 	}
 	if config.License != "None" && config.License != "" {
-		fmt.Printf("   📜 LICENSE (%s)\n", config.License)
+		report(Event{Kind: FileWritten, Path: "LICENSE", Mode: 0644, Message: config.License})
 	}
+	report(Event{Kind: FileWritten, Path: lockfile.FileName, Mode: 0644})
 	if config.InitGit {
-		fmt.Println("   🔧 .git/")
+		report(Event{Kind: DirCreated, Path: ".git"})
+	}
+
+	// Hooks are listed without asking whether to trust them
+	if !opts.NoHooks {
+		note := ""
+		if tmpl.Source != templates.SourceBuiltIn {
+			note = "custom template, asks first"
+		}
+		listHooks := func(stage string, template, extra []hooks.Hook) {
+			for _, h := range template {
+				report(Event{Kind: HookStarted, Hook: h.String(), Stage: stage, Message: note})
+			}
+			for _, h := range extra {
+				report(Event{Kind: HookStarted, Hook: h.String(), Stage: stage})
+			}
+		}
+		listHooks(hookStages[StepPreInit], tmpl.PreInit, opts.PreHooks)
+		listHooks(hookStages[StepPostInit], tmpl.PostInit, opts.PostHooks)
 	}
 
-	fmt.Println("\n💡 Remove --dry-run to create the project")
+	report(Event{Kind: ProjectCreated, Project: config.ProjectName, Dir: displayDir})
 	return nil
 }
 
-// runPostInitHooks runs template-specific post-initialization commands
-func runPostInitHooks(projectDir, templateName string) {
-	switch templateName {
-	case "fullstack":
-		// Check if bun is available
-		if _, err := exec.LookPath("bun"); err == nil {
-			fmt.Println("📦 Running post-init hooks...")
-			frontendDir := filepath.Join(projectDir, "frontend")
-			cmd := exec.Command("bun", "install")
-			cmd.Dir = frontendDir
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			if err := cmd.Run(); err != nil {
-				fmt.Printf("   ⚠ bun install failed: %v\n", err)
-			} else {
-				fmt.Println("   ✓ Frontend dependencies installed")
-			}
+// defaultNextSteps are shown for templates that don't declare any
+var defaultNextSteps = []string{"go mod tidy", "go test ./..."}
+
+// nextSteps returns the template's next steps, leaving out go mod tidy when
+// init already ran it
+func nextSteps(tmpl templates.Template, tidied bool) []string {
+	steps := tmpl.NextSteps
+	if len(steps) == 0 {
+		steps = defaultNextSteps
+	}
+	var out []string
+	for _, step := range steps {
+		if tidied && step == "go mod tidy" {
+			continue
 		}
+		out = append(out, step)
 	}
+	return out
 }
 
-func printNextSteps(config tui.ProjectConfig) {
-	fmt.Println("\nNext steps:")
-	fmt.Printf("   cd %s\n", config.ProjectName)
-
-	switch config.TemplateName {
-	case "fullstack":
-		fmt.Println("   make dev    # Runs backend + frontend")
-	case "go-api":
-		fmt.Println("   go mod tidy")
-		fmt.Println("   go run ./cmd/api")
-	case "go-cli":
-		fmt.Println("   go mod tidy")
-		fmt.Println("   go run .")
-	case "go-grpc":
-		fmt.Println("   go mod tidy")
-		fmt.Println("   make run-server")
-	case "go-worker":
-		fmt.Println("   go mod tidy")
-		fmt.Println("   go run ./cmd/worker")
-	case "go-tui":
-		fmt.Println("   go mod tidy")
-		fmt.Println("   go run .")
-	case "learn-concurrency":
-		fmt.Println("   cd 01-goroutines && go run main.go")
-	case "learn-testing":
-		fmt.Println("   go test -v ./...")
-	case "learn-dsa":
-		fmt.Println("   go test -v ./...   # See which tests fail")
-		fmt.Println("   # Then implement the functions!")
-	case "challenge-30days":
-		fmt.Println("   cd week1/day01_hello")
-		fmt.Println("   go test -v          # See failing tests")
-		fmt.Println("   # Implement the functions, then move to next day!")
-	case "mini-project":
-		fmt.Println("   cd todo-cli         # or url-shortener")
-		fmt.Println("   go test -v          # See what to implement")
-		fmt.Println("   # Read README.md for requirements")
-	case "refactoring-exercise":
-		fmt.Println("   cd exercises/01_long_function")
-		fmt.Println("   # Look at before.go, read hints.md")
-		fmt.Println("   # Create after.go with your refactored code")
-	case "code-review-exercise":
-		fmt.Println("   cd bugs/01_off_by_one")
-		fmt.Println("   go test -v          # See the failing tests")
-		fmt.Println("   # Find and fix the bugs in buggy.go!")
-	case "learn-generics":
-		fmt.Println("   cd basics && go test -v")
-		fmt.Println("   # Implement generic functions!")
-	case "learn-context":
-		fmt.Println("   cd cancellation && go test -v")
-		fmt.Println("   # Learn context cancellation patterns")
-	case "learn-http":
-		fmt.Println("   cd server && go test -v")
-		fmt.Println("   # Build HTTP handlers")
-	case "learn-error-handling":
-		fmt.Println("   cd basics && go test -v")
-		fmt.Println("   # Master error patterns")
-	case "learn-interfaces":
-		fmt.Println("   cd basics && go test -v")
-		fmt.Println("   # Learn interface design")
-	case "learn-design-patterns":
-		fmt.Println("   cd creational && go test -v")
-		fmt.Println("   # Learn design patterns")
-	case "go-microservice":
-		fmt.Println("   go mod tidy && go run ./cmd/server")
-		fmt.Println("   # curl http://localhost:8080/health")
-	case "go-websocket":
-		fmt.Println("   go get github.com/gorilla/websocket")
-		fmt.Println("   go run ./cmd/server")
-	case "go-graphql":
-		fmt.Println("   go run github.com/99designs/gqlgen generate")
-		fmt.Println("   go run ./cmd/server")
-	case "go-lambda":
-		fmt.Println("   make build")
-		fmt.Println("   sam local start-api")
-	case "go-cron":
-		fmt.Println("   go get github.com/robfig/cron/v3")
-		fmt.Println("   go run ./cmd/scheduler")
-	case "go-auth":
-		fmt.Println("   go get github.com/golang-jwt/jwt/v5")
-		fmt.Println("   go run ./cmd/server")
-	case "go-kafka":
-		fmt.Println("   docker-compose up -d")
-		fmt.Println("   go get github.com/IBM/sarama")
-	case "go-redis":
-		fmt.Println("   docker-compose up -d")
-		fmt.Println("   go get github.com/redis/go-redis/v9")
-	case "go-clean-arch":
-		fmt.Println("   go run ./cmd/api")
-	case "go-monorepo":
-		fmt.Println("   make api  # atau make worker")
-	case "learn-frontend":
-		fmt.Println("   cd 01-html-basics")
-		fmt.Println("   open index.html  # Open in your browser")
-		fmt.Println("   # Work through each folder in order!")
-	default:
-		fmt.Println("   go mod tidy")
-		fmt.Println("   go test ./...")
+// TemplateData holds data for template substitution
+type TemplateData = render.Data
+
+// newTemplateData resolves the template variables and builds the data
+// templates are rendered with. The returned template only has the files and
+// directories whose When expressions hold for the variables.
+func newTemplateData(tmpl templates.Template, config tui.ProjectConfig) (templates.Template, TemplateData, error) {
+	data, err := templateData(tmpl, config)
+	if err != nil {
+		return tmpl, TemplateData{}, err
 	}
+	tmpl, err = templates.Select(tmpl, data.Vars)
+	if err != nil {
+		return tmpl, TemplateData{}, err
+	}
+	return tmpl, data, nil
 }
 
-// TemplateData holds data for template substitution
-type TemplateData struct {
-	ProjectName string
-	PackageName string
-	ModuleName  string
-	Description string
-	License     string
+// templateData resolves the template variables and builds the data
+// templates are rendered with
+func templateData(tmpl templates.Template, config tui.ProjectConfig) (TemplateData, error) {
+	vars, err := templates.ResolveVariables(tmpl, config.Vars)
+	if err != nil {
+		return TemplateData{}, err
+	}
+	moduleName := config.ModuleName
+	if moduleName == "" {
+		moduleName = ModulePath("github.com/user", config.ProjectName)
+	}
+	return TemplateData{
+		ProjectName: config.ProjectName,
+		PackageName: render.PackageName(config.ProjectName),
+		ModuleName:  moduleName,
+		Description: fmt.Sprintf("%s - Generated by scaffold", config.ProjectName),
+		License:     config.License,
+		Author:      config.Author,
+		Vars:        vars,
+		GoVersion:   render.DefaultGoVersion,
+	}, nil
 }
 
-func sanitizePackageName(name string) string {
-	// Replace hyphens and underscores with nothing for package names
-	name = strings.ReplaceAll(name, "-", "")
-	name = strings.ReplaceAll(name, "_", "")
-	return strings.ToLower(name)
+// newLock records the template, its inputs and a hash of every file staged
+// in dir
+func newLock(tmpl templates.Template, config tui.ProjectConfig, data TemplateData, dir string) (*lockfile.Lock, error) {
+	lock := lockfile.New()
+	lock.Template = tmpl.Name
+	lock.TemplateVersion = tmpl.Version
+	lock.ProjectName = data.ProjectName
+	lock.Module = data.ModuleName
+	lock.License = data.License
+	lock.Author = data.Author
+	lock.Docker = config.IncludeDocker
+	if len(data.Vars) > 0 {
+		lock.Vars = make(map[string]string, len(data.Vars))
+		for name, value := range data.Vars {
+			lock.Vars[name] = fmt.Sprint(value)
+		}
+	}
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		lock.SetFile(rel, content)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash generated files: %w", err)
+	}
+	return lock, nil
 }
 
-func processPath(path string, data TemplateData) string {
-	path = strings.ReplaceAll(path, "{{.ProjectName}}", data.ProjectName)
-	path = strings.ReplaceAll(path, "{{.PackageName}}", data.PackageName)
-	return path
+// ModulePath joins a module prefix such as "github.com/user" and a project name
+func ModulePath(prefix, projectName string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return projectName
+	}
+	return prefix + "/" + projectName
 }
 
-func processTemplate(content string, data TemplateData) (string, error) {
-	tmpl, err := template.New("file").Parse(content)
-	if err != nil {
-		return "", err
+// copyrightHolder is the author, or the project name when no author is configured
+func copyrightHolder(data TemplateData) string {
+	if data.Author != "" {
+		return data.Author
 	}
+	return data.ProjectName
+}
+
+// File is a generated file with its final path and content
+type File struct {
+	Path    string
+	Content string
+	Mode    fs.FileMode // Permissions the file is written with
+}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", err
+// renderFiles renders the paths and contents of every template file in
+// memory, so template errors surface before anything is written
+func renderFiles(tmpl templates.Template, data TemplateData) ([]File, error) {
+	files := make([]File, 0, len(tmpl.Files))
+	from := make(map[string]string, len(tmpl.Files))
+	for _, f := range tmpl.Files {
+		path, err := processPath(f.Path, data)
+		if err != nil {
+			return nil, fmt.Errorf("file %s: %w", f.Path, err)
+		}
+		if other, ok := from[path]; ok {
+			return nil, fmt.Errorf("files %s and %s both render to %s", other, f.Path, path)
+		}
+		from[path] = f.Path
+		content := f.Content
+		if !f.Static {
+			if content, err = processTemplate(f.Content, data); err != nil {
+				return nil, fmt.Errorf("failed to process template for %s: %w", path, err)
+			}
+		}
+		files = append(files, File{Path: path, Content: content, Mode: render.FileMode(f.Mode, content)})
 	}
+	return files, nil
+}
 
-	return buf.String(), nil
+func processTemplate(content string, data TemplateData) (string, error) {
+	return render.Execute("file", content, data)
 }
 
-func generateDockerfile(templateName string, data TemplateData) string {
-	switch templateName {
-	case "go-api":
+// generateDockerfile returns the Dockerfile of a templates.DockerfileProfiles
+// profile
+func generateDockerfile(profile string, data TemplateData) string {
+	switch profile {
+	case templates.DockerfileAPI:
 		return fmt.Sprintf(`FROM golang:1.21-alpine AS builder
 
 WORKDIR /app
@@ -367,7 +524,7 @@ COPY --from=builder /app/bin/api .
 EXPOSE 8080
 CMD ["./api"]
 `)
-	case "go-cli":
+	case templates.DockerfileCLI:
 		return fmt.Sprintf(`FROM golang:1.21-alpine AS builder
 
 WORKDIR /app
@@ -392,14 +549,14 @@ CMD ["./bin/app"]
 	}
 }
 
-func generateLicense(licenseType, projectName string) string {
-	year := "2026"
+func generateLicense(licenseType, holder string) string {
+	year := time.Now().Year()
 
 	switch licenseType {
 	case "MIT":
 		return fmt.Sprintf(`MIT License
 
-Copyright (c) %s %s
+Copyright (c) %d %s
 
 Permission is hereby granted, free of charge, to any person obtaining a copy
 of this software and associated documentation files (the "Software"), to deal
@@ -418,9 +575,9 @@ AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
 LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
 OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
 SOFTWARE.
-`, year, projectName)
+`, year, holder)
 	case "Apache 2.0":
-		return fmt.Sprintf(`Copyright %s %s
+		return fmt.Sprintf(`Copyright %d %s
 
 Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
@@ -433,9 +590,9 @@ distributed under the License is distributed on an "AS IS" BASIS,
 WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
-`, year, projectName)
+`, year, holder)
 	case "GPL 3.0":
-		return fmt.Sprintf(`%s - Copyright (c) %s
+		return fmt.Sprintf(`Copyright (C) %d %s
 
 This program is free software: you can redistribute it and/or modify
 it under the terms of the GNU General Public License as published by
@@ -449,7 +606,7 @@ GNU General Public License for more details.
 
 You should have received a copy of the GNU General Public License
 along with this program. If not, see <https://www.gnu.org/licenses/>.
-`, projectName, year)
+`, year, holder)
 	default:
 		return ""
 	}
@@ -2,43 +2,30 @@ package generator
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"text/template"
+	"time"
 
+	"github.com/purnama/scaffold/internal/conflict"
+	"github.com/purnama/scaffold/internal/lockfile"
+	"github.com/purnama/scaffold/internal/safefs"
+	"github.com/purnama/scaffold/internal/templates"
 	"github.com/purnama/scaffold/internal/tui"
 )
 
-func TestSanitizePackageName(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected string
-	}{
-		{"my-project", "myproject"},
-		{"my_project", "myproject"},
-		{"MyProject", "myproject"},
-		{"my-api-server", "myapiserver"},
-		{"my_api_server", "myapiserver"},
-		{"myproject", "myproject"},
-		{"MY-PROJECT", "myproject"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			result := sanitizePackageName(tt.input)
-			if result != tt.expected {
-				t.Errorf("sanitizePackageName(%q) = %q; want %q", tt.input, result, tt.expected)
-			}
-		})
-	}
-}
-
 func TestProcessPath(t *testing.T) {
 	data := TemplateData{
 		ProjectName: "my-api",
 		PackageName: "myapi",
+		Vars:        map[string]any{"service": "billing", "version": 2},
 	}
 
 	tests := []struct {
@@ -49,11 +36,18 @@ func TestProcessPath(t *testing.T) {
 		{"{{.ProjectName}}.go", "my-api.go"},
 		{"{{.ProjectName}}_test.go", "my-api_test.go"},
 		{"pkg/{{.PackageName}}/main.go", "pkg/myapi/main.go"},
+		{"cmd/{{.Vars.service}}/main.go", "cmd/billing/main.go"},
+		{"api/v{{.Vars.version}}/api.go", "api/v2/api.go"},
+		{"internal/{{.ProjectName | snake}}/{{.Vars.service | pascal}}.go", "internal/my_api/Billing.go"},
+		{"cmd/{{.ProjectName | goIdent}}/main.go", "cmd/myApi/main.go"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			result := processPath(tt.input, data)
+			result, err := processPath(tt.input, data)
+			if err != nil {
+				t.Fatalf("processPath failed: %v", err)
+			}
 			if result != tt.expected {
 				t.Errorf("processPath(%q) = %q; want %q", tt.input, result, tt.expected)
 			}
@@ -124,6 +118,50 @@ import "github.com/user/my-api"
 	}
 }
 
+func TestProcessTemplateVars(t *testing.T) {
+	data := TemplateData{
+		ProjectName: "my-api",
+		Vars:        map[string]any{"port": 9000, "metrics": true},
+	}
+
+	result, err := processTemplate(`port={{.Vars.port}}{{if .Vars.metrics}} metrics{{end}}`, data)
+	if err != nil {
+		t.Fatalf("processTemplate failed: %v", err)
+	}
+	if result != "port=9000 metrics" {
+		t.Errorf("processTemplate = %q; want %q", result, "port=9000 metrics")
+	}
+}
+
+func TestRenderAllTemplates(t *testing.T) {
+	for _, tmpl := range templates.GetAllTemplates() {
+		t.Run(tmpl.Name, func(t *testing.T) {
+			config := tui.ProjectConfig{
+				ProjectName:   "my-app",
+				TemplateName:  tmpl.Name,
+				ModuleName:    "example.com/my-app",
+				License:       "MIT",
+				IncludeDocker: true,
+			}
+			if _, err := RenderTemplate(tmpl, config); err != nil {
+				t.Errorf("RenderTemplate failed: %v", err)
+			}
+		})
+	}
+
+	// The README headings that use title render with it
+	tmpl, _ := templates.GetTemplate("go-wasm")
+	out, err := RenderTemplate(tmpl, tui.ProjectConfig{ProjectName: "my-app", TemplateName: "go-wasm"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range out.Files {
+		if f.Path == "README.md" && !strings.Contains(f.Content, "My App") {
+			t.Errorf("README.md doesn't contain the title:\n%s", f.Content)
+		}
+	}
+}
+
 func TestProcessTemplateError(t *testing.T) {
 	data := TemplateData{
 		ProjectName: "my-api",
@@ -181,6 +219,261 @@ func TestGenerateDirStructure(t *testing.T) {
 	}
 }
 
+func TestGenerateWithVars(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalCwd, _ := os.Getwd()
+	defer os.Chdir(originalCwd)
+	os.Chdir(tmpDir)
+
+	config := tui.ProjectConfig{
+		ProjectName:  "test-project",
+		TemplateName: "go-api",
+		License:      "None",
+		Vars:         map[string]string{"port": "9090"},
+	}
+
+	if err := GenerateWithOptions(config, Options{}); err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "test-project", "pkg/config/config.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `port = "9090"`) {
+		t.Errorf("expected port variable in config.go, got:\n%s", content)
+	}
+
+	// Invalid values are rejected before anything is written
+	config.ProjectName = "invalid-project"
+	config.Vars = map[string]string{"port": "http"}
+	if err := GenerateWithOptions(config, Options{}); err == nil {
+		t.Error("expected error for invalid variable value")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "invalid-project")); err == nil {
+		t.Error("project directory should not be created for invalid variables")
+	}
+}
+
+func TestGenerateWhen(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "svc")
+	os.MkdirAll(filepath.Join(dir, "migrations"), 0755)
+	os.WriteFile(filepath.Join(dir, "template.json"), []byte(`{
+  "variables": [
+    {"name": "db", "type": "choice", "choices": ["postgres", "mysql", "none"], "default": "postgres"},
+    {"name": "docker", "type": "bool"}
+  ],
+  "directories": ["migrations"],
+  "when": {
+    "postgres.go": "db == 'postgres'",
+    "mysql.go": "db == 'mysql'",
+    "migrations": "db != 'none'",
+    "compose.yaml": "docker"
+  }
+}`), 0644)
+	for _, name := range []string{"main.go", "postgres.go", "mysql.go", "migrations/001.sql", "compose.yaml"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("-- "+name+"\n"), 0644)
+	}
+	if err := templates.LoadCustomTemplates(filepath.Dir(dir)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { templates.LoadCustomTemplates(t.TempDir()) })
+
+	tests := []struct {
+		vars    map[string]string
+		want    []string
+		notWant []string
+	}{
+		{nil, []string{"main.go", "postgres.go", "migrations/001.sql"}, []string{"mysql.go", "compose.yaml"}},
+		{map[string]string{"db": "mysql", "docker": "true"}, []string{"mysql.go", "migrations/001.sql", "compose.yaml"}, []string{"postgres.go"}},
+		{map[string]string{"db": "none"}, []string{"main.go"}, []string{"postgres.go", "mysql.go", "migrations"}},
+	}
+
+	for i, tt := range tests {
+		config := tui.ProjectConfig{
+			ProjectName:  fmt.Sprintf("app%d", i),
+			TemplateName: "svc",
+			License:      "None",
+			OutputDir:    t.TempDir(),
+			Vars:         tt.vars,
+		}
+		if err := GenerateWithOptions(config, Options{}); err != nil {
+			t.Fatalf("GenerateWithOptions(%v) failed: %v", tt.vars, err)
+		}
+		projectDir := filepath.Join(config.OutputDir, config.ProjectName)
+		for _, p := range tt.want {
+			if _, err := os.Stat(filepath.Join(projectDir, p)); err != nil {
+				t.Errorf("vars %v: %s missing", tt.vars, p)
+			}
+		}
+		for _, p := range tt.notWant {
+			if _, err := os.Stat(filepath.Join(projectDir, p)); err == nil {
+				t.Errorf("vars %v: %s should not be generated", tt.vars, p)
+			}
+		}
+
+		lock, err := lockfile.Read(projectDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range tt.notWant {
+			if _, ok := lock.Files[p]; ok {
+				t.Errorf("vars %v: %s recorded in the lockfile", tt.vars, p)
+			}
+		}
+	}
+}
+
+func TestGenerateWritesLockfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := tui.ProjectConfig{
+		ProjectName:   "billing",
+		TemplateName:  "go-api",
+		License:       "MIT",
+		IncludeDocker: true,
+		ModuleName:    "example.com/billing",
+		OutputDir:     tmpDir,
+		Vars:          map[string]string{"port": "9090"},
+	}
+	if err := GenerateWithOptions(config, Options{}); err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+
+	projectDir := filepath.Join(tmpDir, "billing")
+	lock, err := lockfile.Read(projectDir)
+	if err != nil {
+		t.Fatalf("lockfile.Read() error = %v", err)
+	}
+	tmpl, _ := templates.GetTemplate("go-api")
+	if lock.Template != "go-api" || lock.TemplateVersion != tmpl.Version || lock.Module != "example.com/billing" ||
+		lock.License != "MIT" || !lock.Docker || lock.Vars["port"] != "9090" || lock.ScaffoldVersion != lockfile.ScaffoldVersion {
+		t.Errorf("unexpected lock: %+v", lock)
+	}
+
+	// Every file but the lockfile itself is hashed
+	var files []string
+	filepath.WalkDir(projectDir, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(projectDir, p)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	for _, f := range files {
+		content, _ := os.ReadFile(filepath.Join(projectDir, f))
+		switch {
+		case f == lockfile.FileName:
+			if _, ok := lock.Files[f]; ok {
+				t.Error("the lockfile should not hash itself")
+			}
+		case lock.Files[f] != lockfile.Hash(content):
+			t.Errorf("hash of %s = %q, want %q", f, lock.Files[f], lockfile.Hash(content))
+		}
+	}
+	if len(lock.Files) != len(files)-1 {
+		t.Errorf("lock has %d files, project has %d besides the lockfile", len(lock.Files), len(files)-1)
+	}
+}
+
+func TestGenerateLockfileHashesGeneratedContent(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectPath := filepath.Join(tmpDir, "tool")
+	os.Mkdir(projectPath, 0755)
+	os.WriteFile(filepath.Join(projectPath, "README.md"), []byte("my notes\n"), 0644)
+
+	config := tui.ProjectConfig{ProjectName: "tool", TemplateName: "go-cli", License: "None", OutputDir: tmpDir}
+	if err := GenerateWithOptions(config, Options{Conflict: conflict.Skip}); err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+
+	// The kept README shows up as modified: its hash is the template's
+	lock, err := lockfile.Read(projectPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.Files["README.md"] == "" || lock.Files["README.md"] == lockfile.Hash([]byte("my notes\n")) {
+		t.Errorf("README.md hash = %q, want the hash of the generated content", lock.Files["README.md"])
+	}
+}
+
+func TestGenerateOutputDirAndModule(t *testing.T) {
+	tmpDir := t.TempDir()
+	outDir := filepath.Join(tmpDir, "services")
+
+	config := tui.ProjectConfig{
+		ProjectName:  "billing",
+		TemplateName: "go-cli",
+		License:      "None",
+		ModuleName:   "example.com/acme/billing",
+		OutputDir:    outDir,
+	}
+
+	if err := GenerateWithOptions(config, Options{}); err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outDir, "billing", "main.go"))
+	if err != nil {
+		t.Fatalf("project not created in output dir: %v", err)
+	}
+	if !strings.Contains(string(content), "example.com/acme/billing") {
+		t.Errorf("expected module path in main.go, got:\n%s", content)
+	}
+}
+
+func TestGenerateWithAuthor(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	config := tui.ProjectConfig{
+		ProjectName:  "billing",
+		TemplateName: "go-api",
+		License:      "MIT",
+		Author:       "Jane Doe",
+		OutputDir:    tmpDir,
+	}
+
+	if err := GenerateWithOptions(config, Options{}); err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+
+	for _, file := range []string{"LICENSE", "README.md"} {
+		content, err := os.ReadFile(filepath.Join(tmpDir, "billing", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(content), "Jane Doe") {
+			t.Errorf("%s should mention the author, got:\n%s", file, content)
+		}
+	}
+}
+
+func TestModulePath(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		name     string
+		expected string
+	}{
+		{"github.com/user", "my-api", "github.com/user/my-api"},
+		{"gitlab.acme.io/platform/", "svc", "gitlab.acme.io/platform/svc"},
+		{"", "tool", "tool"},
+	}
+
+	for _, tt := range tests {
+		if got := ModulePath(tt.prefix, tt.name); got != tt.expected {
+			t.Errorf("ModulePath(%q, %q) = %q; want %q", tt.prefix, tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestGenerateRefusesBadModulePath(t *testing.T) {
+	config := tui.ProjectConfig{ProjectName: "tool", TemplateName: "go-cli", ModuleName: "github.com/user/my tool", OutputDir: t.TempDir()}
+	for _, dryRun := range []bool{false, true} {
+		if err := GenerateWithOptions(config, Options{DryRun: dryRun}); err == nil || !strings.Contains(err.Error(), "invalid module path") {
+			t.Errorf("GenerateWithOptions(dry run %v) error = %v, want the module path refused", dryRun, err)
+		}
+	}
+}
+
 func TestGenerateDryRun(t *testing.T) {
 	tmpDir := t.TempDir()
 	originalCwd, _ := os.Getwd()
@@ -244,6 +537,226 @@ func TestGenerateForceOverwrite(t *testing.T) {
 	}
 }
 
+func TestGenerateConflictModes(t *testing.T) {
+	tests := []struct {
+		mode  conflict.Mode
+		check func(t *testing.T, dir string)
+	}{
+		{conflict.Skip, func(t *testing.T, dir string) {
+			assertFile(t, filepath.Join(dir, "README.md"), "my notes\n")
+			assertFile(t, filepath.Join(dir, ".gitignore"), "secret.txt\n")
+		}},
+		{conflict.Backup, func(t *testing.T, dir string) {
+			assertFile(t, filepath.Join(dir, "README.md.orig"), "my notes\n")
+			assertFile(t, filepath.Join(dir, ".gitignore.orig"), "secret.txt\n")
+		}},
+		{conflict.Merge, func(t *testing.T, dir string) {
+			// README.md can't be merged and is kept, .gitignore gains lines
+			assertFile(t, filepath.Join(dir, "README.md"), "my notes\n")
+			content, _ := os.ReadFile(filepath.Join(dir, ".gitignore"))
+			if !strings.HasPrefix(string(content), "secret.txt\n") || len(content) <= len("secret.txt\n") {
+				t.Errorf(".gitignore not merged:\n%s", content)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			tmpDir := t.TempDir()
+			projectPath := filepath.Join(tmpDir, "tool")
+			os.Mkdir(projectPath, 0755)
+			os.WriteFile(filepath.Join(projectPath, "README.md"), []byte("my notes\n"), 0644)
+			os.WriteFile(filepath.Join(projectPath, ".gitignore"), []byte("secret.txt\n"), 0644)
+			os.WriteFile(filepath.Join(projectPath, "TODO"), []byte("untouched\n"), 0644)
+
+			config := tui.ProjectConfig{ProjectName: "tool", TemplateName: "go-cli", License: "None", OutputDir: tmpDir}
+			if err := GenerateWithOptions(config, Options{Conflict: tt.mode}); err != nil {
+				t.Fatalf("GenerateWithOptions failed: %v", err)
+			}
+
+			tt.check(t, projectPath)
+			assertFile(t, filepath.Join(projectPath, "TODO"), "untouched\n")
+			if _, err := os.Stat(filepath.Join(projectPath, "main.go")); err != nil {
+				t.Error("new template files should be created")
+			}
+		})
+	}
+}
+
+func TestGenerateConflictPrompt(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectPath := filepath.Join(tmpDir, "tool")
+	os.Mkdir(projectPath, 0755)
+	os.WriteFile(filepath.Join(projectPath, "main.go"), []byte("package main // mine\n"), 0644)
+
+	var asked []string
+	opts := Options{
+		Conflict: conflict.Prompt,
+		Ask: func(path string, existing, proposed []byte) (conflict.Mode, error) {
+			asked = append(asked, path)
+			return conflict.Skip, nil
+		},
+	}
+	config := tui.ProjectConfig{ProjectName: "tool", TemplateName: "go-cli", License: "None", OutputDir: tmpDir}
+	if err := GenerateWithOptions(config, opts); err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+
+	if len(asked) != 1 || asked[0] != "main.go" {
+		t.Errorf("expected one question about main.go, got %v", asked)
+	}
+	assertFile(t, filepath.Join(projectPath, "main.go"), "package main // mine\n")
+}
+
+func TestGenerateInvalidProjectName(t *testing.T) {
+	for _, name := range []string{"", "..", "../evil", "a/b", "/tmp/x", `..\evil`, "con", "tab\there"} {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			config := tui.ProjectConfig{ProjectName: name, TemplateName: "go-cli", License: "None", OutputDir: filepath.Join(tmpDir, "out")}
+			for _, opts := range []Options{{}, {DryRun: true}} {
+				err := GenerateWithOptions(config, opts)
+				if err == nil || !strings.Contains(err.Error(), "invalid project name") {
+					t.Errorf("GenerateWithOptions(dry run %v) error = %v, want the name refused", opts.DryRun, err)
+				}
+			}
+			if entries, _ := os.ReadDir(tmpDir); len(entries) != 0 {
+				t.Errorf("refused name left debris: %v", entries)
+			}
+		})
+	}
+}
+
+func TestGenerateSymlinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		target func(projectPath, outside string) string
+		follow bool
+		err    error
+	}{
+		{"outside", func(_, outside string) string { return filepath.Join(outside, "notes") }, false, safefs.ErrSymlink},
+		{"outside followed", func(_, outside string) string { return filepath.Join(outside, "notes") }, true, safefs.ErrOutside},
+		{"inside", func(projectPath, _ string) string { return filepath.Join(projectPath, "docs", "README.md") }, false, safefs.ErrSymlink},
+		{"inside followed", func(projectPath, _ string) string { return filepath.Join(projectPath, "docs", "README.md") }, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			outside := t.TempDir()
+			os.WriteFile(filepath.Join(outside, "notes"), []byte("secret\n"), 0644)
+			projectPath := filepath.Join(tmpDir, "tool")
+			os.MkdirAll(filepath.Join(projectPath, "docs"), 0755)
+			os.WriteFile(filepath.Join(projectPath, "docs", "README.md"), []byte("my notes\n"), 0644)
+			if err := os.Symlink(tt.target(projectPath, outside), filepath.Join(projectPath, "README.md")); err != nil {
+				t.Skipf("symlinks not supported: %v", err)
+			}
+
+			config := tui.ProjectConfig{ProjectName: "tool", TemplateName: "go-cli", License: "None", OutputDir: tmpDir}
+			err := GenerateWithOptions(config, Options{Conflict: conflict.Overwrite, FollowSymlinks: tt.follow})
+			assertFile(t, filepath.Join(outside, "notes"), "secret\n")
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("GenerateWithOptions error = %v, want %v", err, tt.err)
+				}
+				assertFile(t, filepath.Join(projectPath, "docs", "README.md"), "my notes\n")
+				if _, err := os.Stat(filepath.Join(projectPath, "main.go")); err == nil {
+					t.Error("refused project was partly written")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateWithOptions failed: %v", err)
+			}
+			if content, _ := os.ReadFile(filepath.Join(projectPath, "docs", "README.md")); string(content) == "my notes\n" {
+				t.Error("the file the link points to wasn't written")
+			}
+		})
+	}
+}
+
+func assertFile(t *testing.T, path, want string) {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("reading %s: %v", path, err)
+		return
+	}
+	if string(content) != want {
+		t.Errorf("%s = %q, want %q", filepath.Base(path), content, want)
+	}
+}
+
+// loadBrokenTemplate registers a custom template named "broken" whose second
+// file fails to render
+func loadBrokenTemplate(t *testing.T) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "broken")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "template.json"), []byte(`{"name": "broken"}`), 0644)
+	os.WriteFile(filepath.Join(dir, "a.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("{{.ProjectName | nosuchfunc}}\n"), 0644)
+
+	if err := templates.LoadCustomTemplates(filepath.Dir(dir)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { templates.LoadCustomTemplates(t.TempDir()) })
+}
+
+func TestGenerateLeavesNothingOnError(t *testing.T) {
+	loadBrokenTemplate(t)
+	tmpDir := t.TempDir()
+
+	config := tui.ProjectConfig{
+		ProjectName:  "app",
+		TemplateName: "broken",
+		License:      "MIT",
+		OutputDir:    filepath.Join(tmpDir, "new", "dir"),
+	}
+	if err := GenerateWithOptions(config, Options{}); err == nil {
+		t.Fatal("expected template error")
+	}
+
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 0 {
+		t.Errorf("failed init left debris: %v", entries)
+	}
+}
+
+func TestGenerateForceKeepsFilesOnError(t *testing.T) {
+	loadBrokenTemplate(t)
+	tmpDir := t.TempDir()
+	projectPath := filepath.Join(tmpDir, "app")
+	os.Mkdir(projectPath, 0755)
+	os.WriteFile(filepath.Join(projectPath, "a.go"), []byte("my work"), 0644)
+
+	config := tui.ProjectConfig{
+		ProjectName:  "app",
+		TemplateName: "broken",
+		License:      "None",
+		OutputDir:    tmpDir,
+	}
+	if err := GenerateWithOptions(config, Options{Force: true}); err == nil {
+		t.Fatal("expected template error")
+	}
+
+	content, _ := os.ReadFile(filepath.Join(projectPath, "a.go"))
+	if string(content) != "my work" {
+		t.Errorf("existing file was clobbered: %q", content)
+	}
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 1 {
+		t.Errorf("failed init left debris: %v", entries)
+	}
+}
+
+func TestGenerateDryRunReportsTemplateErrors(t *testing.T) {
+	loadBrokenTemplate(t)
+
+	config := tui.ProjectConfig{ProjectName: "app", TemplateName: "broken", OutputDir: t.TempDir()}
+	if err := GenerateWithOptions(config, Options{DryRun: true}); err == nil {
+		t.Error("expected dry run to report the template error")
+	}
+}
+
 func TestGenerateFilePermissions(t *testing.T) {
 	tmpDir := t.TempDir()
 	originalCwd, _ := os.Getwd()
@@ -285,10 +798,89 @@ func TestGenerateFilePermissions(t *testing.T) {
 	}
 }
 
+func TestGenerateFileModes(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "modes")
+	os.MkdirAll(filepath.Join(dir, "scripts"), 0755)
+	png := "\x89PNG\r\n\x1a\n\x00\x00{{.ProjectName}}\xff"
+	files := map[string]string{
+		"template.json":      `{"modes": {"secrets.env": "0600"}, "static": ["web/*.html"]}`,
+		"main.go":            "package main\n",
+		"scripts/install.sh": "#!/bin/sh\necho {{.ProjectName}}\n",
+		"secrets.env":        "TOKEN=\n",
+		"logo.png":           png,
+		"web/index.html":     "<p>{{.Title}}</p>\n",
+	}
+	for p, content := range files {
+		os.MkdirAll(filepath.Dir(filepath.Join(dir, p)), 0755)
+		os.WriteFile(filepath.Join(dir, p), []byte(content), 0644)
+	}
+	if err := templates.LoadCustomTemplates(filepath.Dir(dir)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { templates.LoadCustomTemplates(t.TempDir()) })
+
+	config := tui.ProjectConfig{ProjectName: "app", TemplateName: "modes", License: "MIT", OutputDir: t.TempDir()}
+	if err := GenerateWithOptions(config, Options{}); err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+	projectPath := filepath.Join(config.OutputDir, "app")
+
+	modes := map[string]fs.FileMode{
+		"main.go":            0644,
+		"scripts/install.sh": 0755,
+		"secrets.env":        0600,
+		"logo.png":           0644,
+		"LICENSE":            0644,
+	}
+	for p, want := range modes {
+		info, err := os.Stat(filepath.Join(projectPath, p))
+		if err != nil {
+			t.Errorf("%s missing: %v", p, err)
+			continue
+		}
+		// The umask may remove bits, never add them
+		if got := info.Mode().Perm(); got&^want != 0 || got&0700 != want&0700 {
+			t.Errorf("%s mode = %04o, want %04o", p, got, want)
+		}
+	}
+
+	assertFile(t, filepath.Join(projectPath, "scripts", "install.sh"), "#!/bin/sh\necho app\n")
+	assertFile(t, filepath.Join(projectPath, "logo.png"), png)
+	assertFile(t, filepath.Join(projectPath, "web", "index.html"), "<p>{{.Title}}</p>\n")
+}
+
+func TestGenerateStaticAssets(t *testing.T) {
+	for _, tt := range []struct{ template, path string }{
+		{"go-web-htmx", "static/favicon.ico"},
+		{"fullstack", "frontend/public/favicon.ico"},
+	} {
+		t.Run(tt.template, func(t *testing.T) {
+			tmpl, err := templates.GetTemplate(tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := RenderTemplate(tmpl, tui.ProjectConfig{ProjectName: "app", TemplateName: tt.template})
+			if err != nil {
+				t.Fatalf("RenderTemplate failed: %v", err)
+			}
+			for _, f := range out.Files {
+				if f.Path == tt.path {
+					if !bytes.HasPrefix([]byte(f.Content), []byte{0, 0, 1, 0}) {
+						t.Errorf("%s isn't an icon: % x", f.Path, f.Content[:min(len(f.Content), 8)])
+					}
+					return
+				}
+			}
+			t.Errorf("%s not generated", tt.path)
+		})
+	}
+}
+
 func TestGenerateLicense(t *testing.T) {
 	data := TemplateData{
 		ProjectName: "test-project",
 	}
+	year := strconv.Itoa(time.Now().Year())
 
 	tests := []struct {
 		name            string
@@ -298,17 +890,17 @@ func TestGenerateLicense(t *testing.T) {
 		{
 			"MIT license",
 			"MIT",
-			[]string{"MIT License", "test-project", "Permission is hereby granted"},
+			[]string{"MIT License", "Copyright (c) " + year + " test-project", "Permission is hereby granted"},
 		},
 		{
 			"Apache 2.0 license",
 			"Apache 2.0",
-			[]string{"Apache License", "Version 2.0", "test-project"},
+			[]string{"Apache License", "Version 2.0", "Copyright " + year + " test-project"},
 		},
 		{
 			"GPL 3.0 license",
 			"GPL 3.0",
-			[]string{"GNU General Public License", "test-project"},
+			[]string{"GNU General Public License", "Copyright (C) " + year + " test-project"},
 		},
 	}
 
@@ -336,25 +928,25 @@ func TestGenerateDockerfile(t *testing.T) {
 
 	tests := []struct {
 		name             string
-		templateName     string
+		profile          string
 		expectedContent  []string
 		shouldNotContain []string
 	}{
 		{
-			"go-api dockerfile",
-			"go-api",
+			"api profile",
+			templates.DockerfileAPI,
 			[]string{"golang:1.21-alpine", "WORKDIR /app", "./api"},
 			[]string{},
 		},
 		{
-			"go-cli dockerfile",
-			"go-cli",
+			"cli profile",
+			templates.DockerfileCLI,
 			[]string{"golang:1.21-alpine", "WORKDIR /app", "my-api"},
 			[]string{},
 		},
 		{
-			"default dockerfile",
-			"go-tui",
+			"default profile",
+			templates.DockerfileDefault,
 			[]string{"golang:1.21-alpine", "WORKDIR /app"},
 			[]string{},
 		},
@@ -362,10 +954,10 @@ func TestGenerateDockerfile(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			dockerfile := generateDockerfile(tt.templateName, data)
+			dockerfile := generateDockerfile(tt.profile, data)
 
 			if dockerfile == "" {
-				t.Errorf("generateDockerfile(%q) returned empty string", tt.templateName)
+				t.Errorf("generateDockerfile(%q) returned empty string", tt.profile)
 			}
 
 			for _, expected := range tt.expectedContent {
@@ -383,6 +975,50 @@ func TestGenerateDockerfile(t *testing.T) {
 	}
 }
 
+func TestGenerateTemplateMetadata(t *testing.T) {
+	if _, err := exec.LookPath("touch"); err != nil {
+		t.Skip("touch not available")
+	}
+	dir := filepath.Join(t.TempDir(), "site")
+	os.MkdirAll(filepath.Join(dir, "web"), 0755)
+	os.WriteFile(filepath.Join(dir, "template.json"), []byte(`{
+  "skip_go_mod": true,
+  "dockerfile": "cli",
+  "post_init": [
+    {"command": ["touch", "installed"], "dir": "web"},
+    {"command": ["no-such-program-for-scaffold"]}
+  ]
+}`), 0644)
+	os.WriteFile(filepath.Join(dir, "web", "index.html"), []byte("<h1>{{.ProjectName}}</h1>\n"), 0644)
+	if err := templates.LoadCustomTemplates(filepath.Dir(dir)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { templates.LoadCustomTemplates(t.TempDir()) })
+
+	config := tui.ProjectConfig{
+		ProjectName:   "app",
+		TemplateName:  "site",
+		License:       "None",
+		IncludeDocker: true,
+		OutputDir:     t.TempDir(),
+	}
+	if err := GenerateWithOptions(config, Options{Trust: trustAll}); err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+	projectDir := filepath.Join(config.OutputDir, "app")
+
+	if _, err := os.Stat(filepath.Join(projectDir, "go.mod")); err == nil {
+		t.Error("go.mod created despite skip_go_mod")
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, "web", "installed")); err != nil {
+		t.Error("post-init hook did not run in web/")
+	}
+	dockerfile, _ := os.ReadFile(filepath.Join(projectDir, "Dockerfile"))
+	if !strings.Contains(string(dockerfile), `ENTRYPOINT ["./app"]`) {
+		t.Errorf("expected the cli Dockerfile, got:\n%s", dockerfile)
+	}
+}
+
 func TestTemplateDataVariables(t *testing.T) {
 	data := TemplateData{
 		ProjectName: "my-api",
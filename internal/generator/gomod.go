@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/purnama/scaffold/internal/templates"
+)
+
+// tidyTimeout bounds go mod tidy, which may wait on an unreachable proxy
+const tidyTimeout = 2 * time.Minute
+
+// initGoMod creates go.mod in dir and requires deps at their pinned
+// versions. go mod init and go mod edit work offline. With tidy, go mod tidy
+// then downloads the modules and writes go.sum; it needs the module cache or
+// a proxy, so a failure is reported and left for the user to retry. It
+// returns whether go mod tidy succeeded.
+func initGoMod(dir, module string, deps []templates.Dependency, tidy bool, report func(Event)) bool {
+	report(Event{Kind: Debug, Message: "running go mod init " + module})
+	cmd := exec.Command("go", "mod", "init", module)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		report(Event{Kind: Warning, Message: "go mod init: " + strings.TrimSpace(string(output))})
+		return false
+	}
+	report(Event{Kind: FileWritten, Path: "go.mod", Mode: 0644})
+
+	if len(deps) > 0 {
+		args := []string{"mod", "edit"}
+		for _, d := range deps {
+			args = append(args, "-require="+d.String())
+		}
+		report(Event{Kind: Debug, Message: "running go " + strings.Join(args, " ")})
+		cmd := exec.Command("go", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			report(Event{Kind: Warning, Message: "go mod edit: " + strings.TrimSpace(string(output))})
+			return false
+		}
+		for _, d := range deps {
+			report(Event{Kind: DependencyAdded, Module: d.Path, Version: d.Version})
+		}
+	}
+
+	if !tidy {
+		return false
+	}
+	report(Event{Kind: Debug, Message: "running go mod tidy"})
+	ctx, cancel := context.WithTimeout(context.Background(), tidyTimeout)
+	defer cancel()
+	cmd = exec.CommandContext(ctx, "go", "mod", "tidy")
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			output = []byte("timed out after " + tidyTimeout.String())
+		}
+		report(Event{Kind: Warning, Message: "go mod tidy failed, run it once the module proxy is reachable: " + strings.TrimSpace(string(output))})
+		return false
+	}
+	report(Event{Kind: CommandRun, Command: "go mod tidy"})
+	return true
+}
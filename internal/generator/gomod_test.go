@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/purnama/scaffold/internal/templates"
+	"github.com/purnama/scaffold/internal/tui"
+)
+
+// offlineGo makes go commands fail instead of downloading modules
+func offlineGo(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOMODCACHE", t.TempDir())
+}
+
+func TestGenerateDependencies(t *testing.T) {
+	offlineGo(t)
+	config := tui.ProjectConfig{
+		ProjectName:  "chat",
+		TemplateName: "go-websocket",
+		License:      "None",
+		ModuleName:   "example.com/chat",
+		OutputDir:    t.TempDir(),
+	}
+	if err := GenerateWithOptions(config, Options{}); err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+
+	gomod, err := os.ReadFile(filepath.Join(config.OutputDir, "chat", "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"module example.com/chat", "require github.com/gorilla/websocket v1.5.3"} {
+		if !strings.Contains(string(gomod), want) {
+			t.Errorf("go.mod missing %q:\n%s", want, gomod)
+		}
+	}
+}
+
+func TestInitGoMod(t *testing.T) {
+	offlineGo(t)
+	deps := []templates.Dependency{
+		{Path: "github.com/robfig/cron/v3", Version: "v3.0.1"},
+		{Path: "gopkg.in/yaml.v3", Version: "v3.0.1"},
+	}
+
+	tests := []struct {
+		name   string
+		deps   []templates.Dependency
+		code   string
+		tidy   bool
+		tidied bool
+	}{
+		{"no tidy", deps, "package main\n", false, false},
+		{"tidy without imports", nil, "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println() }\n", true, true},
+		// The modules can't be downloaded, go.mod keeps the requires
+		{"tidy offline", deps, "package main\n\nimport _ \"github.com/robfig/cron/v3\"\n", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			os.WriteFile(filepath.Join(dir, "main.go"), []byte(tt.code), 0644)
+
+			var warnings []string
+			report := func(e Event) {
+				if e.Kind == Warning {
+					warnings = append(warnings, e.Message)
+				}
+			}
+			if got := initGoMod(dir, "example.com/app", tt.deps, tt.tidy, report); got != tt.tidied {
+				t.Errorf("initGoMod = %v, want %v", got, tt.tidied)
+			}
+			// Only a failed go mod tidy is reported
+			if failed := tt.tidy && !tt.tidied; failed != (len(warnings) > 0) {
+				t.Errorf("warnings = %q", warnings)
+			}
+			gomod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, d := range tt.deps {
+				if !strings.Contains(string(gomod), d.Path+" "+d.Version) {
+					t.Errorf("go.mod doesn't require %s:\n%s", d, gomod)
+				}
+			}
+		})
+	}
+}
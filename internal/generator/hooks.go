@@ -0,0 +1,95 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/purnama/scaffold/internal/hooks"
+	"github.com/purnama/scaffold/internal/templates"
+	"github.com/purnama/scaffold/internal/tui"
+)
+
+// Truster decides whether hooks scaffold doesn't ship may run, e.g. by
+// asking the user. source names where they come from.
+type Truster func(source string, hs []hooks.Hook) (bool, error)
+
+// projectHooks returns the hooks to run before and after init: the
+// template's, then the ones from opts. Hooks of a custom template are left
+// out unless opts.Trust accepts them.
+func projectHooks(tmpl templates.Template, opts Options) (pre, post []hooks.Hook, err error) {
+	if opts.NoHooks {
+		return nil, nil, nil
+	}
+
+	pre, post = tmpl.PreInit, tmpl.PostInit
+	if tmpl.Source != templates.SourceBuiltIn && len(pre)+len(post) > 0 {
+		trusted := false
+		if opts.Trust != nil {
+			source := fmt.Sprintf("template %s (%s)", tmpl.Name, tmpl.Source)
+			if trusted, err = opts.Trust(source, slices.Concat(pre, post)); err != nil {
+				return nil, nil, err
+			}
+		}
+		if !trusted {
+			opts.report(Event{Kind: Warning, Message: fmt.Sprintf("Skipping the hooks of custom template %s, they weren't trusted", tmpl.Name)})
+			pre, post = nil, nil
+		}
+	}
+
+	pre, post = slices.Concat(pre, opts.PreHooks), slices.Concat(post, opts.PostHooks)
+	if err := hooks.Validate(slices.Concat(pre, post)); err != nil {
+		return nil, nil, err
+	}
+	return pre, post, nil
+}
+
+// hookStages name the stage of the hooks run at a step in events
+var hookStages = map[Step]string{
+	StepPreInit:  "pre-init",
+	StepPostInit: "post-init",
+}
+
+// runHooks runs hs in dir at step with the project described in the
+// environment
+func runHooks(step Step, dir string, hs []hooks.Hook, config tui.ProjectConfig, data TemplateData, opts Options) error {
+	if len(hs) == 0 {
+		return nil
+	}
+	opts.report(Event{Kind: StepStarted, Step: step})
+	r := hooks.Runner{
+		Dir: dir,
+		Env: []string{
+			"SCAFFOLD_PROJECT_NAME=" + config.ProjectName,
+			"SCAFFOLD_MODULE=" + data.ModuleName,
+			"SCAFFOLD_TEMPLATE=" + config.TemplateName,
+		},
+		Progress: HookProgress(hookStages[step], opts.Reporter),
+	}
+	_, err := r.Run(hs)
+	return err
+}
+
+// RunHooks runs the pre- and post-init hooks of config's template and of
+// opts in the existing project at projectDir, without generating anything
+func RunHooks(projectDir string, config tui.ProjectConfig, opts Options) error {
+	tmpl, err := templates.GetTemplate(config.TemplateName)
+	if err != nil {
+		return err
+	}
+	tmpl, data, err := newTemplateData(tmpl, config)
+	if err != nil {
+		return err
+	}
+	pre, post, err := projectHooks(tmpl, opts)
+	if err != nil {
+		return err
+	}
+	if len(pre)+len(post) == 0 {
+		opts.report(Event{Kind: Info, Message: "No hooks to run"})
+		return nil
+	}
+	if err := runHooks(StepPreInit, projectDir, pre, config, data, opts); err != nil {
+		return err
+	}
+	return runHooks(StepPostInit, projectDir, post, config, data, opts)
+}
@@ -0,0 +1,167 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/purnama/scaffold/internal/hooks"
+	"github.com/purnama/scaffold/internal/templates"
+	"github.com/purnama/scaffold/internal/tui"
+)
+
+func trustAll(string, []hooks.Hook) (bool, error) { return true, nil }
+
+// loadHookTemplate registers a custom template named "hooked" whose pre-init
+// hook writes pre.txt and whose post-init hook writes post.txt
+func loadHookTemplate(t *testing.T) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "hooked")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "template.json"), []byte(`{
+  "pre_init": [{"run": "echo \"$SCAFFOLD_PROJECT_NAME\" > pre.txt"}],
+  "post_init": [{"run": "echo \"$SCAFFOLD_TEMPLATE\" > post.txt"}]
+}`), 0644)
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644)
+
+	if err := templates.LoadCustomTemplates(filepath.Dir(dir)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { templates.LoadCustomTemplates(t.TempDir()) })
+}
+
+func TestGenerateHooks(t *testing.T) {
+	loadHookTemplate(t)
+
+	var asked []string
+	askAndTrust := func(source string, hs []hooks.Hook) (bool, error) {
+		asked = append(asked, source)
+		return true, nil
+	}
+
+	tests := []struct {
+		name  string
+		opts  Options
+		files []string // Created by hooks
+		asked bool
+	}{
+		{
+			name:  "trusted",
+			opts:  Options{Trust: askAndTrust},
+			files: []string{"pre.txt", "post.txt"},
+			asked: true,
+		},
+		{
+			name: "not trusted",
+			opts: Options{Trust: func(string, []hooks.Hook) (bool, error) { return false, nil }},
+		},
+		{
+			name: "no truster",
+			opts: Options{},
+		},
+		{
+			name: "no hooks",
+			opts: Options{NoHooks: true, Trust: askAndTrust, PostHooks: []hooks.Hook{{Run: "touch config.txt"}}},
+		},
+		{
+			name:  "config hooks don't need trust",
+			opts:  Options{PreHooks: []hooks.Hook{{Run: "touch config-pre.txt"}}, PostHooks: []hooks.Hook{{Run: "touch config.txt"}}},
+			files: []string{"config-pre.txt", "config.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asked = nil
+			config := tui.ProjectConfig{
+				ProjectName:  "app",
+				TemplateName: "hooked",
+				License:      "None",
+				OutputDir:    t.TempDir(),
+			}
+			if err := GenerateWithOptions(config, tt.opts); err != nil {
+				t.Fatalf("GenerateWithOptions failed: %v", err)
+			}
+			projectDir := filepath.Join(config.OutputDir, "app")
+
+			for _, name := range []string{"pre.txt", "post.txt", "config-pre.txt", "config.txt"} {
+				_, err := os.Stat(filepath.Join(projectDir, name))
+				if want := slices.Contains(tt.files, name); (err == nil) != want {
+					t.Errorf("%s exists = %v, want %v", name, err == nil, want)
+				}
+			}
+			if tt.asked != (len(asked) > 0) {
+				t.Errorf("asked = %v, want asked %v", asked, tt.asked)
+			}
+		})
+	}
+
+	// The hooks see the project in their environment
+	asked = nil
+	config := tui.ProjectConfig{ProjectName: "app", TemplateName: "hooked", License: "None", OutputDir: t.TempDir()}
+	if err := GenerateWithOptions(config, Options{Trust: askAndTrust}); err != nil {
+		t.Fatal(err)
+	}
+	assertFile(t, filepath.Join(config.OutputDir, "app", "pre.txt"), "app\n")
+	assertFile(t, filepath.Join(config.OutputDir, "app", "post.txt"), "hooked\n")
+	if len(asked) != 1 || !strings.HasPrefix(asked[0], "template hooked (") {
+		t.Errorf("Trust asked about %v, want the custom template", asked)
+	}
+}
+
+func TestGeneratePreHookFailureLeavesNothing(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := tui.ProjectConfig{
+		ProjectName:  "app",
+		TemplateName: "go-lib",
+		License:      "MIT",
+		OutputDir:    filepath.Join(tmpDir, "new"),
+	}
+	opts := Options{PreHooks: []hooks.Hook{{Name: "check", Run: "exit 1"}}}
+	err := GenerateWithOptions(config, opts)
+	if err == nil || !strings.Contains(err.Error(), `hook "check" failed`) {
+		t.Fatalf("GenerateWithOptions error = %v, want the failing hook", err)
+	}
+
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 0 {
+		t.Errorf("failed pre-init hook left debris: %v", entries)
+	}
+}
+
+func TestGeneratePostHookFailure(t *testing.T) {
+	config := tui.ProjectConfig{
+		ProjectName:  "app",
+		TemplateName: "go-lib",
+		License:      "MIT",
+		OutputDir:    t.TempDir(),
+	}
+	opts := Options{PostHooks: []hooks.Hook{
+		{Name: "optional", Run: "exit 1", ContinueOnError: true},
+		{Name: "required", Run: "exit 1"},
+	}}
+	err := GenerateWithOptions(config, opts)
+	if err == nil || !strings.Contains(err.Error(), `project 'app' was created but hook "required" failed`) {
+		t.Fatalf("GenerateWithOptions error = %v, want the required hook reported", err)
+	}
+	if _, err := os.Stat(filepath.Join(config.OutputDir, "app", "go.mod")); err != nil {
+		t.Error("a failing post-init hook removed the project")
+	}
+}
+
+func TestRunHooks(t *testing.T) {
+	loadHookTemplate(t)
+	dir := t.TempDir()
+	config := tui.ProjectConfig{ProjectName: "app", TemplateName: "hooked"}
+
+	if err := RunHooks(dir, config, Options{Trust: trustAll}); err != nil {
+		t.Fatalf("RunHooks failed: %v", err)
+	}
+	assertFile(t, filepath.Join(dir, "pre.txt"), "app\n")
+	assertFile(t, filepath.Join(dir, "post.txt"), "hooked\n")
+	if _, err := os.Stat(filepath.Join(dir, "main.go")); err == nil {
+		t.Error("RunHooks generated files")
+	}
+}
@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/purnama/scaffold/internal/render"
+	"github.com/purnama/scaffold/internal/safefs"
+	"github.com/purnama/scaffold/internal/templates"
+	"github.com/purnama/scaffold/internal/tui"
+)
+
+// processPath renders a file or directory path like file contents, with the
+// same data and functions, e.g. "cmd/{{.ProjectName | kebab}}/main.go", and
+// checks that the result is a valid path inside the project
+func processPath(path string, data TemplateData) (string, error) {
+	rendered := path
+	if strings.Contains(path, "{{") {
+		var err error
+		if rendered, err = render.Execute(path, path, data); err != nil {
+			return "", err
+		}
+	}
+	if err := validatePath(rendered); err != nil {
+		if rendered != path {
+			return "", fmt.Errorf("renders to %q, which %w", rendered, err)
+		}
+		return "", err
+	}
+	return rendered, nil
+}
+
+// validatePath checks that p is a relative, slash-separated path that stays
+// inside the project and only has names valid on Linux, macOS and Windows
+func validatePath(p string) error {
+	switch {
+	case p == "":
+		return fmt.Errorf("is empty")
+	case strings.HasPrefix(p, "/") || isDrive(p):
+		return fmt.Errorf("is absolute")
+	}
+	for _, name := range strings.Split(p, "/") {
+		switch {
+		case name == "":
+			return fmt.Errorf("has an empty segment")
+		case name == "." || name == "..":
+			return fmt.Errorf("has a %s segment", name)
+		}
+		if err := safefs.ValidateName(name); err != nil {
+			return fmt.Errorf("has %q, which %w", name, err)
+		}
+	}
+	return nil
+}
+
+// isDrive reports whether p starts with a Windows drive such as C:
+func isDrive(p string) bool {
+	if len(p) < 2 || p[1] != ':' {
+		return false
+	}
+	c := p[0] | 0x20
+	return c >= 'a' && c <= 'z' && (len(p) == 2 || p[2] == '/')
+}
+
+// renderDirectories renders the directory paths of tmpl
+func renderDirectories(tmpl templates.Template, data TemplateData) ([]string, error) {
+	dirs := make([]string, len(tmpl.Directories))
+	for i, dir := range tmpl.Directories {
+		var err error
+		if dirs[i], err = processPath(dir, data); err != nil {
+			return nil, fmt.Errorf("directory %s: %w", dir, err)
+		}
+	}
+	return dirs, nil
+}
+
+// RenderPaths renders every directory and file path of tmpl for config,
+// including those whose When expression doesn't hold, keyed by the path as
+// the template declares it
+func RenderPaths(tmpl templates.Template, config tui.ProjectConfig) (map[string]string, error) {
+	data, err := templateData(tmpl, config)
+	if err != nil {
+		return nil, err
+	}
+	paths := make(map[string]string, len(tmpl.Directories)+len(tmpl.Files))
+	for _, dir := range tmpl.Directories {
+		if paths[dir], err = processPath(dir, data); err != nil {
+			return nil, fmt.Errorf("directory %s: %w", dir, err)
+		}
+	}
+	for _, f := range tmpl.Files {
+		if paths[f.Path], err = processPath(f.Path, data); err != nil {
+			return nil, fmt.Errorf("file %s: %w", f.Path, err)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,193 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/purnama/scaffold/internal/lockfile"
+	"github.com/purnama/scaffold/internal/templates"
+	"github.com/purnama/scaffold/internal/tui"
+)
+
+func TestValidatePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"main.go", ""},
+		{"cmd/api/main.go", ""},
+		{".github/workflows/ci.yml", ""},
+		{"console/x.conf", ""}, // Only whole names before the extension are reserved
+		{"con.d/x.conf", "reserved name on Windows"},
+		{"", "is empty"},
+		{"/etc/passwd", "is absolute"},
+		{"C:/Windows", "is absolute"},
+		{"cmd//main.go", "has an empty segment"},
+		{"cmd/", "has an empty segment"},
+		{"../main.go", "has a .. segment"},
+		{"cmd/../../main.go", "has a .. segment"},
+		{"./main.go", "has a . segment"},
+		{`cmd\main.go`, "character invalid on Windows"},
+		{"what?.go", "character invalid on Windows"},
+		{"a:b.go", "character invalid on Windows"},
+		{"tab\there.go", "control character"},
+		{"docs./x.md", "can't end with a space or dot"},
+		{"name /x.md", "can't end with a space or dot"},
+		{"aux.go", "reserved name on Windows"},
+		{"internal/COM1/x.go", "reserved name on Windows"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := validatePath(tt.path)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("validatePath failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("validatePath(%q) error = %v, want %q", tt.path, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessPathErrors(t *testing.T) {
+	data := TemplateData{
+		ProjectName: "my-api",
+		Vars:        map[string]any{"empty": "", "escape": "../../etc", "abs": "/tmp/x"},
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"cmd/{{.Vars.empty}}/main.go", `renders to "cmd//main.go", which has an empty segment`},
+		{"{{.Vars.escape}}/passwd", `renders to "../../etc/passwd", which has a .. segment`},
+		{"{{.Vars.abs}}", `renders to "/tmp/x", which is absolute`},
+		{"{{if .Vars.empty}}x{{end}}", `renders to "", which is empty`},
+		{"{{.ProjectName | nosuchfunc}}", `function "nosuchfunc" not defined`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			_, err := processPath(tt.path, data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("processPath(%q) error = %v, want %q", tt.path, err, tt.want)
+			}
+		})
+	}
+}
+
+// loadPathTemplate registers a custom template named "paths" whose paths use
+// the module name, a variable, a function and a conditional
+func loadPathTemplate(t *testing.T) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "paths")
+	os.MkdirAll(filepath.Join(dir, "cmd", "{{.Vars.service | kebab}}"), 0755)
+	os.WriteFile(filepath.Join(dir, "template.json"), []byte(`{
+  "variables": [
+    {"name": "service", "default": "billing"},
+    {"name": "sub", "default": ""}
+  ],
+  "directories": ["internal/{{.Vars.service | snake}}"]
+}`), 0644)
+	os.WriteFile(filepath.Join(dir, "cmd", "{{.Vars.service | kebab}}", "main.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "{{.ModuleName | kebab}}.md"), []byte("# {{.ProjectName}}\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, "{{if .Vars.sub}}{{.Vars.sub}}"), 0755)
+	os.WriteFile(filepath.Join(dir, "{{if .Vars.sub}}{{.Vars.sub}}/{{end}}config.yaml"), []byte("x: 1\n"), 0644)
+
+	if err := templates.LoadCustomTemplates(filepath.Dir(dir)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { templates.LoadCustomTemplates(t.TempDir()) })
+}
+
+func TestGenerateRendersPaths(t *testing.T) {
+	loadPathTemplate(t)
+	config := tui.ProjectConfig{
+		ProjectName:  "app",
+		TemplateName: "paths",
+		ModuleName:   "example.com/team/app",
+		License:      "None",
+		OutputDir:    t.TempDir(),
+		Vars:         map[string]string{"service": "OrderSync"},
+	}
+	if err := GenerateWithOptions(config, Options{}); err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+	projectDir := filepath.Join(config.OutputDir, "app")
+
+	for _, path := range []string{"cmd/order-sync/main.go", "example-com-team-app.md", "config.yaml"} {
+		if _, err := os.Stat(filepath.Join(projectDir, path)); err != nil {
+			t.Errorf("%s missing: %v", path, err)
+		}
+	}
+	if st, err := os.Stat(filepath.Join(projectDir, "internal", "order_sync")); err != nil || !st.IsDir() {
+		t.Errorf("directory internal/order_sync missing: %v", err)
+	}
+
+	lock, err := lockfile.Read(projectDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path := range lock.Files {
+		if strings.Contains(path, "{{") {
+			t.Errorf("lockfile records the template path %s", path)
+		}
+	}
+	if _, ok := lock.Files["cmd/order-sync/main.go"]; !ok {
+		t.Errorf("lockfile doesn't record cmd/order-sync/main.go: %v", lock.Files)
+	}
+
+	// A bad value fails before anything is written
+	config.OutputDir = t.TempDir()
+	config.Vars = map[string]string{"service": "billing", "sub": "../outside"}
+	err = GenerateWithOptions(config, Options{})
+	if err == nil || !strings.Contains(err.Error(), `renders to "../outside/config.yaml", which has a .. segment`) {
+		t.Errorf("GenerateWithOptions error = %v, want the escaping path refused", err)
+	}
+	if entries, _ := os.ReadDir(config.OutputDir); len(entries) != 0 {
+		t.Errorf("refused path left debris: %v", entries)
+	}
+}
+
+func TestRenderPathsCollision(t *testing.T) {
+	tmpl := templates.Template{
+		Name: "clash",
+		Files: []templates.FileTemplate{
+			{Path: "{{.ProjectName}}.go"},
+			{Path: "app.go"},
+		},
+	}
+	_, err := RenderTemplate(tmpl, tui.ProjectConfig{ProjectName: "app"})
+	if err == nil || !strings.Contains(err.Error(), "files {{.ProjectName}}.go and app.go both render to app.go") {
+		t.Errorf("RenderTemplate error = %v, want the collision reported", err)
+	}
+}
+
+func TestRenderPaths(t *testing.T) {
+	loadPathTemplate(t)
+	tmpl, err := templates.GetTemplate("paths")
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths, err := RenderPaths(tmpl, tui.ProjectConfig{ProjectName: "my-project", TemplateName: "paths"})
+	if err != nil {
+		t.Fatalf("RenderPaths failed: %v", err)
+	}
+
+	want := map[string]string{
+		"internal/{{.Vars.service | snake}}":               "internal/billing",
+		"cmd/{{.Vars.service | kebab}}/main.go":            "cmd/billing/main.go",
+		"{{.ModuleName | kebab}}.md":                       "github-com-user-my-project.md",
+		"{{if .Vars.sub}}{{.Vars.sub}}/{{end}}config.yaml": "config.yaml",
+	}
+	for raw, rendered := range want {
+		if paths[raw] != rendered {
+			t.Errorf("%s renders to %q, want %q", raw, paths[raw], rendered)
+		}
+	}
+}
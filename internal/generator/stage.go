@@ -0,0 +1,247 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/purnama/scaffold/internal/conflict"
+	"github.com/purnama/scaffold/internal/safefs"
+)
+
+// stage is a temporary directory next to the project directory where the
+// project is built, so a failed init leaves nothing behind
+type stage struct {
+	dir        string // Staging directory
+	projectDir string // Final location
+	createdDir string // Topmost parent of projectDir created for the stage, if any
+
+	project safefs.Root // Checks every path before it's touched in projectDir
+}
+
+// newStage creates the staging directory for projectDir, creating missing
+// parent directories too. Symbolic links in an existing project directory
+// are only followed with followSymlinks.
+func newStage(projectDir string, followSymlinks bool) (*stage, error) {
+	baseDir := filepath.Dir(projectDir)
+	s := &stage{
+		projectDir: projectDir,
+		createdDir: firstMissing(baseDir),
+		project:    safefs.Root{Dir: projectDir, FollowSymlinks: followSymlinks},
+	}
+
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", baseDir, err)
+	}
+	dir, err := os.MkdirTemp(baseDir, "."+filepath.Base(projectDir)+".scaffold-*")
+	if err != nil {
+		s.discard()
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	s.dir = dir
+
+	// MkdirTemp uses 0700, the project directory should look like any other
+	if err := os.Chmod(dir, 0755); err != nil {
+		s.discard()
+		return nil, err
+	}
+	return s, nil
+}
+
+// mkdir creates a directory in the stage
+func (s *stage) mkdir(rel string) error {
+	return safefs.Root{Dir: s.dir}.MkdirAll(rel, 0755)
+}
+
+// writeFile writes a file to the stage, creating parent directories
+func (s *stage) writeFile(rel string, content []byte, mode fs.FileMode) error {
+	return safefs.Root{Dir: s.dir}.WriteFile(rel, content, mode)
+}
+
+// discard removes the staging directory and any parents created for it
+func (s *stage) discard() {
+	if s.dir != "" {
+		os.RemoveAll(s.dir)
+	}
+	if s.createdDir != "" {
+		os.RemoveAll(s.createdDir)
+	}
+}
+
+// resolvedFile is a staged file that collided with an existing one
+type resolvedFile struct {
+	Path string
+	conflict.Resolution
+}
+
+// resolve applies the conflict mode to every staged file that already exists
+// in the project directory, before anything there is touched. Skipped and
+// unchanged files are dropped from the stage, merged ones rewritten and the
+// previous content of backed up files staged next to them.
+func (s *stage) resolve(mode conflict.Mode, ask conflict.Asker) ([]resolvedFile, error) {
+	var resolved []resolvedFile
+	err := filepath.WalkDir(s.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		target, err := s.project.Path(rel)
+		if err != nil {
+			return err
+		}
+		proposed, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		res, err := conflict.ResolveFile(mode, s.projectDir, rel, proposed, ask)
+		if err != nil {
+			return err
+		}
+		if res.Action == conflict.Created {
+			return nil
+		}
+		resolved = append(resolved, resolvedFile{Path: rel, Resolution: res})
+
+		if !res.Write() {
+			return os.Remove(p)
+		}
+		if res.Backup != "" {
+			existing, err := os.ReadFile(target)
+			if err != nil {
+				return err
+			}
+			info, err := os.Stat(target)
+			if err != nil {
+				return err
+			}
+			if err := s.writeFile(res.Backup, existing, info.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+		return os.WriteFile(p, res.Content, 0644)
+	})
+	return resolved, err
+}
+
+// commit moves the staged project into place. A new project is renamed in a
+// single step. When the project directory already exists, staged files
+// (already passed through resolve) replace existing ones one at a time;
+// replaced files are set aside and restored if any move fails, and files not
+// in the template are kept.
+func (s *stage) commit() error {
+	if _, err := os.Lstat(s.projectDir); errors.Is(err, fs.ErrNotExist) {
+		if err := os.Rename(s.dir, s.projectDir); err != nil {
+			return fmt.Errorf("failed to move project into place: %w", err)
+		}
+		return nil
+	}
+	if err := s.merge(); err != nil {
+		return err
+	}
+	return os.RemoveAll(s.dir)
+}
+
+// merge moves staged files into the existing project directory
+func (s *stage) merge() (err error) {
+	backupDir, err := os.MkdirTemp(filepath.Dir(s.projectDir), "."+filepath.Base(s.projectDir)+".backup-*")
+	if err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	var createdDirs, moved, replaced []string
+	defer func() {
+		if err == nil {
+			os.RemoveAll(backupDir)
+			return
+		}
+
+		// Undo in reverse order: new files, then originals, then new dirs
+		for i := len(moved) - 1; i >= 0; i-- {
+			os.Remove(filepath.Join(s.projectDir, moved[i]))
+		}
+		restored := true
+		for i := len(replaced) - 1; i >= 0; i-- {
+			if os.Rename(filepath.Join(backupDir, replaced[i]), filepath.Join(s.projectDir, replaced[i])) != nil {
+				restored = false
+			}
+		}
+		for i := len(createdDirs) - 1; i >= 0; i-- {
+			os.Remove(filepath.Join(s.projectDir, createdDirs[i]))
+		}
+		if restored {
+			os.RemoveAll(backupDir)
+		} else {
+			err = fmt.Errorf("%w (some original files could not be restored, they are in %s)", err, backupDir)
+		}
+	}()
+
+	return filepath.WalkDir(s.dir, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		target, err := s.project.Path(rel)
+		if err != nil {
+			return err
+		}
+		info, statErr := os.Lstat(target)
+		exists := statErr == nil
+
+		if d.IsDir() {
+			if !exists {
+				if err := os.Mkdir(target, 0755); err != nil {
+					return fmt.Errorf("failed to create directory %s: %w", rel, err)
+				}
+				createdDirs = append(createdDirs, rel)
+			} else if !info.IsDir() {
+				return fmt.Errorf("cannot create directory %s: a file with that name exists", rel)
+			}
+			return nil
+		}
+
+		if exists {
+			if info.IsDir() {
+				return fmt.Errorf("cannot write %s: a directory with that name exists", rel)
+			}
+			backup := filepath.Join(backupDir, rel)
+			if err := os.MkdirAll(filepath.Dir(backup), 0755); err != nil {
+				return err
+			}
+			if err := os.Rename(target, backup); err != nil {
+				return fmt.Errorf("failed to replace %s: %w", rel, err)
+			}
+			replaced = append(replaced, rel)
+		}
+		if err := os.Rename(p, target); err != nil {
+			return fmt.Errorf("failed to write %s: %w", rel, err)
+		}
+		moved = append(moved, rel)
+		return nil
+	})
+}
+
+// firstMissing returns the topmost directory of dir's ancestry, dir
+// included, that doesn't exist yet, or "" when dir exists
+func firstMissing(dir string) string {
+	missing := ""
+	for {
+		if _, err := os.Lstat(dir); err == nil {
+			return missing
+		}
+		missing = dir
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return missing
+		}
+		dir = parent
+	}
+}
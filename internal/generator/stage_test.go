@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// stageFiles creates a stage for projectDir holding files
+func stageFiles(t *testing.T, projectDir string, files map[string]string) *stage {
+	t.Helper()
+	st, err := newStage(projectDir, false)
+	if err != nil {
+		t.Fatalf("newStage failed: %v", err)
+	}
+	for p, content := range files {
+		if err := st.writeFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return st
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// assertOnlyEntries fails when dir holds anything but names, e.g. leftover
+// staging or backup directories
+func assertOnlyEntries(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != len(names) {
+		var got []string
+		for _, e := range entries {
+			got = append(got, e.Name())
+		}
+		t.Errorf("%s contains %v, want %v", dir, got, names)
+	}
+}
+
+func TestStageCommitNewProject(t *testing.T) {
+	base := t.TempDir()
+	projectDir := filepath.Join(base, "app")
+	st := stageFiles(t, projectDir, map[string]string{"main.go": "package main\n"})
+
+	if err := st.commit(); err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+	if got := readFile(t, filepath.Join(projectDir, "main.go")); got != "package main\n" {
+		t.Errorf("main.go = %q", got)
+	}
+	if info, _ := os.Stat(projectDir); info.Mode().Perm() != 0755 {
+		t.Errorf("project dir mode = %o, want 755", info.Mode().Perm())
+	}
+	assertOnlyEntries(t, base, "app")
+}
+
+func TestStageCommitIntoExisting(t *testing.T) {
+	base := t.TempDir()
+	projectDir := filepath.Join(base, "app")
+	os.MkdirAll(filepath.Join(projectDir, "notes"), 0755)
+	os.WriteFile(filepath.Join(projectDir, "main.go"), []byte("old"), 0644)
+	os.WriteFile(filepath.Join(projectDir, "notes", "todo.txt"), []byte("mine"), 0644)
+
+	st := stageFiles(t, projectDir, map[string]string{
+		"main.go":         "new",
+		"internal/app.go": "package internal",
+	})
+	if err := st.commit(); err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+
+	if got := readFile(t, filepath.Join(projectDir, "main.go")); got != "new" {
+		t.Errorf("main.go = %q, want the staged content", got)
+	}
+	if got := readFile(t, filepath.Join(projectDir, "internal", "app.go")); got != "package internal" {
+		t.Errorf("internal/app.go = %q", got)
+	}
+	if got := readFile(t, filepath.Join(projectDir, "notes", "todo.txt")); got != "mine" {
+		t.Errorf("files outside the template must be kept, got %q", got)
+	}
+	assertOnlyEntries(t, base, "app")
+}
+
+func TestStageCommitRollsBack(t *testing.T) {
+	base := t.TempDir()
+	projectDir := filepath.Join(base, "app")
+	os.MkdirAll(projectDir, 0755)
+	os.WriteFile(filepath.Join(projectDir, "a.go"), []byte("original"), 0644)
+	// A file where the template wants a directory makes the commit fail
+	// after a.go has been replaced
+	os.WriteFile(filepath.Join(projectDir, "pkg"), []byte("in the way"), 0644)
+
+	st := stageFiles(t, projectDir, map[string]string{
+		"a.go":       "generated",
+		"b/new.go":   "generated",
+		"pkg/lib.go": "generated",
+	})
+	if err := st.commit(); err == nil {
+		t.Fatal("expected commit to fail")
+	}
+	st.discard()
+
+	if got := readFile(t, filepath.Join(projectDir, "a.go")); got != "original" {
+		t.Errorf("a.go = %q, want the original restored", got)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, "b")); !os.IsNotExist(err) {
+		t.Error("directories created during the failed commit should be removed")
+	}
+	if got := readFile(t, filepath.Join(projectDir, "pkg")); got != "in the way" {
+		t.Errorf("pkg = %q", got)
+	}
+	assertOnlyEntries(t, base, "app")
+}
+
+func TestStageDiscardRemovesCreatedParents(t *testing.T) {
+	base := t.TempDir()
+	st := stageFiles(t, filepath.Join(base, "services", "billing", "app"), map[string]string{"main.go": "x"})
+
+	st.discard()
+	assertOnlyEntries(t, base)
+}
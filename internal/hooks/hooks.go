@@ -0,0 +1,278 @@
+// Package hooks runs the commands templates and config declare around
+// project generation
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout limits hooks that don't set their own timeout
+const DefaultTimeout = 10 * time.Minute
+
+// Hook is a command run in the project directory. Exactly one of Run and
+// Command must be set.
+type Hook struct {
+	Name            string            `json:"name,omitempty"`              // Shown in output, defaults to the command line
+	Run             string            `json:"run,omitempty"`               // Shell command, run with sh -c
+	Command         []string          `json:"command,omitempty"`           // Program and arguments, run without a shell
+	Dir             string            `json:"dir,omitempty"`               // Relative to the project directory
+	Env             map[string]string `json:"env,omitempty"`               // Added to scaffold's environment
+	Timeout         string            `json:"timeout,omitempty"`           // Such as "30s" or "2m", DefaultTimeout when empty
+	Requires        []string          `json:"requires,omitempty"`          // Programs that must be installed, the hook is skipped otherwise
+	ContinueOnError bool              `json:"continue_on_error,omitempty"` // A failure is reported but doesn't stop generation
+}
+
+// String returns the hook's name or command line
+func (h Hook) String() string {
+	switch {
+	case h.Name != "":
+		return h.Name
+	case h.Run != "":
+		return h.Run
+	default:
+		return strings.Join(h.Command, " ")
+	}
+}
+
+// Validate checks the hook's declaration
+func (h Hook) Validate() error {
+	if (h.Run == "") == (len(h.Command) == 0) {
+		return fmt.Errorf("hook %q: set exactly one of run and command", h)
+	}
+	if h.Dir != "" {
+		if filepath.IsAbs(h.Dir) || strings.HasPrefix(h.Dir, "/") {
+			return fmt.Errorf("hook %q: dir %s must be relative to the project", h, h.Dir)
+		}
+		if clean := filepath.ToSlash(filepath.Clean(filepath.FromSlash(h.Dir))); clean == ".." || strings.HasPrefix(clean, "../") {
+			return fmt.Errorf("hook %q: dir %s is outside the project", h, h.Dir)
+		}
+	}
+	if _, err := h.timeout(); err != nil {
+		return fmt.Errorf("hook %q: %w", h, err)
+	}
+	return nil
+}
+
+func (h Hook) timeout() (time.Duration, error) {
+	if h.Timeout == "" {
+		return DefaultTimeout, nil
+	}
+	d, err := time.ParseDuration(h.Timeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q", h.Timeout)
+	}
+	return d, nil
+}
+
+// required returns the programs the hook needs: its Requires and the
+// program it runs
+func (h Hook) required() []string {
+	req := h.Requires
+	switch {
+	case len(h.Command) > 0:
+		req = append([]string{h.Command[0]}, req...)
+	case h.Run != "":
+		req = append([]string{"sh"}, req...)
+	}
+	return req
+}
+
+// Validate checks every hook of a list
+func Validate(hooks []Hook) error {
+	var errs []error
+	for _, h := range hooks {
+		errs = append(errs, h.Validate())
+	}
+	return errors.Join(errs...)
+}
+
+// Runner runs hooks in a project directory
+type Runner struct {
+	Dir      string       // Project directory hooks run in
+	Out      io.Writer    // Receives progress and the hooks' output, prefixed with their name
+	Env      []string     // Extra KEY=value pairs for every hook, on top of the environment
+	Progress func(Update) // Receives progress and output instead of Out when set
+}
+
+// Status is what an Update reports about a hook
+type Status int
+
+const (
+	Started  Status = iota // The hook is about to run
+	Output                 // The hook printed Line
+	Finished               // The hook ran, Err is its failure
+	Skipped                // The hook didn't run, Reason says why
+)
+
+// Update is progress of a hook, sent to Runner.Progress
+type Update struct {
+	Hook   Hook
+	Status Status
+	Line   string // Output line without its newline
+	Reason string // Why the hook was skipped
+	Err    error  // Failure of a hook that ran
+}
+
+// update sends u to r.Progress, or writes it to r.Out
+func (r Runner) update(u Update) {
+	if r.Progress != nil {
+		r.Progress(u)
+		return
+	}
+	if r.Out == nil {
+		return
+	}
+	switch u.Status {
+	case Started:
+		fmt.Fprintf(r.Out, "   $ %s\n", u.Hook)
+	case Output:
+		fmt.Fprintf(r.Out, "   │ %s\n", u.Line)
+	case Finished:
+		switch {
+		case u.Err == nil:
+			fmt.Fprintf(r.Out, "   ✓ %s\n", u.Hook)
+		case u.Hook.ContinueOnError:
+			fmt.Fprintf(r.Out, "   ⚠ %s failed: %v\n", u.Hook, u.Err)
+		}
+	case Skipped:
+		fmt.Fprintf(r.Out, "   - %s skipped, %s\n", u.Hook, u.Reason)
+	}
+}
+
+// Result is what happened to one hook
+type Result struct {
+	Hook    Hook
+	Skipped string // Why the hook didn't run, e.g. a missing program
+	Err     error  // Failure of a hook that ran
+}
+
+// Run runs hooks in order. A missing required program skips the hook. A
+// failing hook stops the run with an error, unless it continues on error.
+func (r Runner) Run(hooks []Hook) ([]Result, error) {
+	var results []Result
+	for _, h := range hooks {
+		res := Result{Hook: h}
+		if missing := missingPrograms(filepath.Join(r.Dir, filepath.FromSlash(h.Dir)), h.required()); len(missing) > 0 {
+			res.Skipped = strings.Join(missing, ", ") + " not installed"
+			r.update(Update{Hook: h, Status: Skipped, Reason: res.Skipped})
+			results = append(results, res)
+			continue
+		}
+
+		r.update(Update{Hook: h, Status: Started})
+		res.Err = r.run(h)
+		results = append(results, res)
+		r.update(Update{Hook: h, Status: Finished, Err: res.Err})
+		if res.Err != nil && !h.ContinueOnError {
+			return results, fmt.Errorf("hook %q failed: %w", h, res.Err)
+		}
+	}
+	return results, nil
+}
+
+func (r Runner) run(h Hook) error {
+	if err := h.Validate(); err != nil {
+		return err
+	}
+	timeout, _ := h.timeout()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if h.Run != "" {
+		cmd = exec.CommandContext(ctx, "sh", "-c", h.Run)
+	} else {
+		cmd = exec.CommandContext(ctx, h.Command[0], h.Command[1:]...)
+	}
+	cmd.Dir = filepath.Join(r.Dir, filepath.FromSlash(h.Dir))
+	cmd.Env = append(os.Environ(), r.Env...)
+	for _, k := range sortedKeys(h.Env) {
+		cmd.Env = append(cmd.Env, k+"="+h.Env[k])
+	}
+	// Children of sh may keep the pipes open after a timeout
+	cmd.WaitDelay = time.Second
+
+	out := &lineWriter{line: func(line string) {
+		r.update(Update{Hook: h, Status: Output, Line: line})
+	}}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err := cmd.Run()
+	out.Flush()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
+
+// missingPrograms returns the programs that can't be run from dir. Bare
+// names are looked up in PATH, paths such as ./scripts/setup.sh are
+// relative to dir, like the command that runs them.
+func missingPrograms(dir string, programs []string) []string {
+	var missing []string
+	for _, p := range programs {
+		path := p
+		if strings.ContainsRune(p, '/') || strings.ContainsRune(p, filepath.Separator) {
+			if path = filepath.FromSlash(p); !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+		}
+		if _, err := exec.LookPath(path); err != nil {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// lineWriter passes complete lines to line, so the output of a hook can
+// stand out from scaffold's own. It is shared by stdout and stderr.
+type lineWriter struct {
+	mu   sync.Mutex
+	line func(string)
+	buf  []byte
+}
+
+func (l *lineWriter) Write(b []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buf = append(l.buf, b...)
+	for {
+		i := bytes.IndexByte(l.buf, '\n')
+		if i < 0 {
+			break
+		}
+		l.line(string(bytes.TrimRight(l.buf[:i], "\r")))
+		l.buf = l.buf[i+1:]
+	}
+	return len(b), nil
+}
+
+// Flush passes on a last line that didn't end with a newline
+func (l *lineWriter) Flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.buf) > 0 {
+		l.line(string(l.buf))
+		l.buf = nil
+	}
+}
@@ -0,0 +1,201 @@
+package hooks
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		hook Hook
+		want string
+	}{
+		{"run", Hook{Run: "go mod tidy"}, ""},
+		{"command", Hook{Command: []string{"go", "mod", "tidy"}, Dir: "api", Timeout: "30s"}, ""},
+		{"nested dir", Hook{Run: "true", Dir: "web/../api"}, ""},
+		{"neither", Hook{Name: "empty"}, "set exactly one of run and command"},
+		{"both", Hook{Run: "true", Command: []string{"true"}}, "set exactly one of run and command"},
+		{"absolute dir", Hook{Run: "true", Dir: "/tmp"}, "must be relative to the project"},
+		{"outside dir", Hook{Run: "true", Dir: "web/../.."}, "is outside the project"},
+		{"bad timeout", Hook{Run: "true", Timeout: "soon"}, `invalid timeout "soon"`},
+		{"negative timeout", Hook{Run: "true", Timeout: "-1s"}, `invalid timeout "-1s"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hook.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		hook Hook
+		want string
+	}{
+		{Hook{Name: "install", Run: "bun install"}, "install"},
+		{Hook{Run: "bun install"}, "bun install"},
+		{Hook{Command: []string{"go", "mod", "tidy"}}, "go mod tidy"},
+	}
+	for _, tt := range tests {
+		if got := tt.hook.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "web"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	r := Runner{Dir: dir, Out: &out, Env: []string{"SCAFFOLD_PROJECT_NAME=demo"}}
+	results, err := r.Run([]Hook{
+		{Name: "env", Run: `echo "$SCAFFOLD_PROJECT_NAME $GREETING" > env.txt`, Env: map[string]string{"GREETING": "hi"}},
+		{Command: []string{"touch", "here"}, Dir: "web"},
+		{Name: "missing", Command: []string{"scaffold-no-such-program"}},
+		{Name: "needs", Run: "true", Requires: []string{"scaffold-no-such-tool"}},
+		{Name: "flaky", Run: "echo oops >&2; exit 3", ContinueOnError: true},
+		{Name: "output", Run: "printf 'one\\ntwo'"},
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if got, _ := os.ReadFile(filepath.Join(dir, "env.txt")); string(got) != "demo hi\n" {
+		t.Errorf("env.txt = %q, want the runner and hook env", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "web", "here")); err != nil {
+		t.Errorf("hook didn't run in its dir: %v", err)
+	}
+
+	if len(results) != 6 {
+		t.Fatalf("got %d results, want 6", len(results))
+	}
+	if got := results[2].Skipped; got != "scaffold-no-such-program not installed" {
+		t.Errorf("missing program Skipped = %q", got)
+	}
+	if got := results[3].Skipped; got != "scaffold-no-such-tool not installed" {
+		t.Errorf("missing requirement Skipped = %q", got)
+	}
+	if results[4].Err == nil {
+		t.Error("flaky hook reported no error")
+	}
+
+	for _, want := range []string{
+		"   $ env\n",
+		"   ✓ env\n",
+		"   - missing skipped, scaffold-no-such-program not installed\n",
+		"   │ oops\n",
+		"   ⚠ flaky failed: exit status 3\n",
+		"   │ one\n   │ two\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunStopsOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	var out bytes.Buffer
+	results, err := Runner{Dir: dir, Out: &out}.Run([]Hook{
+		{Name: "fail", Run: "exit 1"},
+		{Name: "after", Run: "touch after"},
+	})
+	if err == nil || !strings.Contains(err.Error(), `hook "fail" failed`) {
+		t.Errorf("Run error = %v, want the failing hook named", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("got %d results, want the run to stop after the failure", len(results))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "after")); err == nil {
+		t.Error("hook after the failure ran")
+	}
+}
+
+func TestRunRelativeScript(t *testing.T) {
+	// The runner works from the parent directory, scripts are relative to
+	// the hook's own directory
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "app", "scripts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app", "scripts", "setup.sh"), []byte("#!/bin/sh\ntouch done\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	results, err := Runner{Dir: dir, Out: &out}.Run([]Hook{
+		{Name: "setup", Command: []string{"./scripts/setup.sh"}, Dir: "app"},
+		{Name: "missing", Command: []string{"./scripts/nope.sh"}, Dir: "app"},
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v\n%s", err, out.String())
+	}
+	if results[0].Skipped != "" || results[0].Err != nil {
+		t.Errorf("setup = %+v, want it run", results[0])
+	}
+	if _, err := os.Stat(filepath.Join(dir, "app", "done")); err != nil {
+		t.Errorf("setup.sh didn't run in app: %v", err)
+	}
+	if got := results[1].Skipped; got != "./scripts/nope.sh not installed" {
+		t.Errorf("missing script Skipped = %q", got)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	var out bytes.Buffer
+	_, err := Runner{Dir: t.TempDir(), Out: &out}.Run([]Hook{{Name: "slow", Run: "sleep 5", Timeout: "100ms"}})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("Run error = %v, want a timeout", err)
+	}
+}
+
+func TestRunProgress(t *testing.T) {
+	var out bytes.Buffer
+	var got []string
+	r := Runner{Dir: t.TempDir(), Out: &out, Progress: func(u Update) {
+		got = append(got, fmt.Sprintf("%d %s %q %q %v", u.Status, u.Hook, u.Line, u.Reason, u.Err))
+	}}
+	_, err := r.Run([]Hook{
+		{Name: "output", Run: "echo one; echo two >&2"},
+		{Name: "needs", Run: "true", Requires: []string{"scaffold-no-such-tool"}},
+		{Name: "fail", Run: "exit 2"},
+	})
+	if err == nil {
+		t.Fatal("Run succeeded, want the failing hook's error")
+	}
+
+	want := []string{
+		`0 output "" "" <nil>`,
+		`1 output "one" "" <nil>`,
+		`1 output "two" "" <nil>`,
+		`2 output "" "" <nil>`,
+		`3 needs "" "scaffold-no-such-tool not installed" <nil>`,
+		`0 fail "" "" <nil>`,
+		`2 fail "" "" exit status 2`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("updates:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if out.Len() > 0 {
+		t.Errorf("Out got %q, want nothing when Progress is set", out.String())
+	}
+}
@@ -0,0 +1,171 @@
+// Package lockfile records how a project was generated in .scaffold.json
+package lockfile
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// FileName is the lockfile at the root of a generated project
+const FileName = ".scaffold.json"
+
+// FormatVersion is the version of the lockfile layout
+const FormatVersion = 1
+
+// ScaffoldVersion is the scaffold release recorded in lockfiles, set by main
+var ScaffoldVersion = "dev"
+
+// Lock is the content of .scaffold.json
+type Lock struct {
+	Format          int               `json:"format"`
+	ScaffoldVersion string            `json:"scaffold_version"` // Release that generated the project
+	Template        string            `json:"template"`
+	TemplateVersion string            `json:"template_version,omitempty"`
+	ProjectName     string            `json:"project_name"`
+	Module          string            `json:"module"`
+	License         string            `json:"license,omitempty"`
+	Author          string            `json:"author,omitempty"`
+	Docker          bool              `json:"docker,omitempty"`
+	Vars            map[string]string `json:"vars,omitempty"` // Every template variable, defaults included
+	Components      []Component       `json:"components,omitempty"`
+	Files           map[string]string `json:"files"` // Slash-separated path to the Hash of the generated content
+	GeneratedAt     time.Time         `json:"generated_at"`
+	UpdatedAt       time.Time         `json:"updated_at,omitzero"`
+}
+
+// Component is a component added with scaffold add
+type Component struct {
+	Name            string    `json:"name"`
+	ScaffoldVersion string    `json:"scaffold_version"`
+	Files           []string  `json:"files"`
+	AddedAt         time.Time `json:"added_at"`
+}
+
+// New returns a lock for a project generated now by this scaffold release
+func New() *Lock {
+	return &Lock{
+		Format:          FormatVersion,
+		ScaffoldVersion: ScaffoldVersion,
+		Files:           map[string]string{},
+		GeneratedAt:     time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// Hash returns the content hash recorded for a file
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Path returns the lockfile location for a project directory
+func Path(dir string) string {
+	return filepath.Join(dir, FileName)
+}
+
+// Read loads the lockfile of the project in dir. The error wraps
+// fs.ErrNotExist when the project has none.
+func Read(dir string) (*Lock, error) {
+	data, err := os.ReadFile(Path(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	var l Lock
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", FileName, err)
+	}
+	if l.Format > FormatVersion {
+		return nil, fmt.Errorf("%s was written by a newer scaffold (format %d), please upgrade", FileName, l.Format)
+	}
+	if l.Files == nil {
+		l.Files = map[string]string{}
+	}
+	if err := l.checkPaths(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", FileName, err)
+	}
+	return &l, nil
+}
+
+// checkPaths refuses file paths that leave the project, such as
+// "../../etc/passwd" in a tampered lockfile
+func (l *Lock) checkPaths() error {
+	paths := slices.Collect(maps.Keys(l.Files))
+	for _, c := range l.Components {
+		paths = append(paths, c.Files...)
+	}
+	for _, p := range paths {
+		if !filepath.IsLocal(filepath.FromSlash(p)) {
+			return fmt.Errorf("file %q is outside the project", p)
+		}
+	}
+	return nil
+}
+
+// Marshal returns the lockfile content, indented and ending with a newline
+func (l *Lock) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(l); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Write saves the lockfile into dir
+func Write(dir string, l *Lock) error {
+	data, err := l.Marshal()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(Path(dir), data, 0644)
+}
+
+// SetFile records the generated content of a project-relative path
+func (l *Lock) SetFile(path string, content []byte) {
+	l.Files[filepath.ToSlash(path)] = Hash(content)
+}
+
+// AddComponent records a component, replacing an earlier entry with the
+// same name
+func (l *Lock) AddComponent(name string, files []string) {
+	l.Touch()
+	c := Component{
+		Name:            name,
+		ScaffoldVersion: ScaffoldVersion,
+		Files:           files,
+		AddedAt:         l.UpdatedAt,
+	}
+	l.Components = slices.DeleteFunc(l.Components, func(c Component) bool { return c.Name == name })
+	l.Components = append(l.Components, c)
+}
+
+// Touch marks the lock as updated now
+func (l *Lock) Touch() {
+	l.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+}
+
+// ComponentFiles maps the files of every recorded component to its name
+func (l *Lock) ComponentFiles() map[string]string {
+	owners := map[string]string{}
+	for _, c := range l.Components {
+		for _, f := range c.Files {
+			owners[f] = c.Name
+		}
+	}
+	return owners
+}
+
+// HasComponent reports whether the named component was added
+func (l *Lock) HasComponent(name string) bool {
+	return slices.ContainsFunc(l.Components, func(c Component) bool { return c.Name == name })
+}
@@ -0,0 +1,91 @@
+package lockfile
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReadWrite(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Read(dir); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Read() without a lockfile error = %v, want fs.ErrNotExist", err)
+	}
+
+	l := New()
+	l.Template = "go-api"
+	l.TemplateVersion = "1.0.0"
+	l.ProjectName = "billing"
+	l.Module = "example.com/billing"
+	l.Vars = map[string]string{"port": "9090"}
+	l.SetFile("cmd/api/main.go", []byte("package main\n"))
+	if err := Write(dir, l); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	got, err := Read(dir)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, l) {
+		t.Errorf("Read() = %+v, want %+v", got, l)
+	}
+	if got.Files["cmd/api/main.go"] != Hash([]byte("package main\n")) {
+		t.Errorf("file hash = %q", got.Files["cmd/api/main.go"])
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"invalid json", "{"},
+		{"newer format", `{"format": 99, "files": {}}`},
+		{"file outside", `{"format": 1, "files": {"../../etc/passwd": "sha256:00"}}`},
+		{"absolute file", `{"format": 1, "files": {"/etc/passwd": "sha256:00"}}`},
+		{"component file outside", `{"format": 1, "files": {}, "components": [{"name": "x", "files": ["../secret"]}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			os.WriteFile(Path(dir), []byte(tt.content), 0644)
+			if _, err := Read(dir); err == nil {
+				t.Error("Read() should fail")
+			}
+		})
+	}
+}
+
+func TestAddComponent(t *testing.T) {
+	l := New()
+	l.AddComponent("makefile", []string{"Makefile"})
+	l.AddComponent("dockerfile", []string{"Dockerfile", ".dockerignore"})
+	l.AddComponent("makefile", []string{"Makefile"})
+
+	var names []string
+	for _, c := range l.Components {
+		names = append(names, c.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"dockerfile", "makefile"}) {
+		t.Errorf("components = %v, want the re-added one last and no duplicates", names)
+	}
+	if !l.HasComponent("dockerfile") || l.HasComponent("readme") {
+		t.Error("HasComponent() is wrong")
+	}
+	if l.UpdatedAt.IsZero() {
+		t.Error("UpdatedAt should be set")
+	}
+}
+
+func TestHash(t *testing.T) {
+	if Hash([]byte("a")) == Hash([]byte("b")) {
+		t.Error("different contents should hash differently")
+	}
+	if got := Hash(nil); got != "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("Hash(nil) = %q", got)
+	}
+}
@@ -0,0 +1,262 @@
+// Package progress shows generator events to people, with or without
+// colors and emoji, or to programs as JSON
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/purnama/scaffold/internal/conflict"
+	"github.com/purnama/scaffold/internal/generator"
+)
+
+// Format is how events are written
+type Format string
+
+const (
+	Pretty Format = "pretty" // Emoji and colors, for a terminal
+	Plain  Format = "plain"  // ASCII only, for logs and NO_COLOR
+	JSON   Format = "json"   // One JSON object per event and line
+)
+
+// Formats lists the formats ParseFormat accepts
+var Formats = []Format{Pretty, Plain, JSON}
+
+// ParseFormat returns the format named s
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q, use pretty, plain or json", s)
+}
+
+// Level is how much the text formats show
+type Level int
+
+const (
+	Quiet   Level = iota - 1 // Only warnings and failures
+	Normal                   // Steps, files, hooks and their output
+	Verbose                  // Also unchanged files, modes and the commands scaffold runs
+)
+
+// New returns a reporter writing events to out in format. The text formats
+// show as much as level allows and write warnings and failures to errOut.
+// JSON writes every event to out, whatever the level.
+func New(format Format, level Level, out, errOut io.Writer) generator.Reporter {
+	if format == JSON {
+		return &jsonReporter{enc: json.NewEncoder(out)}
+	}
+	return &textReporter{out: out, errOut: errOut, level: level, plain: format == Plain}
+}
+
+// jsonReporter writes events as newline-delimited JSON
+type jsonReporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (r *jsonReporter) Report(e generator.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.enc.Encode(e)
+}
+
+var (
+	stepStyle    = lipgloss.NewStyle().Bold(true)
+	successStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#10B981"))
+	warnStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#F59E0B"))
+	dimStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#6B7280"))
+)
+
+// stepTitles announce the steps of generation
+var stepTitles = map[generator.Step]struct{ icon, title string }{
+	generator.StepDirectories:  {"📁", "Creating directories..."},
+	generator.StepFiles:        {"📄", "Creating files..."},
+	generator.StepGoMod:        {"📦", "Initializing Go module..."},
+	generator.StepDockerfile:   {"🐳", "Adding Dockerfile..."},
+	generator.StepLicense:      {"📜", "Adding license..."},
+	generator.StepPreInit:      {"🪝", "Running pre-init hooks..."},
+	generator.StepConflicts:    {"⚠️ ", "Directory exists, resolving conflicts (%s)..."},
+	generator.StepLockfile:     {"🔒", "Recording template and file hashes..."},
+	generator.StepGit:          {"🔧", "Initializing git repository..."},
+	generator.StepPostInit:     {"🪝", "Running post-init hooks..."},
+	generator.StepProfileHooks: {"🪝", "Running profile hooks..."},
+}
+
+// textReporter writes events as lines for people
+type textReporter struct {
+	mu     sync.Mutex
+	out    io.Writer
+	errOut io.Writer
+	level  Level
+	plain  bool
+}
+
+// icon returns an emoji followed by a space, or nothing in plain text
+func (r *textReporter) icon(emoji string) string {
+	if r.plain {
+		return ""
+	}
+	return emoji + " "
+}
+
+// mark returns the pretty symbol, or its ASCII replacement in plain text
+func (r *textReporter) mark(pretty, ascii string, style lipgloss.Style) string {
+	if r.plain {
+		return ascii
+	}
+	return style.Render(pretty)
+}
+
+func (r *textReporter) println(w io.Writer, format string, args ...any) {
+	fmt.Fprintf(w, format+"\n", args...)
+}
+
+func (r *textReporter) Report(e generator.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Warnings and failures show at every level
+	switch {
+	case e.Kind == generator.Warning:
+		r.println(r.errOut, "   %s %s", r.mark("⚠", "!", warnStyle), e.Message)
+		return
+	case e.Kind == generator.HookFinished && e.Err != nil:
+		r.println(r.errOut, "   %s %s failed: %v", r.mark("⚠", "!", warnStyle), e.Hook, e.Err)
+		return
+	case r.level <= Quiet:
+		return
+	}
+
+	if e.DryRun {
+		r.preview(e)
+		return
+	}
+
+	ok := r.mark("✓", "+", successStyle)
+	switch e.Kind {
+	case generator.ProjectStarted:
+		r.println(r.out, "\n%sCreating project: %s", r.icon("🚀"), e.Project)
+	case generator.StepStarted:
+		step := stepTitles[e.Step]
+		title := step.title
+		if strings.Contains(title, "%s") {
+			title = fmt.Sprintf(title, e.Message)
+		}
+		r.println(r.out, "%s%s", r.icon(step.icon), r.style(stepStyle, title))
+	case generator.DirCreated:
+		r.println(r.out, "   %s %s/", ok, e.Path)
+	case generator.FileWritten:
+		r.println(r.out, "   %s %s", ok, r.fileDetails(e))
+	case generator.FileResolved:
+		r.resolution(e)
+	case generator.DependencyAdded:
+		r.println(r.out, "   %s require %s %s", ok, e.Module, e.Version)
+	case generator.CommandRun:
+		r.println(r.out, "   %s %s", ok, e.Command)
+	case generator.HookStarted:
+		r.println(r.out, "   $ %s", e.Hook)
+	case generator.HookOutput:
+		r.println(r.out, "   %s %s", r.mark("│", "|", dimStyle), e.Message)
+	case generator.HookFinished:
+		r.println(r.out, "   %s %s", ok, e.Hook)
+	case generator.HookSkipped:
+		r.println(r.out, "   - %s skipped, %s", e.Hook, e.Reason)
+	case generator.ComponentStarted:
+		r.println(r.out, "\n%sAdding component: %s", r.icon("🧩"), r.style(stepStyle, e.Component))
+		if e.Message != "" {
+			r.println(r.out, "   %s", r.style(dimStyle, e.Message))
+		}
+	case generator.Info:
+		r.println(r.out, "\n%s", e.Message)
+	case generator.Debug:
+		if r.level >= Verbose {
+			r.println(r.out, "   %s", r.style(dimStyle, e.Message))
+		}
+	case generator.ProjectCreated:
+		r.println(r.out, "\n%s%s", r.icon("✅"), r.style(successStyle, fmt.Sprintf("Project '%s' created successfully!", e.Project)))
+		r.println(r.out, "\nNext steps:")
+		r.println(r.out, "   cd %s", e.Dir)
+		for _, step := range e.NextSteps {
+			r.println(r.out, "   %s", step)
+		}
+	}
+}
+
+// preview writes an event of a dry run
+func (r *textReporter) preview(e generator.Event) {
+	switch e.Kind {
+	case generator.ProjectStarted:
+		r.println(r.out, "\n%sDRY RUN - Preview of what will be created:", r.icon("🔍"))
+		r.println(r.out, "\n%sProject: %s/", r.icon("📦"), e.Dir)
+	case generator.DirCreated:
+		r.println(r.out, "   %s%s/", r.icon("📁"), e.Path)
+	case generator.FileWritten:
+		r.println(r.out, "   %s%s", r.icon("📄"), r.fileDetails(e))
+	case generator.DependencyAdded:
+		r.println(r.out, "      require %s %s", e.Module, e.Version)
+	case generator.CommandRun:
+		r.println(r.out, "   %s%s", r.icon("📦"), e.Command)
+	case generator.HookStarted:
+		line := fmt.Sprintf("%s: %s", e.Stage, e.Hook)
+		if e.Message != "" {
+			line += " (" + e.Message + ")"
+		}
+		r.println(r.out, "   %s%s", r.icon("🪝"), line)
+	case generator.ComponentStarted:
+		r.println(r.out, "   %scomponent: %s", r.icon("🧩"), e.Component)
+	case generator.ProjectCreated:
+		r.println(r.out, "\n%sRemove --dry-run to create the project", r.icon("💡"))
+	}
+}
+
+// fileDetails returns the path of a written file with what's worth knowing
+// about it: the license it holds, whether it's executable and, when
+// verbose, its mode
+func (r *textReporter) fileDetails(e generator.Event) string {
+	var details []string
+	if e.Message != "" {
+		details = append(details, e.Message)
+	}
+	switch {
+	case r.level >= Verbose && e.Mode != 0:
+		details = append(details, fmt.Sprintf("mode %04o", e.Mode.Perm()))
+	case e.Mode&0111 != 0:
+		details = append(details, "executable")
+	}
+	if len(details) == 0 {
+		return e.Path
+	}
+	return fmt.Sprintf("%s (%s)", e.Path, strings.Join(details, ", "))
+}
+
+// resolution writes what happened to a file that already existed. Unchanged
+// files are only listed when verbose.
+func (r *textReporter) resolution(e generator.Event) {
+	ok := r.mark("✓", "+", successStyle)
+	switch {
+	case e.Action == conflict.Unchanged && r.level < Verbose:
+	case e.Backup != "":
+		r.println(r.out, "   %s %s (previous version saved as %s)", ok, e.Path, e.Backup)
+	case e.Action == conflict.Created || e.Action == conflict.Overwritten || e.Action == conflict.Merged:
+		r.println(r.out, "   %s %s (%s)", ok, e.Path, e.Action)
+	case e.Reason != "":
+		r.println(r.out, "   - %s %s (%s)", e.Path, e.Action, e.Reason)
+	default:
+		r.println(r.out, "   - %s %s", e.Path, e.Action)
+	}
+}
+
+// style renders s with st, unless the text is plain
+func (r *textReporter) style(st lipgloss.Style, s string) string {
+	if r.plain {
+		return s
+	}
+	return st.Render(s)
+}
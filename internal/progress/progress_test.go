@@ -0,0 +1,207 @@
+package progress
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/purnama/scaffold/internal/conflict"
+	"github.com/purnama/scaffold/internal/generator"
+)
+
+// events is a run with a bit of everything
+var events = []generator.Event{
+	{Kind: generator.ProjectStarted, Project: "app", Dir: "app", Template: "go-cli"},
+	{Kind: generator.Debug, Message: "staging the project in /tmp/x"},
+	{Kind: generator.StepStarted, Step: generator.StepFiles},
+	{Kind: generator.FileWritten, Path: "main.go", Mode: 0644},
+	{Kind: generator.FileWritten, Path: "scripts/setup.sh", Mode: 0755},
+	{Kind: generator.StepStarted, Step: generator.StepConflicts, Message: "merge"},
+	{Kind: generator.FileResolved, Path: "README.md", Action: conflict.Unchanged},
+	{Kind: generator.FileResolved, Path: ".gitignore", Action: conflict.Merged},
+	{Kind: generator.Warning, Message: "go mod tidy failed"},
+	{Kind: generator.HookStarted, Hook: "lint", Stage: "post-init"},
+	{Kind: generator.HookOutput, Hook: "lint", Stage: "post-init", Message: "all good"},
+	{Kind: generator.HookFinished, Hook: "lint", Stage: "post-init"},
+	{Kind: generator.HookFinished, Hook: "flaky", Stage: "post-init", Err: errors.New("exit status 1")},
+	{Kind: generator.ProjectCreated, Project: "app", Dir: "app", NextSteps: []string{"go test ./..."}},
+}
+
+func report(format Format, level Level, events []generator.Event) (out, errOut string) {
+	var o, e bytes.Buffer
+	r := New(format, level, &o, &e)
+	for _, ev := range events {
+		r.Report(ev)
+	}
+	return o.String(), e.String()
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		level   Level
+		want    []string // Lines of out
+		notWant []string
+	}{
+		{
+			name:   "pretty",
+			format: Pretty,
+			want: []string{
+				"🚀 Creating project: app",
+				"📄 Creating files...",
+				"   ✓ main.go",
+				"   ✓ scripts/setup.sh (executable)",
+				"⚠️  Directory exists, resolving conflicts (merge)...",
+				"   ✓ .gitignore (merged)",
+				"   $ lint",
+				"   │ all good",
+				"   ✓ lint",
+				"✅ Project 'app' created successfully!",
+				"   cd app",
+				"   go test ./...",
+			},
+			notWant: []string{"README.md", "staging", "mode"},
+		},
+		{
+			name:   "plain",
+			format: Plain,
+			want: []string{
+				"Creating project: app",
+				"Creating files...",
+				"   + main.go",
+				"   | all good",
+				"Project 'app' created successfully!",
+			},
+		},
+		{
+			name:   "verbose",
+			format: Plain,
+			level:  Verbose,
+			want: []string{
+				"   staging the project in /tmp/x",
+				"   + main.go (mode 0644)",
+				"   + scripts/setup.sh (mode 0755)",
+				"   - README.md unchanged",
+			},
+		},
+		{
+			name:    "quiet",
+			format:  Pretty,
+			level:   Quiet,
+			notWant: []string{"app", "lint"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, errOut := report(tt.format, tt.level, events)
+			lines := strings.Split(out, "\n")
+			for _, want := range tt.want {
+				if !slices.Contains(lines, want) {
+					t.Errorf("output has no line %q:\n%s", want, out)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out, notWant) {
+					t.Errorf("output shouldn't contain %q:\n%s", notWant, out)
+				}
+			}
+			if tt.level == Quiet && out != "" {
+				t.Errorf("quiet output = %q, want nothing", out)
+			}
+
+			// Warnings and failed hooks go to errOut at every level
+			for _, want := range []string{"go mod tidy failed", "flaky failed: exit status 1"} {
+				if !strings.Contains(errOut, want) {
+					t.Errorf("errOut missing %q:\n%s", want, errOut)
+				}
+			}
+			if tt.format == Plain {
+				for _, r := range out + errOut {
+					if r > 0x7f {
+						t.Fatalf("plain output has %q:\n%s", r, out+errOut)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTextDryRun(t *testing.T) {
+	preview := []generator.Event{
+		{Kind: generator.ProjectStarted, DryRun: true, Project: "app", Dir: "out/app"},
+		{Kind: generator.DirCreated, DryRun: true, Path: "cmd"},
+		{Kind: generator.FileWritten, DryRun: true, Path: "run.sh", Mode: 0755},
+		{Kind: generator.FileWritten, DryRun: true, Path: "LICENSE", Mode: 0644, Message: "MIT"},
+		{Kind: generator.DependencyAdded, DryRun: true, Module: "github.com/spf13/cobra", Version: "v1.8.0"},
+		{Kind: generator.HookStarted, DryRun: true, Hook: "make", Stage: "pre-init", Message: "custom template, asks first"},
+		{Kind: generator.ProjectCreated, DryRun: true, Project: "app", Dir: "out/app"},
+	}
+	out, _ := report(Pretty, Normal, preview)
+	for _, want := range []string{
+		"🔍 DRY RUN - Preview of what will be created:",
+		"📦 Project: out/app/",
+		"   📁 cmd/",
+		"   📄 run.sh (executable)",
+		"   📄 LICENSE (MIT)",
+		"      require github.com/spf13/cobra v1.8.0",
+		"   🪝 pre-init: make (custom template, asks first)",
+		"💡 Remove --dry-run to create the project",
+	} {
+		if !slices.Contains(strings.Split(out, "\n"), want) {
+			t.Errorf("output has no line %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "created successfully") {
+		t.Errorf("a dry run shouldn't claim success:\n%s", out)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	// JSON ignores the level, a program decides what to show
+	out, errOut := report(JSON, Quiet, events)
+	if errOut != "" {
+		t.Errorf("errOut = %q, want everything on out", errOut)
+	}
+
+	var kinds []generator.EventKind
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		var e struct {
+			Event generator.EventKind `json:"event"`
+			Mode  string              `json:"mode"`
+			Error string              `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("line %q isn't JSON: %v", scanner.Text(), err)
+		}
+		kinds = append(kinds, e.Event)
+		if e.Event == generator.FileWritten && e.Mode == "" {
+			t.Errorf("file event without a mode: %s", scanner.Text())
+		}
+	}
+	if len(kinds) != len(events) {
+		t.Fatalf("got %d events, want %d:\n%s", len(kinds), len(events), out)
+	}
+	for i, e := range events {
+		if kinds[i] != e.Kind {
+			t.Errorf("event %d = %s, want %s", i, kinds[i], e.Kind)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range Formats {
+		if got, err := ParseFormat(string(f)); err != nil || got != f {
+			t.Errorf("ParseFormat(%q) = %q, %v", f, got, err)
+		}
+	}
+	if _, err := ParseFormat("yaml"); err == nil {
+		t.Error("ParseFormat(yaml) succeeded")
+	}
+}
@@ -0,0 +1,300 @@
+// Package project inspects existing Go projects
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of projects, detected from imports
+const (
+	KindHTTP = "http"
+	KindGRPC = "grpc"
+	KindCLI  = "cli"
+)
+
+// kindImports maps import path prefixes to the kind of project they indicate
+var kindImports = []struct{ prefix, kind string }{
+	{"google.golang.org/grpc", KindGRPC},
+	{"net/http", KindHTTP},
+	{"github.com/gin-gonic/gin", KindHTTP},
+	{"github.com/labstack/echo", KindHTTP},
+	{"github.com/go-chi/chi", KindHTTP},
+	{"github.com/gorilla/mux", KindHTTP},
+	{"github.com/gofiber/fiber", KindHTTP},
+	{"github.com/spf13/cobra", KindCLI},
+	{"github.com/urfave/cli", KindCLI},
+	{"github.com/alecthomas/kong", KindCLI},
+}
+
+// ciFiles are the CI configurations Inspect looks for, besides GitHub workflows
+var ciFiles = []string{".gitlab-ci.yml", ".circleci/config.yml", "Jenkinsfile", ".travis.yml", "azure-pipelines.yml"}
+
+// frontendDirs are the folders a frontend usually lives in
+var frontendDirs = []string{"frontend", "web", "ui", "client", "app"}
+
+// Info describes a project directory
+type Info struct {
+	Dir          string        `json:"dir"`
+	Module       string        `json:"module"`        // Module path from go.mod, "" without one
+	GoVersion    string        `json:"go_version"`    // go directive from go.mod
+	MainPackages []MainPackage `json:"main_packages"` // cmd/* sorted by name, then the root package
+	Kinds        []string      `json:"kinds"`         // KindHTTP, KindGRPC and KindCLI, from imports
+	Dockerfile   bool          `json:"dockerfile"`
+	Makefile     bool          `json:"makefile"`
+	CI           []string      `json:"ci"`       // CI configuration files, relative to Dir
+	Frontend     string        `json:"frontend"` // Folder holding a package.json, "" without one
+	Git          *GitStatus    `json:"git"`      // nil outside a git repository
+}
+
+// MainPackage is a directory holding package main
+type MainPackage struct {
+	Name string `json:"name"` // Binary name: the cmd/ subdirectory or the module's last element
+	Path string `json:"path"` // Relative package path, e.g. "./cmd/server" or "."
+}
+
+// GitStatus summarizes `git status`
+type GitStatus struct {
+	Branch    string `json:"branch"`   // "" on a detached HEAD
+	Modified  int    `json:"modified"` // Changed, staged or deleted files
+	Untracked int    `json:"untracked"`
+}
+
+// Clean reports whether the working tree has no changes at all
+func (g *GitStatus) Clean() bool {
+	return g.Modified == 0 && g.Untracked == 0
+}
+
+// Inspect reports what it can find out about the project in dir. A missing
+// go.mod is not an error, Module and GoVersion are just empty.
+func Inspect(dir string) (*Info, error) {
+	info := &Info{Dir: dir}
+
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	info.Module, info.GoVersion = ParseGoMod(data)
+
+	entries, err := os.ReadDir(filepath.Join(dir, "cmd"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() && isMainPackage(filepath.Join(dir, "cmd", e.Name())) {
+			info.MainPackages = append(info.MainPackages, MainPackage{Name: e.Name(), Path: "./cmd/" + e.Name()})
+		}
+	}
+	sort.Slice(info.MainPackages, func(i, j int) bool {
+		return info.MainPackages[i].Name < info.MainPackages[j].Name
+	})
+
+	if isMainPackage(dir) {
+		info.MainPackages = append(info.MainPackages, MainPackage{Name: info.Name(), Path: "."})
+	}
+
+	if info.Kinds, err = detectKinds(dir); err != nil {
+		return nil, err
+	}
+	info.Dockerfile = exists(filepath.Join(dir, "Dockerfile"))
+	info.Makefile = exists(filepath.Join(dir, "Makefile")) || exists(filepath.Join(dir, "GNUmakefile"))
+	info.CI = detectCI(dir)
+	for _, d := range frontendDirs {
+		if exists(filepath.Join(dir, d, "package.json")) {
+			info.Frontend = d
+			break
+		}
+	}
+	info.Git = gitStatus(dir)
+	return info, nil
+}
+
+// detectKinds looks at the imports of every non-test Go file
+func detectKinds(dir string) ([]string, error) {
+	found := map[string]bool{}
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != dir && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			return nil
+		}
+
+		f, err := parser.ParseFile(fset, p, nil, parser.ImportsOnly)
+		if err != nil {
+			return nil // Broken files don't make the project unreadable
+		}
+		for _, imp := range f.Imports {
+			importPath, _ := strconv.Unquote(imp.Path.Value)
+			for _, k := range kindImports {
+				if importPath == k.prefix || strings.HasPrefix(importPath, k.prefix+"/") {
+					found[k.kind] = true
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var kinds []string
+	for _, k := range []string{KindHTTP, KindGRPC, KindCLI} {
+		if found[k] {
+			kinds = append(kinds, k)
+		}
+	}
+	return kinds, nil
+}
+
+// detectCI lists GitHub workflows and other known CI configuration files
+func detectCI(dir string) []string {
+	var ci []string
+	workflows, _ := filepath.Glob(filepath.Join(dir, ".github", "workflows", "*.y*ml"))
+	sort.Strings(workflows)
+	for _, w := range workflows {
+		ci = append(ci, filepath.ToSlash(filepath.Join(".github", "workflows", filepath.Base(w))))
+	}
+	for _, f := range ciFiles {
+		if exists(filepath.Join(dir, f)) {
+			ci = append(ci, f)
+		}
+	}
+	return ci
+}
+
+// gitStatus runs git status in dir, returning nil when dir isn't in a
+// repository or git isn't installed
+func gitStatus(dir string) *GitStatus {
+	out, err := exec.Command("git", "-C", dir, "status", "--porcelain=v1", "--branch").Output()
+	if err != nil {
+		return nil
+	}
+
+	status := &GitStatus{}
+	for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "## "):
+			branch := strings.TrimPrefix(line, "## ")
+			branch, _, _ = strings.Cut(branch, "...")
+			branch = strings.TrimPrefix(branch, "No commits yet on ")
+			if !strings.HasPrefix(branch, "HEAD (no branch)") {
+				status.Branch = branch
+			}
+		case strings.HasPrefix(line, "??"):
+			status.Untracked++
+		case line != "":
+			status.Modified++
+		}
+	}
+	return status
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+var majorVersionRe = regexp.MustCompile(`^v[0-9]+$`)
+
+// Name is the project name: the last element of the module path, ignoring
+// a major version suffix, or the directory name without a module
+func (i *Info) Name() string {
+	if i.Module == "" {
+		abs, err := filepath.Abs(i.Dir)
+		if err != nil {
+			return filepath.Base(i.Dir)
+		}
+		return filepath.Base(abs)
+	}
+	name := path.Base(i.Module)
+	if majorVersionRe.MatchString(name) && strings.Contains(i.Module, "/") {
+		name = path.Base(path.Dir(i.Module))
+	}
+	return name
+}
+
+// ParseGoMod returns the module path and go version from go.mod content
+func ParseGoMod(data []byte) (module, goVersion string) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			module = fields[1]
+			if unquoted, err := strconv.Unquote(module); err == nil {
+				module = unquoted
+			}
+		case "go":
+			goVersion = fields[1]
+		}
+	}
+	return module, goVersion
+}
+
+// isMainPackage reports whether a non-test Go file in dir declares package main
+func isMainPackage(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	fset := token.NewFileSet()
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.PackageClauseOnly)
+		if err == nil && f.Name.Name == "main" {
+			return true
+		}
+	}
+	return false
+}
+
+// modulePathElemRe matches an element of a module path: letters, digits
+// and ._~- not starting with a dot or dash
+var modulePathElemRe = regexp.MustCompile(`^[A-Za-z0-9_~][A-Za-z0-9._~-]*$`)
+
+// CheckModulePath checks that path is a Go module path, or the start of
+// one, such as "github.com/jane/tool" or just "tool". It is shared by
+// --module and the module_prefix setting, callers say which was wrong.
+func CheckModulePath(path string) error {
+	if path == "" {
+		return errors.New("is empty")
+	}
+	for _, elem := range strings.Split(path, "/") {
+		if !modulePathElemRe.MatchString(elem) || strings.HasSuffix(elem, ".") {
+			return fmt.Errorf("bad path element %q", elem)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package project
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for p, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestParseGoMod(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantModule string
+		wantGo     string
+	}{
+		{"plain", "module example.com/app\n\ngo 1.22\n", "example.com/app", "1.22"},
+		{"comments and toolchain", "// Service\nmodule example.com/app // main module\n\ngo 1.23.4\n\ntoolchain go1.24.0\n", "example.com/app", "1.23.4"},
+		{"quoted", "module \"example.com/app\"\n", "example.com/app", ""},
+		{"empty", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			module, goVersion := ParseGoMod([]byte(tt.content))
+			if module != tt.wantModule || goVersion != tt.wantGo {
+				t.Errorf("ParseGoMod() = %q, %q, want %q, %q", module, goVersion, tt.wantModule, tt.wantGo)
+			}
+		})
+	}
+}
+
+func TestInspectMainPackages(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":                   "module example.com/acme/billing/v2\n\ngo 1.23\n",
+		"cmd/worker/main.go":       "package main\n",
+		"cmd/api/main.go":          "// Command api\npackage main\n",
+		"cmd/tools/doc.go":         "package tools\n",
+		"cmd/only-tests/x_test.go": "package main\n",
+		"cmd/README.md":            "commands",
+		"internal/app/app.go":      "package app\n",
+	})
+
+	info, err := Inspect(dir)
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	if info.Module != "example.com/acme/billing/v2" || info.GoVersion != "1.23" {
+		t.Errorf("got module %q, go %q", info.Module, info.GoVersion)
+	}
+	if info.Name() != "billing" {
+		t.Errorf("Name() = %q, want billing", info.Name())
+	}
+
+	want := []MainPackage{{"api", "./cmd/api"}, {"worker", "./cmd/worker"}}
+	if len(info.MainPackages) != len(want) {
+		t.Fatalf("MainPackages = %v, want %v", info.MainPackages, want)
+	}
+	for i := range want {
+		if info.MainPackages[i] != want[i] {
+			t.Errorf("MainPackages[%d] = %v, want %v", i, info.MainPackages[i], want[i])
+		}
+	}
+}
+
+func TestInspectRootMain(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":      "module github.com/jane/tool\n",
+		"main.go":     "package main\n",
+		"cmd/root.go": "package cmd\n",
+	})
+
+	info, err := Inspect(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.MainPackages) != 1 || info.MainPackages[0] != (MainPackage{"tool", "."}) {
+		t.Errorf("MainPackages = %v", info.MainPackages)
+	}
+}
+
+func TestInspectWithoutGoMod(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "scripts")
+	os.Mkdir(dir, 0755)
+
+	info, err := Inspect(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Module != "" || len(info.MainPackages) != 0 {
+		t.Errorf("unexpected info: %+v", info)
+	}
+	if info.Name() != "scripts" {
+		t.Errorf("Name() = %q, want the directory name", info.Name())
+	}
+}
+
+func TestInspectKinds(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{"http", map[string]string{"main.go": "package main\n\nimport \"net/http\"\n"}, []string{KindHTTP}},
+		{"framework", map[string]string{"internal/api/api.go": "package api\n\nimport \"github.com/go-chi/chi/v5\"\n"}, []string{KindHTTP}},
+		{"grpc and cli", map[string]string{
+			"cmd/server/main.go": "package main\n\nimport (\n\t\"google.golang.org/grpc\"\n\t_ \"google.golang.org/grpc/reflection\"\n)\n",
+			"cmd/ctl/main.go":    "package main\n\nimport \"github.com/spf13/cobra\"\n",
+		}, []string{KindGRPC, KindCLI}},
+		{"tests and vendor are ignored", map[string]string{
+			"app_test.go":                     "package app\n\nimport \"net/http\"\n",
+			"vendor/example.com/x/x.go":       "package x\n\nimport \"github.com/spf13/cobra\"\n",
+			"testdata/server.go":              "package main\n\nimport \"net/http\"\n",
+			"frontend/node_modules/x/main.go": "package main\n\nimport \"net/http\"\n",
+		}, nil},
+		{"similar prefix", map[string]string{"x.go": "package x\n\nimport \"net/httptest\"\n"}, nil},
+		{"broken file", map[string]string{"x.go": "package x\n\nimport (\n"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			info, err := Inspect(dir)
+			if err != nil {
+				t.Fatalf("Inspect failed: %v", err)
+			}
+			if !slices.Equal(info.Kinds, tt.want) {
+				t.Errorf("Kinds = %v, want %v", info.Kinds, tt.want)
+			}
+		})
+	}
+}
+
+func TestInspectTooling(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Dockerfile":                  "FROM scratch\n",
+		"GNUmakefile":                 "all:\n",
+		".github/workflows/test.yml":  "on: push\n",
+		".github/workflows/lint.yaml": "on: push\n",
+		".github/workflows/README.md": "workflows",
+		".gitlab-ci.yml":              "test:\n",
+		"web/package.json":            "{}\n",
+		"frontend/src/index.ts":       "",
+	})
+
+	info, err := Inspect(dir)
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	if !info.Dockerfile || !info.Makefile {
+		t.Errorf("Dockerfile = %v, Makefile = %v, want both true", info.Dockerfile, info.Makefile)
+	}
+	wantCI := []string{".github/workflows/lint.yaml", ".github/workflows/test.yml", ".gitlab-ci.yml"}
+	if !slices.Equal(info.CI, wantCI) {
+		t.Errorf("CI = %v, want %v", info.CI, wantCI)
+	}
+	if info.Frontend != "web" {
+		t.Errorf("Frontend = %q, want web", info.Frontend)
+	}
+
+	empty, err := Inspect(t.TempDir())
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	if empty.Dockerfile || empty.Makefile || empty.CI != nil || empty.Frontend != "" {
+		t.Errorf("empty directory reported tooling: %+v", empty)
+	}
+}
+
+func TestInspectGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	if info, err := Inspect(dir); err != nil || info.Git != nil {
+		t.Fatalf("Inspect outside a repository: Git = %+v, err = %v", info.Git, err)
+	}
+
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q", "-b", "trunk")
+	writeFiles(t, dir, map[string]string{"a.txt": "a\n", "b.txt": "b\n"})
+
+	info, err := Inspect(dir)
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	if info.Git == nil || info.Git.Branch != "trunk" || info.Git.Untracked != 2 || info.Git.Modified != 0 {
+		t.Fatalf("Git = %+v, want trunk with 2 untracked files", info.Git)
+	}
+
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	if info, _ = Inspect(dir); !info.Git.Clean() {
+		t.Errorf("Git = %+v, want clean", info.Git)
+	}
+
+	writeFiles(t, dir, map[string]string{"a.txt": "changed\n"})
+	if info, _ = Inspect(dir); info.Git.Modified != 1 || info.Git.Untracked != 0 {
+		t.Errorf("Git = %+v, want 1 modified", info.Git)
+	}
+}
+
+func TestCheckModulePath(t *testing.T) {
+	tests := []struct {
+		path  string
+		valid bool
+	}{
+		{"github.com/user/my-api", true},
+		{"tool", true},
+		{"example.com/tool/v2", true},
+		{"gitlab.example.com/~team/_tools", true},
+		{"", false},
+		{"/github.com/user", false},
+		{"github.com/user/", false},
+		{"github.com//tool", false},
+		{"github.com/../tool", false},
+		{"my tool", false},
+		{"-flag", false},
+		{"github.com/user/tool.", false},
+		{"github.com/user/c++", false},
+		{`github.com\user`, false},
+	}
+
+	for _, tt := range tests {
+		if err := CheckModulePath(tt.path); (err == nil) != tt.valid {
+			t.Errorf("CheckModulePath(%q) = %v, want valid %v", tt.path, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package render
+
+import (
+	"crypto/rand"
+	"fmt"
+	"go/token"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// EnvAllowList holds the environment variables the env function may read.
+// A trailing * matches any suffix. Other variables are an error, so a
+// template can't copy secrets into a project.
+var EnvAllowList = []string{"USER", "LOGNAME", "GOPATH", "GOPROXY", "SCAFFOLD_*"}
+
+// Funcs returns the helper functions available to template contents and
+// paths, next to the text/template built-ins:
+//
+//	title      "my-api" -> "My Api"
+//	camel      "my-api" -> "myApi"
+//	pascal     "my-api" -> "MyApi"
+//	snake      "my-api" -> "my_api"
+//	kebab      "MyAPI"  -> "my-api"
+//	screaming  "my-api" -> "MY_API"
+//	pluralize  "entry"  -> "entries"
+//	goIdent    "2fa-api" -> "x2faApi", a valid Go identifier that isn't a keyword
+//	now        the current time.Time, e.g. {{now.Format "2006-01-02"}}
+//	year       the current year as an int
+//	uuid       a random version 4 UUID
+//	env        an environment variable from EnvAllowList, e.g. {{env "USER"}}
+//	indent     {{indent 4 .Text}} indents every non-empty line by 4 spaces
+//	quote      a Go string literal, e.g. {{quote .ProjectName}} -> "my-api"
+//	default    {{.Vars.name | default "x"}} is x when name is empty
+//
+// The case functions split words at spaces, punctuation and case changes, so
+// they accept names in any of the styles they produce.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"title":     Title,
+		"camel":     Camel,
+		"pascal":    Pascal,
+		"snake":     Snake,
+		"kebab":     Kebab,
+		"screaming": Screaming,
+		"pluralize": Pluralize,
+		"goIdent":   GoIdent,
+		"now":       time.Now,
+		"year":      func() int { return time.Now().Year() },
+		"uuid":      newUUID,
+		"env":       env,
+		"indent":    indent,
+		"quote":     func(v any) string { return strconv.Quote(fmt.Sprint(v)) },
+		"default":   defaultValue,
+	}
+}
+
+// words splits s at non-alphanumeric characters and at case changes:
+// "myHTTPServer2" is my, HTTP, Server2
+func words(s string) []string {
+	var out []string
+	runes := []rune(s)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				out = append(out, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := runes[i-1]
+		lowerToUpper := unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev))
+		acronymEnd := unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if lowerToUpper || acronymEnd {
+			out = append(out, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start >= 0 {
+		out = append(out, string(runes[start:]))
+	}
+	return out
+}
+
+func capitalize(w string) string {
+	r := []rune(strings.ToLower(w))
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// Title returns the words of s capitalized and separated by spaces
+func Title(s string) string {
+	ws := words(s)
+	for i, w := range ws {
+		ws[i] = capitalize(w)
+	}
+	return strings.Join(ws, " ")
+}
+
+// Camel returns s in camelCase
+func Camel(s string) string {
+	ws := words(s)
+	for i, w := range ws {
+		if i == 0 {
+			ws[i] = strings.ToLower(w)
+		} else {
+			ws[i] = capitalize(w)
+		}
+	}
+	return strings.Join(ws, "")
+}
+
+// Pascal returns s in PascalCase
+func Pascal(s string) string {
+	ws := words(s)
+	for i, w := range ws {
+		ws[i] = capitalize(w)
+	}
+	return strings.Join(ws, "")
+}
+
+// Snake returns s in snake_case
+func Snake(s string) string {
+	return strings.ToLower(strings.Join(words(s), "_"))
+}
+
+// Kebab returns s in kebab-case
+func Kebab(s string) string {
+	return strings.ToLower(strings.Join(words(s), "-"))
+}
+
+// Screaming returns s in SCREAMING_SNAKE_CASE
+func Screaming(s string) string {
+	return strings.ToUpper(strings.Join(words(s), "_"))
+}
+
+var irregularPlurals = map[string]string{
+	"child":  "children",
+	"person": "people",
+	"man":    "men",
+	"woman":  "women",
+	"mouse":  "mice",
+	"datum":  "data",
+	"index":  "indices",
+}
+
+// Pluralize returns the English plural of the last word of s, keeping the
+// case of s and what follows the word: "Entry" is Entries, "USER" is USERS
+// and "user-" is users-
+func Pluralize(s string) string {
+	ws := words(s)
+	if len(ws) == 0 {
+		return s
+	}
+	last := ws[len(ws)-1]
+	// Punctuation after the last word, as in "user-", stays after the plural
+	i := strings.LastIndex(s, last)
+	stem, rest, lower := s[:i], s[i+len(last):], strings.ToLower(last)
+	upper := last == strings.ToUpper(last) && last != lower
+
+	plural, ok := irregularPlurals[lower]
+	switch {
+	case ok:
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		plural = lower[:len(lower)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		plural = lower + "es"
+	default:
+		plural = lower + "s"
+	}
+
+	switch {
+	case upper:
+		plural = strings.ToUpper(plural)
+	case unicode.IsUpper([]rune(last)[0]):
+		plural = capitalize(plural)
+	}
+	// Keep the original spelling of the word where the plural extends it
+	if strings.HasPrefix(strings.ToLower(plural), lower) {
+		plural = last + plural[len(last):]
+	}
+	return stem + plural + rest
+}
+
+// GoIdent returns s as a camelCase Go identifier: a leading digit gets an x
+// prefix and a keyword an underscore suffix, e.g. "2fa" is x2fa and "type"
+// is type_
+func GoIdent(s string) string {
+	id := Camel(s)
+	if id == "" {
+		return "x"
+	}
+	if unicode.IsDigit([]rune(id)[0]) {
+		id = "x" + id
+	}
+	if token.IsKeyword(id) {
+		id += "_"
+	}
+	return id
+}
+
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40 // Version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+func env(name string) (string, error) {
+	for _, allowed := range EnvAllowList {
+		if name == allowed || strings.HasSuffix(allowed, "*") && strings.HasPrefix(name, strings.TrimSuffix(allowed, "*")) {
+			return os.Getenv(name), nil
+		}
+	}
+	return "", fmt.Errorf("env: %s is not in the allow list (%s)", name, strings.Join(EnvAllowList, ", "))
+}
+
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = pad + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// defaultValue returns v, or def when v is empty: nil, "", 0, false or an
+// empty slice or map
+func defaultValue(def, v any) any {
+	if v == nil {
+		return def
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		if rv.Len() == 0 {
+			return def
+		}
+	default:
+		if rv.IsZero() {
+			return def
+		}
+	}
+	return v
+}
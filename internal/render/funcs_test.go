@@ -0,0 +1,128 @@
+package render
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCaseFuncs(t *testing.T) {
+	tests := []struct {
+		in                                            string
+		title, camel, pascal, snake, kebab, screaming string
+	}{
+		{"my-api", "My Api", "myApi", "MyApi", "my_api", "my-api", "MY_API"},
+		{"my_api", "My Api", "myApi", "MyApi", "my_api", "my-api", "MY_API"},
+		{"My API", "My Api", "myApi", "MyApi", "my_api", "my-api", "MY_API"},
+		{"myHTTPServer2", "My Http Server2", "myHttpServer2", "MyHttpServer2", "my_http_server2", "my-http-server2", "MY_HTTP_SERVER2"},
+		{"UserID", "User Id", "userId", "UserId", "user_id", "user-id", "USER_ID"},
+		{"v2-api", "V2 Api", "v2Api", "V2Api", "v2_api", "v2-api", "V2_API"},
+		{"  spaced  out ", "Spaced Out", "spacedOut", "SpacedOut", "spaced_out", "spaced-out", "SPACED_OUT"},
+		{"", "", "", "", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got := []string{Title(tt.in), Camel(tt.in), Pascal(tt.in), Snake(tt.in), Kebab(tt.in), Screaming(tt.in)}
+			want := []string{tt.title, tt.camel, tt.pascal, tt.snake, tt.kebab, tt.screaming}
+			for i, name := range []string{"title", "camel", "pascal", "snake", "kebab", "screaming"} {
+				if got[i] != want[i] {
+					t.Errorf("%s(%q) = %q, want %q", name, tt.in, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"user", "users"},
+		{"User", "Users"},
+		{"USER", "USERS"},
+		{"entry", "entries"},
+		{"Category", "Categories"},
+		{"key", "keys"},
+		{"box", "boxes"},
+		{"match", "matches"},
+		{"bus", "buses"},
+		{"child", "children"},
+		{"Person", "People"},
+		{"order_item", "order_items"},
+		{"blogPost", "blogPosts"},
+		{"user-", "users-"},
+		{"order_item!", "order_items!"},
+		{"box.", "boxes."},
+		{"--", "--"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Pluralize(tt.in); got != tt.want {
+			t.Errorf("Pluralize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGoIdent(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"my-api", "myApi"},
+		{"2fa-api", "x2faApi"},
+		{"type", "type_"},
+		{"go.mod", "goMod"},
+		{"---", "x"},
+		{"café", "café"},
+	}
+	for _, tt := range tests {
+		if got := GoIdent(tt.in); got != tt.want {
+			t.Errorf("GoIdent(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteFuncs(t *testing.T) {
+	t.Setenv("USER", "jane")
+	t.Setenv("SCAFFOLD_TEAM", "platform")
+	data := Data{ProjectName: "order-service", Vars: map[string]any{"db": "", "port": 0, "entity": "Order"}}
+
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{"{{.ProjectName | title}}", "Order Service"},
+		{"{{.ProjectName | pascal}}Server", "OrderServiceServer"},
+		{"{{.Vars.entity | pluralize | snake}}", "orders"},
+		{"{{.ProjectName | goIdent}}", "orderService"},
+		{`{{env "USER"}}/{{env "SCAFFOLD_TEAM"}}`, "jane/platform"},
+		{"{{quote .ProjectName}}", `"order-service"`},
+		{`{{.Vars.db | default "postgres"}}`, "postgres"},
+		{`{{.Vars.port | default 8080}}`, "8080"},
+		{`{{.Vars.entity | default "x"}}`, "Order"},
+		{"x:\n{{indent 2 \"a: 1\\n\\nb: 2\"}}", "x:\n  a: 1\n\n  b: 2"},
+		{"{{year}}", strconv.Itoa(time.Now().Year())},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			got, err := Execute("test", tt.tmpl, data)
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Execute(%q) = %q, want %q", tt.tmpl, got, tt.want)
+			}
+		})
+	}
+
+	got, err := Execute("test", "{{uuid}}", data)
+	if err != nil || !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(got) {
+		t.Errorf("uuid = %q, %v, want a version 4 UUID", got, err)
+	}
+}
+
+func TestEnvAllowList(t *testing.T) {
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	_, err := Execute("test", `{{env "AWS_SECRET_ACCESS_KEY"}}`, Data{})
+	if err == nil || !strings.Contains(err.Error(), "AWS_SECRET_ACCESS_KEY is not in the allow list") {
+		t.Errorf("Execute error = %v, want the variable refused", err)
+	}
+}
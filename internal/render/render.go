@@ -0,0 +1,98 @@
+// Package render executes the text/template content of templates and components
+package render
+
+import (
+	"bytes"
+	"io/fs"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// DefaultGoVersion is used when there is no go directive to follow
+const DefaultGoVersion = "1.22"
+
+// Data holds data for template substitution
+type Data struct {
+	ProjectName string
+	PackageName string
+	ModuleName  string // Empty when adding components to a project without go.mod
+	Description string
+	License     string
+	Author      string         // From config, may be empty
+	Vars        map[string]any // Template variables, typed per their declaration
+
+	// Detected from an existing project when adding components
+	GoVersion string   // From the go directive, e.g. "1.22"
+	Binaries  []Binary // Main packages, cmd/* first
+	Kinds     []string // "http", "grpc" and "cli", from the project's imports
+}
+
+// Binary is a main package of the project
+type Binary struct {
+	Name   string // Binary name, e.g. "server"
+	Path   string // Package path for go build, e.g. "./cmd/server"
+	Import string // Import path for go install, e.g. "example.com/app/cmd/server"
+}
+
+// PackageName returns the Go package name for a project name: lowercase,
+// without the hyphens, underscores and dots a package name can't have
+func PackageName(projectName string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", "_", "", ".", "").Replace(projectName))
+}
+
+// BinaryName is the name of the first binary, or the project name
+func (d Data) BinaryName() string {
+	if len(d.Binaries) > 0 {
+		return d.Binaries[0].Name
+	}
+	return d.ProjectName
+}
+
+// MainPath is the package path of the first binary, or "."
+func (d Data) MainPath() string {
+	if len(d.Binaries) > 0 {
+		return d.Binaries[0].Path
+	}
+	return "."
+}
+
+// Is reports whether the project was detected to be of the given kind
+func (d Data) Is(kind string) bool {
+	return slices.Contains(d.Kinds, kind)
+}
+
+// Execute renders content as a text/template with data and Funcs
+func Execute(name, content string, data any) (string, error) {
+	tmpl, err := template.New(name).Funcs(Funcs()).Parse(content)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// FileMode returns the permissions of a generated file: mode when set, else
+// 0755 for a script starting with #! and 0644 for anything else
+func FileMode(mode fs.FileMode, content string) fs.FileMode {
+	switch {
+	case mode != 0:
+		return mode.Perm()
+	case strings.HasPrefix(content, "#!"):
+		return 0755
+	default:
+		return 0644
+	}
+}
+
+// IsBinary reports whether content isn't text: it holds a NUL byte or isn't
+// valid UTF-8
+func IsBinary(content []byte) bool {
+	return bytes.IndexByte(content, 0) >= 0 || !utf8.Valid(content)
+}
@@ -0,0 +1,70 @@
+package render
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestFileMode(t *testing.T) {
+	tests := []struct {
+		mode    fs.FileMode
+		content string
+		want    fs.FileMode
+	}{
+		{0, "package main\n", 0644},
+		{0, "#!/bin/sh\necho hi\n", 0755},
+		{0, "#!/usr/bin/env bash\n", 0755},
+		{0, " #!/bin/sh\n", 0644},
+		{0, "", 0644},
+		{0600, "#!/bin/sh\n", 0600},
+		{0750, "echo hi\n", 0750},
+	}
+	for _, tt := range tests {
+		if got := FileMode(tt.mode, tt.content); got != tt.want {
+			t.Errorf("FileMode(%04o, %q) = %04o, want %04o", tt.mode, tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{"package main\n", false},
+		{"héllo, 世界\n", false},
+		{"", false},
+		{"\x89PNG\r\n\x1a\n\x00\x00", true},
+		{"text\x00with a NUL", true},
+		{"latin-1 caf\xe9", true},
+	}
+	for _, tt := range tests {
+		if got := IsBinary([]byte(tt.content)); got != tt.want {
+			t.Errorf("IsBinary(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"my-project", "myproject"},
+		{"my_project", "myproject"},
+		{"MyProject", "myproject"},
+		{"my-api-server", "myapiserver"},
+		{"my_api_server", "myapiserver"},
+		{"myproject", "myproject"},
+		{"MY-PROJECT", "myproject"},
+		{"my.app", "myapp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := PackageName(tt.input); result != tt.expected {
+				t.Errorf("PackageName(%q) = %q; want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
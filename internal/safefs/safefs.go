@@ -0,0 +1,146 @@
+// Package safefs confines the files scaffold writes to a target directory,
+// so a template path, a variable or a symbolic link in an existing project
+// can't make it write anywhere else
+package safefs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrOutside is returned for paths that leave the root directory
+	ErrOutside = errors.New("is outside the target directory")
+	// ErrSymlink is returned for paths that go through a symbolic link when
+	// links aren't followed
+	ErrSymlink = errors.New("goes through a symbolic link")
+)
+
+// Root is a directory writes are confined to. Root itself may be a
+// symbolic link, the links below it are refused unless FollowSymlinks is
+// set, and even then they must resolve inside the real root.
+//
+// The checks look at the file system as it is when a path is resolved, they
+// don't protect against a concurrent process swapping a directory for a
+// link afterwards.
+type Root struct {
+	Dir            string
+	FollowSymlinks bool // Follow links below Dir that stay inside it
+}
+
+// Path returns where the relative, slash-separated path rel is written
+// below r.Dir, with every symbolic link resolved
+func (r Root) Path(rel string) (string, error) {
+	local := filepath.FromSlash(rel)
+	if !filepath.IsLocal(local) {
+		return "", fmt.Errorf("%s %w", rel, ErrOutside)
+	}
+
+	root, err := filepath.Abs(r.Dir)
+	if err != nil {
+		return "", err
+	}
+	if real, err := filepath.EvalSymlinks(root); err == nil {
+		root = real
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+
+	current := root
+	parts := strings.Split(filepath.Clean(local), string(filepath.Separator))
+	for i, part := range parts {
+		if part == "." {
+			continue
+		}
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if errors.Is(err, fs.ErrNotExist) {
+			// Nothing below a missing entry exists either
+			return filepath.Join(append([]string{current}, parts[i+1:]...)...), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			continue
+		}
+
+		if !r.FollowSymlinks {
+			return "", fmt.Errorf("%s %w (%s)", rel, ErrSymlink, filepath.ToSlash(filepath.Join(parts[:i+1]...)))
+		}
+		target, err := filepath.EvalSymlinks(current)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", rel, err)
+		}
+		if !inside(root, target) {
+			return "", fmt.Errorf("%s %w, %s links to %s", rel, ErrOutside, filepath.ToSlash(filepath.Join(parts[:i+1]...)), target)
+		}
+		current = target
+	}
+	return current, nil
+}
+
+// inside reports whether path is root or below it
+func inside(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && filepath.IsLocal(rel)
+}
+
+// MkdirAll creates the directory rel below r.Dir with its missing parents
+func (r Root) MkdirAll(rel string, perm fs.FileMode) error {
+	path, err := r.Path(rel)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(path, perm)
+}
+
+// WriteFile writes data to rel below r.Dir, creating missing parents
+func (r Root) WriteFile(rel string, data []byte, perm fs.FileMode) error {
+	path, err := r.Path(rel)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+// reservedNames can't be used as file names on Windows, with or without an
+// extension
+var reservedNames = []string{
+	"CON", "PRN", "AUX", "NUL",
+	"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+	"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
+}
+
+// ValidateName checks that name is a single file or directory name valid on
+// Linux, macOS and Windows, e.g. a project name
+func ValidateName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("is empty")
+	case name == "." || name == "..":
+		return fmt.Errorf("can't be %s", name)
+	case strings.Contains(name, "/"):
+		return fmt.Errorf("can't contain /")
+	case strings.ContainsAny(name, `<>:"\|?*`):
+		return fmt.Errorf("has a character invalid on Windows")
+	case strings.ContainsFunc(name, func(r rune) bool { return r < 0x20 || r == 0x7f }):
+		return fmt.Errorf("has a control character")
+	case strings.HasSuffix(name, " ") || strings.HasSuffix(name, "."):
+		return fmt.Errorf("can't end with a space or dot on Windows")
+	}
+	base, _, _ := strings.Cut(name, ".")
+	for _, reserved := range reservedNames {
+		if strings.EqualFold(base, reserved) {
+			return fmt.Errorf("is a reserved name on Windows")
+		}
+	}
+	return nil
+}
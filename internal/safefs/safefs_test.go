@@ -0,0 +1,186 @@
+package safefs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// hostileRoot returns a root holding a regular directory and links that
+// point inside it, outside it and nowhere, next to a directory outside it
+func hostileRoot(t *testing.T) (root, outside string) {
+	t.Helper()
+	base := t.TempDir()
+	root = filepath.Join(base, "project")
+	outside = filepath.Join(base, "outside")
+	os.MkdirAll(filepath.Join(root, "internal"), 0755)
+	os.MkdirAll(outside, 0755)
+	os.WriteFile(filepath.Join(root, "internal", "app.go"), []byte("package internal\n"), 0644)
+	os.WriteFile(filepath.Join(outside, ".bashrc"), []byte("# mine\n"), 0644)
+
+	links := map[string]string{
+		"escape":       outside,
+		"escape.go":    filepath.Join(outside, ".bashrc"),
+		"relative":     "../outside",
+		"pkg":          "internal",
+		"internal/up":  "..",
+		"dangling":     filepath.Join(base, "missing"),
+		"loop":         "loop",
+		"internal/out": "../../outside",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+	return root, outside
+}
+
+func TestRootPath(t *testing.T) {
+	root, _ := hostileRoot(t)
+	realRoot, _ := filepath.EvalSymlinks(root)
+
+	tests := []struct {
+		name   string
+		rel    string
+		follow bool
+		want   string // Path relative to the real root, when there's no error
+		err    error
+	}{
+		{"plain file", "main.go", false, "main.go", nil},
+		{"nested new file", "cmd/api/main.go", false, "cmd/api/main.go", nil},
+		{"existing file", "internal/app.go", false, "internal/app.go", nil},
+		{"root", ".", false, ".", nil},
+		{"inner dot dot", "cmd/../main.go", false, "main.go", nil},
+		{"parent", "../.bashrc", false, "", ErrOutside},
+		{"deep parent", "../../../../etc/passwd", false, "", ErrOutside},
+		{"parent after descent", "cmd/../../outside/x", false, "", ErrOutside},
+		{"absolute", "/etc/passwd", false, "", ErrOutside},
+		{"empty", "", false, "", ErrOutside},
+		{"link out", "escape/.bashrc", false, "", ErrSymlink},
+		{"link out followed", "escape/.bashrc", true, "", ErrOutside},
+		{"file link out", "escape.go", false, "", ErrSymlink},
+		{"file link out followed", "escape.go", true, "", ErrOutside},
+		{"relative link out followed", "relative/x", true, "", ErrOutside},
+		{"nested link out followed", "internal/out/x", true, "", ErrOutside},
+		{"link inside", "pkg/app.go", false, "", ErrSymlink},
+		{"link inside followed", "pkg/app.go", true, "internal/app.go", nil},
+		{"link to root followed", "internal/up/main.go", true, "main.go", nil},
+		{"link to root then out", "internal/up/escape/x", true, "", ErrOutside},
+		{"dangling link", "dangling", false, "", ErrSymlink},
+		{"loop", "loop/x", false, "", ErrSymlink},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Root{Dir: root, FollowSymlinks: tt.follow}.Path(tt.rel)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Path(%q) = %q, %v, want %v", tt.rel, got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Path(%q) failed: %v", tt.rel, err)
+			}
+			if want := filepath.Join(realRoot, filepath.FromSlash(tt.want)); got != want {
+				t.Errorf("Path(%q) = %q, want %q", tt.rel, got, want)
+			}
+		})
+	}
+
+	// Links that can't be resolved are an error even when followed
+	for _, rel := range []string{"dangling", "loop/x"} {
+		if _, err := (Root{Dir: root, FollowSymlinks: true}).Path(rel); err == nil {
+			t.Errorf("Path(%q) following links succeeded, want an error", rel)
+		}
+	}
+}
+
+func TestRootIsSymlink(t *testing.T) {
+	root, _ := hostileRoot(t)
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(root, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	// The root itself is trusted, links below it aren't
+	if err := (Root{Dir: link}).WriteFile("cmd/main.go", []byte("package main\n"), 0644); err != nil {
+		t.Fatalf("WriteFile through a linked root failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "cmd", "main.go")); err != nil {
+		t.Errorf("file not written in the real root: %v", err)
+	}
+	if err := (Root{Dir: link}).WriteFile("escape/x", nil, 0644); !errors.Is(err, ErrSymlink) {
+		t.Errorf("WriteFile error = %v, want %v", err, ErrSymlink)
+	}
+}
+
+func TestWriteRefusesEscape(t *testing.T) {
+	root, outside := hostileRoot(t)
+
+	writes := []string{"../outside/.bashrc", "escape/.bashrc", "escape.go", "relative/.bashrc", "internal/out/.bashrc"}
+	for _, rel := range writes {
+		for _, follow := range []bool{false, true} {
+			r := Root{Dir: root, FollowSymlinks: follow}
+			if err := r.WriteFile(rel, []byte("pwned\n"), 0644); err == nil {
+				t.Errorf("WriteFile(%q, follow %v) succeeded", rel, follow)
+			}
+			if err := r.MkdirAll(rel+"/dir", 0755); err == nil {
+				t.Errorf("MkdirAll(%q, follow %v) succeeded", rel, follow)
+			}
+		}
+	}
+
+	content, _ := os.ReadFile(filepath.Join(outside, ".bashrc"))
+	if string(content) != "# mine\n" {
+		t.Errorf("file outside the root changed: %q", content)
+	}
+	if entries, _ := os.ReadDir(outside); len(entries) != 1 {
+		t.Errorf("entries created outside the root: %v", entries)
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"my-api", ""},
+		{"My_API.v2", ""},
+		{".dotfiles", ""},
+		{"console", ""},
+		{"", "is empty"},
+		{".", "can't be ."},
+		{"..", "can't be .."},
+		{"../x", "can't contain /"},
+		{"a/b", "can't contain /"},
+		{"/etc", "can't contain /"},
+		{`..\x`, "character invalid on Windows"},
+		{"C:", "character invalid on Windows"},
+		{"what?", "character invalid on Windows"},
+		{"new\nline", "control character"},
+		{"nul\x00byte", "control character"},
+		{"trailing.", "can't end with a space or dot"},
+		{"trailing ", "can't end with a space or dot"},
+		{"CON", "reserved name on Windows"},
+		{"lpt1.txt", "reserved name on Windows"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateName(tt.name)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("ValidateName failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ValidateName(%q) error = %v, want %q", tt.name, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package templates
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/purnama/scaffold/internal/components"
+)
+
+// ComponentPrefix marks includes that refer to a component instead of a
+// template, e.g. "component:middleware"
+const ComponentPrefix = "component:"
+
+// lookup returns a template as registered, without resolving its layers
+func lookup(name string) (Template, bool) {
+	if t, ok := builtInTemplates[name]; ok {
+		return t, true
+	}
+	t, ok := customTemplates[name]
+	return t, ok
+}
+
+// resolve returns the named template with its layers applied. chain holds
+// the templates being resolved, to detect cycles.
+func resolve(name string, chain []string) (Template, error) {
+	t, ok := lookup(name)
+	if !ok {
+		if len(chain) > 0 {
+			return Template{}, fmt.Errorf("template %s: unknown template %q", chain[len(chain)-1], name)
+		}
+		return Template{}, fmt.Errorf("template not found: %s", name)
+	}
+	return resolveLayers(t, chain)
+}
+
+// resolveLayers builds the final file set of t from its layers, lowest
+// first: the template it extends, its includes in order, then its own files.
+// A file replaces one with the same path from a lower layer, keeping its
+// position; variables, dependencies and directory conditions are replaced by
+// name and directories added once. Next steps and hooks t leaves unset come
+// from the highest layer that sets them, and go mod init is skipped when any
+// layer skips it.
+func resolveLayers(t Template, chain []string) (Template, error) {
+	if slices.Contains(chain, t.Name) {
+		return Template{}, fmt.Errorf("template cycle: %s", strings.Join(append(chain, t.Name), " -> "))
+	}
+	chain = append(slices.Clip(chain), t.Name)
+
+	var layers []Template
+	if t.Extends != "" {
+		base, err := resolve(t.Extends, chain)
+		if err != nil {
+			return Template{}, err
+		}
+		layers = append(layers, base)
+	}
+	for _, inc := range t.Includes {
+		if name, ok := strings.CutPrefix(inc, ComponentPrefix); ok {
+			comp, found := components.GetComponent(name)
+			if !found {
+				return Template{}, fmt.Errorf("template %s: unknown component %q", t.Name, name)
+			}
+			layer := Template{Name: inc}
+			for _, f := range comp.Files {
+				layer.Files = append(layer.Files, FileTemplate{Path: f.Path, Content: f.Content, Mode: f.Mode, Layer: inc})
+			}
+			layers = append(layers, layer)
+			continue
+		}
+		included, err := resolve(inc, chain)
+		if err != nil {
+			return Template{}, err
+		}
+		layers = append(layers, included)
+	}
+
+	own := t
+	own.Files = make([]FileTemplate, len(t.Files))
+	for i, f := range t.Files {
+		if f.Layer == "" {
+			f.Layer = t.Name
+		}
+		own.Files[i] = f
+	}
+	if len(layers) == 0 {
+		return own, nil
+	}
+	layers = append(layers, own)
+
+	result := t
+	for i := len(layers) - 2; i >= 0; i-- {
+		if i == 0 && t.Extends != "" {
+			inherit(&result, layers[0])
+		} else {
+			inheritSteps(&result, layers[i])
+		}
+	}
+	result.Directories, result.Files, result.Variables, result.DirectoryWhen = nil, nil, nil, nil
+	result.Dependencies = nil
+	for _, l := range layers {
+		for dir, expr := range l.DirectoryWhen {
+			if result.DirectoryWhen == nil {
+				result.DirectoryWhen = map[string]string{}
+			}
+			result.DirectoryWhen[dir] = expr
+		}
+		for _, d := range l.Directories {
+			if !slices.Contains(result.Directories, d) {
+				result.Directories = append(result.Directories, d)
+			}
+		}
+		for _, f := range l.Files {
+			i := slices.IndexFunc(result.Files, func(e FileTemplate) bool { return e.Path == f.Path })
+			if i >= 0 {
+				result.Files[i] = f
+			} else {
+				result.Files = append(result.Files, f)
+			}
+		}
+		for _, v := range l.Variables {
+			i := slices.IndexFunc(result.Variables, func(e Variable) bool { return e.Name == v.Name })
+			if i >= 0 {
+				result.Variables[i] = v
+			} else {
+				result.Variables = append(result.Variables, v)
+			}
+		}
+		for _, d := range l.Dependencies {
+			i := slices.IndexFunc(result.Dependencies, func(e Dependency) bool { return e.Path == d.Path })
+			if i >= 0 {
+				result.Dependencies[i] = d
+			} else {
+				result.Dependencies = append(result.Dependencies, d)
+			}
+		}
+	}
+	return result, nil
+}
+
+// inherit copies the metadata t leaves unset from the template it extends
+func inherit(t *Template, base Template) {
+	if t.Category == "" {
+		t.Category = base.Category
+	}
+	if len(t.Tags) == 0 {
+		t.Tags = base.Tags
+	}
+	if t.DockerfileProfile == "" {
+		t.DockerfileProfile = base.DockerfileProfile
+	}
+	inheritSteps(t, base)
+}
+
+// inheritSteps copies the next steps and hooks t leaves unset from a layer
+// it extends or includes, and skips go mod init when the layer does
+func inheritSteps(t *Template, layer Template) {
+	if len(t.NextSteps) == 0 {
+		t.NextSteps = layer.NextSteps
+	}
+	if len(t.PreInit) == 0 {
+		t.PreInit = layer.PreInit
+	}
+	if len(t.PostInit) == 0 {
+		t.PostInit = layer.PostInit
+	}
+	t.SkipGoMod = t.SkipGoMod || layer.SkipGoMod
+}
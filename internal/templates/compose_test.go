@@ -0,0 +1,310 @@
+package templates
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/purnama/scaffold/internal/hooks"
+)
+
+// withBuiltIns registers extra built-in templates for the duration of a test
+func withBuiltIns(t *testing.T, tmpls ...Template) {
+	t.Helper()
+	saved := builtInTemplates
+	builtInTemplates = make(map[string]Template, len(saved)+len(tmpls))
+	for name, tmpl := range saved {
+		builtInTemplates[name] = tmpl
+	}
+	for _, tmpl := range tmpls {
+		builtInTemplates[tmpl.Name] = tmpl
+	}
+	t.Cleanup(func() { builtInTemplates = saved })
+}
+
+func filePaths(tmpl Template) []string {
+	paths := make([]string, len(tmpl.Files))
+	for i, f := range tmpl.Files {
+		paths[i] = f.Path
+	}
+	return paths
+}
+
+func findFile(tmpl Template, path string) (FileTemplate, bool) {
+	for _, f := range tmpl.Files {
+		if f.Path == path {
+			return f, true
+		}
+	}
+	return FileTemplate{}, false
+}
+
+func TestExtends(t *testing.T) {
+	withBuiltIns(t,
+		Template{
+			Name:        "base",
+			Directories: []string{"cmd", "internal"},
+			Files: []FileTemplate{
+				{Path: "README.md", Content: "base readme"},
+				{Path: ".gitignore", Content: "bin/"},
+			},
+			Variables: []Variable{
+				{Name: "port", Type: VarInt, Default: "8080"},
+				{Name: "db", Default: "postgres"},
+			},
+		},
+		Template{
+			Name:        "child",
+			Extends:     "base",
+			Directories: []string{"internal", "pkg"},
+			Files: []FileTemplate{
+				{Path: "main.go", Content: "package main"},
+				{Path: "README.md", Content: "child readme"},
+			},
+			Variables: []Variable{{Name: "port", Type: VarInt, Default: "9090"}},
+		},
+	)
+
+	tmpl, err := GetTemplate("child")
+	if err != nil {
+		t.Fatalf("GetTemplate failed: %v", err)
+	}
+
+	if got, want := strings.Join(tmpl.Directories, ","), "cmd,internal,pkg"; got != want {
+		t.Errorf("Directories = %s, want %s", got, want)
+	}
+	// An override keeps the position of the file it replaces
+	if got, want := strings.Join(filePaths(tmpl), ","), "README.md,.gitignore,main.go"; got != want {
+		t.Errorf("Files = %s, want %s", got, want)
+	}
+
+	layers := map[string]struct{ content, layer string }{
+		"README.md":  {"child readme", "child"},
+		".gitignore": {"bin/", "base"},
+		"main.go":    {"package main", "child"},
+	}
+	for path, want := range layers {
+		f, _ := findFile(tmpl, path)
+		if f.Content != want.content || f.Layer != want.layer {
+			t.Errorf("%s = %q from %q, want %q from %q", path, f.Content, f.Layer, want.content, want.layer)
+		}
+	}
+
+	if len(tmpl.Variables) != 2 || tmpl.Variables[0].Name != "port" || tmpl.Variables[0].Default != "9090" {
+		t.Errorf("Variables = %+v, want port overridden to 9090 and db kept", tmpl.Variables)
+	}
+	if tmpl.Extends != "base" || tmpl.Name != "child" {
+		t.Errorf("resolved template lost its identity: %+v", tmpl)
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	withBuiltIns(t,
+		Template{Name: "base", Files: []FileTemplate{{Path: "README.md", Content: "base"}}},
+		Template{Name: "extra", Files: []FileTemplate{
+			{Path: "README.md", Content: "extra"},
+			{Path: "extra.txt", Content: "extra"},
+		}},
+		Template{
+			Name:     "composed",
+			Extends:  "base",
+			Includes: []string{"component:makefile", "extra"},
+			Files:    []FileTemplate{{Path: "main.go", Content: "package main"}},
+		},
+	)
+
+	tmpl, err := GetTemplate("composed")
+	if err != nil {
+		t.Fatalf("GetTemplate failed: %v", err)
+	}
+
+	tests := []struct {
+		path  string
+		layer string
+	}{
+		{"README.md", "extra"}, // Includes come after the base
+		{"Makefile", "component:makefile"},
+		{"extra.txt", "extra"},
+		{"main.go", "composed"},
+	}
+	for _, tt := range tests {
+		f, ok := findFile(tmpl, tt.path)
+		if !ok {
+			t.Errorf("%s missing, got %v", tt.path, filePaths(tmpl))
+			continue
+		}
+		if f.Layer != tt.layer {
+			t.Errorf("%s layer = %q, want %q", tt.path, f.Layer, tt.layer)
+		}
+	}
+}
+
+func TestIncludesSteps(t *testing.T) {
+	withBuiltIns(t,
+		Template{
+			Name:      "base",
+			NextSteps: []string{"make base"},
+			PostInit:  []hooks.Hook{{Run: "echo base"}},
+		},
+		Template{
+			Name:      "module",
+			NextSteps: []string{"make module"},
+			PreInit:   []hooks.Hook{{Run: "echo module"}},
+			SkipGoMod: true,
+		},
+		Template{Name: "docs", PostInit: []hooks.Hook{{Run: "echo docs"}}},
+		Template{Name: "composed", Extends: "base", Includes: []string{"module", "docs"}},
+		Template{Name: "own", Extends: "base", Includes: []string{"module"}, NextSteps: []string{"make own"}},
+	)
+
+	tests := []struct {
+		name      string
+		nextSteps []string
+		preInit   string
+		postInit  string
+	}{
+		// The highest layer that sets a field wins, includes over Extends
+		{"composed", []string{"make module"}, "echo module", "echo docs"},
+		{"own", []string{"make own"}, "echo module", "echo base"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := GetTemplate(tt.name)
+			if err != nil {
+				t.Fatalf("GetTemplate failed: %v", err)
+			}
+			if !slices.Equal(tmpl.NextSteps, tt.nextSteps) {
+				t.Errorf("NextSteps = %v, want %v", tmpl.NextSteps, tt.nextSteps)
+			}
+			if len(tmpl.PreInit) != 1 || tmpl.PreInit[0].Run != tt.preInit {
+				t.Errorf("PreInit = %v, want %q", tmpl.PreInit, tt.preInit)
+			}
+			if len(tmpl.PostInit) != 1 || tmpl.PostInit[0].Run != tt.postInit {
+				t.Errorf("PostInit = %v, want %q", tmpl.PostInit, tt.postInit)
+			}
+			if !tmpl.SkipGoMod {
+				t.Error("SkipGoMod should be set by the included module template")
+			}
+		})
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		tmpls []Template
+		want  string
+	}{
+		{
+			name: "cycle",
+			tmpls: []Template{
+				{Name: "a", Extends: "b"},
+				{Name: "b", Includes: []string{"c"}},
+				{Name: "c", Extends: "a"},
+			},
+			want: "template cycle: a -> b -> c -> a",
+		},
+		{
+			name:  "self",
+			tmpls: []Template{{Name: "a", Extends: "a"}},
+			want:  "template cycle: a -> a",
+		},
+		{
+			name:  "unknown base",
+			tmpls: []Template{{Name: "a", Extends: "missing"}},
+			want:  `template a: unknown template "missing"`,
+		},
+		{
+			name:  "unknown component",
+			tmpls: []Template{{Name: "a", Includes: []string{"component:missing"}}},
+			want:  `template a: unknown component "missing"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withBuiltIns(t, tt.tmpls...)
+
+			_, err := GetTemplate("a")
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("GetTemplate error = %v, want %q", err, tt.want)
+			}
+			for _, tmpl := range GetAllTemplates() {
+				if tmpl.Name == "a" {
+					t.Error("GetAllTemplates lists a template that can't be resolved")
+				}
+			}
+		})
+	}
+}
+
+func TestBuiltInsExtendGoBase(t *testing.T) {
+	tmpl, err := GetTemplate("go-api")
+	if err != nil {
+		t.Fatalf("GetTemplate failed: %v", err)
+	}
+	for _, path := range []string{"README.md", ".gitignore"} {
+		f, ok := findFile(tmpl, path)
+		if !ok || f.Layer != "go-base" || f.Content == "" {
+			t.Errorf("%s = %+v, want it from go-base", path, f)
+		}
+	}
+	if f, _ := findFile(tmpl, "Dockerfile"); f.Layer != "go-api" {
+		t.Errorf("Dockerfile layer = %q, want go-api", f.Layer)
+	}
+}
+
+func TestCustomTemplateExtendsBuiltIn(t *testing.T) {
+	root := t.TempDir()
+	t.Cleanup(func() { customTemplates = map[string]Template{} })
+
+	writeCustomTemplate(t, root, "acme-api", map[string]string{
+		ManifestFile: `{
+  "extends": "go-api",
+  "includes": ["component:github-actions"],
+  "variables": [{"name": "port", "type": "int", "default": "3000"}],
+  "dependencies": [{"path": "github.com/go-chi/chi/v5", "version": "v5.1.0"}]
+}`,
+		"README.md": "# {{.ProjectName}} at ACME\n",
+	})
+	writeCustomTemplate(t, root, "loop", map[string]string{
+		ManifestFile: `{"extends": "loop"}`,
+	})
+	writeCustomTemplate(t, root, "on-loop", map[string]string{
+		ManifestFile: `{"extends": "loop"}`,
+	})
+
+	err := LoadCustomTemplates(root)
+	if err == nil || !strings.Contains(err.Error(), "template cycle: loop -> loop") {
+		t.Errorf("LoadCustomTemplates error = %v, want the cycle reported", err)
+	}
+	for _, name := range []string{"loop", "on-loop"} {
+		if _, err := GetTemplate(name); err == nil {
+			t.Errorf("%s was loaded despite its broken base", name)
+		}
+	}
+
+	tmpl, err := GetTemplate("acme-api")
+	if err != nil {
+		t.Fatalf("GetTemplate failed: %v", err)
+	}
+	api, _ := GetTemplate("go-api")
+	if len(tmpl.Files) <= len(api.Files) {
+		t.Errorf("got %d files, want the %d of go-api plus the component", len(tmpl.Files), len(api.Files))
+	}
+	if f, _ := findFile(tmpl, "README.md"); f.Layer != "acme-api" || !strings.Contains(f.Content, "ACME") {
+		t.Errorf("README.md = %+v, want the custom one", f)
+	}
+	if f, _ := findFile(tmpl, "cmd/api/main.go"); f.Layer != "go-api" {
+		t.Errorf("cmd/api/main.go layer = %q, want go-api", f.Layer)
+	}
+	if _, ok := findFile(tmpl, ".github/workflows/ci.yml"); !ok {
+		t.Errorf("component file missing, got %v", filePaths(tmpl))
+	}
+	if len(tmpl.Variables) != 1 || tmpl.Variables[0].Default != "3000" {
+		t.Errorf("Variables = %+v, want port defaulting to 3000", tmpl.Variables)
+	}
+	if len(tmpl.Dependencies) != 1 || tmpl.Dependencies[0].String() != "github.com/go-chi/chi/v5@v5.1.0" {
+		t.Errorf("Dependencies = %+v, want chi", tmpl.Dependencies)
+	}
+}
@@ -0,0 +1,272 @@
+package templates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/purnama/scaffold/internal/hooks"
+	"github.com/purnama/scaffold/internal/render"
+)
+
+// ManifestFile is the manifest every directory-based template must contain
+const ManifestFile = "template.json"
+
+// SourceBuiltIn marks templates compiled into the binary
+const SourceBuiltIn = "built-in"
+
+// BuiltInVersion is the version of the built-in templates
+const BuiltInVersion = "1.0.0"
+
+// Manifest describes a directory-based template
+type Manifest struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Version     string     `json:"version"`
+	Category    string     `json:"category"`
+	Tags        []string   `json:"tags"`
+	Extends     string     `json:"extends"`
+	Includes    []string   `json:"includes"`
+	Directories []string   `json:"directories"`
+	Variables   []Variable `json:"variables"`
+
+	// When maps file and directory paths to a When expression, e.g.
+	// {"migrations": "db != 'none'"}
+	When map[string]string `json:"when"`
+
+	// Modes maps file paths to octal permissions, e.g. {"install": "0755"}.
+	// Files whose source is executable or starts with #! get 0755 without it.
+	Modes map[string]string `json:"modes"`
+
+	// Static lists path.Match patterns of files copied byte-for-byte instead
+	// of rendered, e.g. ["assets/*.png"]. Binary files always are.
+	Static []string `json:"static"`
+
+	Dependencies []Dependency `json:"dependencies"`
+	NextSteps    []string     `json:"next_steps"`
+	PreInit      []hooks.Hook `json:"pre_init"`
+	PostInit     []hooks.Hook `json:"post_init"`
+	SkipGoMod    bool         `json:"skip_go_mod"`
+	Dockerfile   string       `json:"dockerfile"` // One of DockerfileProfiles
+}
+
+var customTemplates = map[string]Template{}
+
+// LoadCustomTemplates discovers directory-based templates in dir and registers
+// them next to the built-in ones. Every subdirectory holding a template.json is
+// a template; all other files in it become the template's files.
+// A missing dir is not an error. Templates that fail to load are skipped and
+// reported in the returned error.
+func LoadCustomTemplates(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read custom templates: %w", err)
+	}
+
+	loaded := map[string]Template{}
+	var errs []error
+	for _, e := range entries {
+		if !e.IsDir() || e.Name()[0] == '.' {
+			continue
+		}
+
+		root := filepath.Join(dir, e.Name())
+		t, err := loadTemplateDir(os.DirFS(root), e.Name())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("custom template %s: %w", e.Name(), err))
+			continue
+		}
+		if _, ok := builtInTemplates[t.Name]; ok {
+			errs = append(errs, fmt.Errorf("custom template %s: name %q is already used by a built-in template", e.Name(), t.Name))
+			continue
+		}
+		if _, ok := loaded[t.Name]; ok {
+			errs = append(errs, fmt.Errorf("custom template %s: duplicate template name %q", e.Name(), t.Name))
+			continue
+		}
+		t.Source = root
+		loaded[t.Name] = t
+	}
+
+	// Layers may refer to any template, so check them once all are known.
+	// Dropping a broken template can break the ones built on it, repeat
+	// until nothing changes.
+	customTemplates = loaded
+	for dropped := true; dropped; {
+		dropped = false
+		for _, name := range sortedNames(customTemplates) {
+			tmpl, err := resolve(name, nil)
+			if err == nil {
+				err = checkConditions(tmpl)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("custom template %s: %w", filepath.Base(loaded[name].Source), err))
+				delete(customTemplates, name)
+				dropped = true
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func sortedNames(m map[string]Template) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadTemplateDir reads a template from the root of fsys. dirName is used as
+// the template name when the manifest doesn't set one.
+func loadTemplateDir(fsys fs.FS, dirName string) (Template, error) {
+	data, err := fs.ReadFile(fsys, ManifestFile)
+	if err != nil {
+		return Template{}, fmt.Errorf("missing %s", ManifestFile)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Template{}, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+	}
+	if m.Name == "" {
+		m.Name = dirName
+	}
+	if m.Description == "" {
+		m.Description = "Custom template"
+	}
+
+	for _, v := range m.Variables {
+		if err := v.validate(); err != nil {
+			return Template{}, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+		}
+	}
+	if !slices.Contains(DockerfileProfiles, m.Dockerfile) {
+		return Template{}, fmt.Errorf("invalid %s: unknown dockerfile profile %q", ManifestFile, m.Dockerfile)
+	}
+	if err := hooks.Validate(append(slices.Clone(m.PreInit), m.PostInit...)); err != nil {
+		return Template{}, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+	}
+	if err := validateDependencies(m.Dependencies); err != nil {
+		return Template{}, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+	}
+	for _, pattern := range m.Static {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return Template{}, fmt.Errorf("invalid %s: static pattern %q: %w", ManifestFile, pattern, err)
+		}
+	}
+
+	t := Template{
+		Name:        m.Name,
+		Description: m.Description,
+		Version:     m.Version,
+		Category:    m.Category,
+		Tags:        m.Tags,
+		Extends:     m.Extends,
+		Includes:    m.Includes,
+		Directories: m.Directories,
+		Variables:   m.Variables,
+
+		Dependencies:      m.Dependencies,
+		NextSteps:         m.NextSteps,
+		PreInit:           m.PreInit,
+		PostInit:          m.PostInit,
+		SkipGoMod:         m.SkipGoMod,
+		DockerfileProfile: m.Dockerfile,
+	}
+
+	err = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" || p == HistoryDir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if p == ManifestFile {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		f := FileTemplate{Path: p, Content: string(content), Static: render.IsBinary(content)}
+		for _, pattern := range m.Static {
+			if ok, _ := path.Match(pattern, p); ok {
+				f.Static = true
+			}
+		}
+		if info, err := d.Info(); err == nil && info.Mode().Perm()&0111 != 0 {
+			f.Mode = 0755
+		}
+		t.Files = append(t.Files, f)
+		return nil
+	})
+	if err != nil {
+		return Template{}, err
+	}
+	if len(t.Files) == 0 && t.Extends == "" && len(t.Includes) == 0 {
+		return Template{}, fmt.Errorf("template has no files")
+	}
+
+	sort.Slice(t.Files, func(i, j int) bool {
+		return t.Files[i].Path < t.Files[j].Path
+	})
+
+	// A path in "when" is a file of the template, or else a directory or a
+	// file from a layer below, which DirectoryWhen covers as well
+	for p, expr := range m.When {
+		p = path.Clean(p)
+		i := slices.IndexFunc(t.Files, func(f FileTemplate) bool { return f.Path == p })
+		if i >= 0 {
+			t.Files[i].When = expr
+			continue
+		}
+		if t.DirectoryWhen == nil {
+			t.DirectoryWhen = map[string]string{}
+		}
+		t.DirectoryWhen[p] = expr
+	}
+
+	for p, value := range m.Modes {
+		p = path.Clean(p)
+		i := slices.IndexFunc(t.Files, func(f FileTemplate) bool { return f.Path == p })
+		if i < 0 {
+			return Template{}, fmt.Errorf("invalid %s: modes: %s is not a file of the template", ManifestFile, p)
+		}
+		mode, err := parseMode(value)
+		if err != nil {
+			return Template{}, fmt.Errorf("invalid %s: modes: %s: %w", ManifestFile, p, err)
+		}
+		t.Files[i].Mode = mode
+	}
+	return t, nil
+}
+
+// parseMode parses octal permissions such as "0755" or "644". The owner
+// must be able to read and write the file, scaffold rewrites it on conflicts.
+func parseMode(s string) (fs.FileMode, error) {
+	n, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || n > 0777 {
+		return 0, fmt.Errorf("%q is not an octal permission like 0644", s)
+	}
+	mode := fs.FileMode(n)
+	if mode&0600 != 0600 {
+		return 0, fmt.Errorf("%s must be readable and writable by its owner", s)
+	}
+	return mode, nil
+}
@@ -0,0 +1,261 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeCustomTemplate creates a template directory under root with the given files
+func writeCustomTemplate(t *testing.T, root, dir string, files map[string]string) {
+	t.Helper()
+	for p, content := range files {
+		full := filepath.Join(root, dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadCustomTemplates(t *testing.T) {
+	root := t.TempDir()
+	t.Cleanup(func() { customTemplates = map[string]Template{} })
+
+	writeCustomTemplate(t, root, "svc", map[string]string{
+		ManifestFile: `{
+  "name": "acme-service",
+  "description": "ACME in-house service",
+  "category": "Internal",
+  "directories": ["cmd/server", "internal/app"],
+  "variables": [
+    {"name": "port", "type": "int", "default": "8080", "help": "HTTP port"},
+    {"name": "db", "type": "choice", "choices": ["postgres", "mysql"], "default": "postgres"}
+  ]
+}`,
+		"cmd/server/main.go": "package main\n",
+		"README.md":          "# {{.ProjectName}}\n",
+		".gitignore":         "bin/\n",
+	})
+
+	if err := LoadCustomTemplates(root); err != nil {
+		t.Fatalf("LoadCustomTemplates failed: %v", err)
+	}
+
+	tmpl, err := GetTemplate("acme-service")
+	if err != nil {
+		t.Fatalf("GetTemplate failed: %v", err)
+	}
+	if tmpl.Description != "ACME in-house service" {
+		t.Errorf("Description = %q", tmpl.Description)
+	}
+	if tmpl.Category != "Internal" {
+		t.Errorf("Category = %q, want Internal", tmpl.Category)
+	}
+	if tmpl.Source != filepath.Join(root, "svc") {
+		t.Errorf("Source = %q, want %q", tmpl.Source, filepath.Join(root, "svc"))
+	}
+	if len(tmpl.Directories) != 2 {
+		t.Errorf("expected 2 directories, got %v", tmpl.Directories)
+	}
+	if len(tmpl.Variables) != 2 || tmpl.Variables[0].Name != "port" || tmpl.Variables[1].Type != VarChoice {
+		t.Errorf("unexpected variables: %+v", tmpl.Variables)
+	}
+
+	paths := map[string]string{}
+	for _, f := range tmpl.Files {
+		paths[f.Path] = f.Content
+	}
+	if _, ok := paths[ManifestFile]; ok {
+		t.Error("manifest should not be a template file")
+	}
+	for _, want := range []string{"cmd/server/main.go", "README.md", ".gitignore"} {
+		if _, ok := paths[want]; !ok {
+			t.Errorf("expected file %s, got %v", want, paths)
+		}
+	}
+
+	found := false
+	for _, tmpl := range GetAllTemplates() {
+		if tmpl.Name == "acme-service" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("custom template missing from GetAllTemplates")
+	}
+}
+
+func TestLoadCustomTemplatesMissingDir(t *testing.T) {
+	t.Cleanup(func() { customTemplates = map[string]Template{} })
+
+	if err := LoadCustomTemplates(filepath.Join(t.TempDir(), "nope")); err != nil {
+		t.Errorf("expected no error for missing directory, got %v", err)
+	}
+}
+
+func TestLoadCustomTemplatesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{"missing manifest", map[string]string{"main.go": "package main"}, "missing template.json"},
+		{"invalid manifest", map[string]string{ManifestFile: "{", "main.go": "package main"}, "invalid template.json"},
+		{"no files", map[string]string{ManifestFile: `{"name": "empty"}`}, "no files"},
+		{"bad variable", map[string]string{ManifestFile: `{"variables": [{"name": "port", "type": "int", "default": "http"}]}`, "main.go": "package main"}, "invalid default"},
+		{"shadows built-in", map[string]string{ManifestFile: `{"name": "go-api"}`, "main.go": "package main"}, "built-in"},
+		{"bad dockerfile", map[string]string{ManifestFile: `{"dockerfile": "rust"}`, "main.go": "package main"}, `unknown dockerfile profile "rust"`},
+		{"empty hook", map[string]string{ManifestFile: `{"post_init": [{"dir": "web"}]}`, "main.go": "package main"}, "set exactly one of run and command"},
+		{"bad dependency", map[string]string{ManifestFile: `{"dependencies": [{"path": "github.com/gorilla/websocket", "version": "latest"}]}`, "main.go": "package main"}, `version "latest" is not a semantic version`},
+		{"mode of a missing file", map[string]string{ManifestFile: `{"modes": {"run.sh": "0755"}}`, "main.go": "package main"}, "run.sh is not a file of the template"},
+		{"mode not octal", map[string]string{ManifestFile: `{"modes": {"main.go": "rwx"}}`, "main.go": "package main"}, `"rwx" is not an octal permission`},
+		{"mode too large", map[string]string{ManifestFile: `{"modes": {"main.go": "4755"}}`, "main.go": "package main"}, `"4755" is not an octal permission`},
+		{"mode read-only", map[string]string{ManifestFile: `{"modes": {"main.go": "0444"}}`, "main.go": "package main"}, "readable and writable by its owner"},
+		{"bad static pattern", map[string]string{ManifestFile: `{"static": ["assets/["]}`, "main.go": "package main"}, `static pattern "assets/["`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			t.Cleanup(func() { customTemplates = map[string]Template{} })
+			writeCustomTemplate(t, root, "bad", tt.files)
+			writeCustomTemplate(t, root, "good", map[string]string{
+				ManifestFile: `{"name": "good"}`,
+				"main.go":    "package main",
+			})
+
+			err := LoadCustomTemplates(root)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+
+			// Valid templates are still registered
+			if _, err := GetTemplate("good"); err != nil {
+				t.Errorf("valid template not loaded: %v", err)
+			}
+			// Built-ins are never replaced
+			if tmpl, _ := GetTemplate("go-api"); tmpl.Source != SourceBuiltIn {
+				t.Errorf("built-in go-api was replaced by %s", tmpl.Source)
+			}
+		})
+	}
+}
+
+func TestManifestNameDefaultsToDirectory(t *testing.T) {
+	root := t.TempDir()
+	t.Cleanup(func() { customTemplates = map[string]Template{} })
+	writeCustomTemplate(t, root, "team-lib", map[string]string{
+		ManifestFile: `{}`,
+		"lib.go":     "package lib",
+	})
+
+	if err := LoadCustomTemplates(root); err != nil {
+		t.Fatalf("LoadCustomTemplates failed: %v", err)
+	}
+	if _, err := GetTemplate("team-lib"); err != nil {
+		t.Errorf("expected template named after its directory: %v", err)
+	}
+}
+
+func TestCustomTemplateMetadata(t *testing.T) {
+	root := t.TempDir()
+	t.Cleanup(func() { customTemplates = map[string]Template{} })
+
+	writeCustomTemplate(t, root, "site", map[string]string{
+		ManifestFile: `{
+  "category": "Web",
+  "tags": ["static", "web"],
+  "next_steps": ["npm run dev"],
+  "post_init": [{"command": ["npm", "install"], "dir": "web"}],
+  "skip_go_mod": true,
+  "dockerfile": "cli"
+}`,
+		"web/package.json": "{}\n",
+	})
+	writeCustomTemplate(t, root, "site-plus", map[string]string{
+		ManifestFile:   `{"extends": "site", "tags": ["extra"]}`,
+		"web/extra.js": "\n",
+	})
+
+	if err := LoadCustomTemplates(root); err != nil {
+		t.Fatalf("LoadCustomTemplates failed: %v", err)
+	}
+
+	for _, name := range []string{"site", "site-plus"} {
+		tmpl, err := GetTemplate(name)
+		if err != nil {
+			t.Fatalf("GetTemplate(%s) failed: %v", name, err)
+		}
+		if tmpl.Category != "Web" {
+			t.Errorf("%s: Category = %q, want Web", name, tmpl.Category)
+		}
+		if len(tmpl.NextSteps) != 1 || tmpl.NextSteps[0] != "npm run dev" {
+			t.Errorf("%s: NextSteps = %v", name, tmpl.NextSteps)
+		}
+		if len(tmpl.PostInit) != 1 || tmpl.PostInit[0].String() != "npm install" || tmpl.PostInit[0].Dir != "web" {
+			t.Errorf("%s: PostInit = %+v", name, tmpl.PostInit)
+		}
+		if !tmpl.SkipGoMod {
+			t.Errorf("%s: SkipGoMod = false", name)
+		}
+		if tmpl.DockerfileProfile != DockerfileCLI {
+			t.Errorf("%s: DockerfileProfile = %q, want cli", name, tmpl.DockerfileProfile)
+		}
+	}
+
+	// Set fields aren't inherited
+	if tmpl, _ := GetTemplate("site-plus"); strings.Join(tmpl.Tags, ",") != "extra" {
+		t.Errorf("Tags = %v, want only its own", tmpl.Tags)
+	}
+}
+
+func TestCustomTemplateModesAndStatic(t *testing.T) {
+	root := t.TempDir()
+	t.Cleanup(func() { customTemplates = map[string]Template{} })
+	writeCustomTemplate(t, root, "tool", map[string]string{
+		ManifestFile:         `{"modes": {"secrets.env": "0600", "./hooks/pre-commit": "755"}, "static": ["charts/*.yaml"]}`,
+		"main.go":            "package main\n",
+		"setup.sh":           "#!/bin/sh\necho {{.ProjectName}}\n",
+		"bin/run":            "exec go run .\n",
+		"secrets.env":        "TOKEN=\n",
+		"hooks/pre-commit":   "go vet ./...\n",
+		"charts/values.yaml": "name: {{ .Release.Name }}\n",
+		"logo.png":           "\x89PNG\r\n\x1a\n\x00{{",
+	})
+	os.Chmod(filepath.Join(root, "tool", "bin", "run"), 0755)
+
+	if err := LoadCustomTemplates(root); err != nil {
+		t.Fatalf("LoadCustomTemplates failed: %v", err)
+	}
+	tmpl, err := GetTemplate("tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]struct {
+		mode   os.FileMode
+		static bool
+	}{
+		"main.go":            {0, false},
+		"setup.sh":           {0, false}, // The shebang makes it 0755 when generated
+		"bin/run":            {0755, false},
+		"secrets.env":        {0600, false},
+		"hooks/pre-commit":   {0755, false},
+		"charts/values.yaml": {0, true},
+		"logo.png":           {0, true},
+	}
+	for _, f := range tmpl.Files {
+		w, ok := want[f.Path]
+		if !ok {
+			t.Errorf("unexpected file %s", f.Path)
+			continue
+		}
+		if f.Mode != w.mode || f.Static != w.static {
+			t.Errorf("%s: Mode = %04o, Static = %v, want %04o, %v", f.Path, f.Mode, f.Static, w.mode, w.static)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Dependency is a module the generated code imports. It is written to
+// go.mod as a require at a pinned version, so init needs no network;
+// "go mod tidy" fetches it and fills go.sum later.
+type Dependency struct {
+	Path    string `json:"path"`    // Module path, e.g. github.com/gorilla/websocket
+	Version string `json:"version"` // Semantic version, e.g. v1.5.3
+}
+
+// String returns the dependency as go get takes it, path@version
+func (d Dependency) String() string {
+	return d.Path + "@" + d.Version
+}
+
+var (
+	modulePathRe    = regexp.MustCompile(`^[a-z0-9.-]+\.[a-z]{2,}(/[A-Za-z0-9._~+-]+)*$`)
+	moduleVersionRe = regexp.MustCompile(`^v(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(-[0-9A-Za-z.-]+)?(\+incompatible)?$`)
+	majorSuffixRe   = regexp.MustCompile(`/v([2-9]|[1-9][0-9]+)$`)
+)
+
+// validate checks the module path and version, including that a /vN path
+// suffix matches the major version
+func (d Dependency) validate() error {
+	if !modulePathRe.MatchString(d.Path) {
+		return fmt.Errorf("dependency %q: invalid module path", d.Path)
+	}
+	m := moduleVersionRe.FindStringSubmatch(d.Version)
+	if m == nil {
+		return fmt.Errorf("dependency %s: version %q is not a semantic version like v1.2.3", d.Path, d.Version)
+	}
+	major := m[1]
+	if s := majorSuffixRe.FindStringSubmatch(d.Path); s != nil {
+		if major != s[1] {
+			return fmt.Errorf("dependency %s: version %s doesn't match the /v%s suffix", d.Path, d.Version, s[1])
+		}
+	} else if major != "0" && major != "1" && !strings.HasSuffix(d.Version, "+incompatible") && !strings.HasPrefix(d.Path, "gopkg.in/") {
+		return fmt.Errorf("dependency %s: version %s needs a /v%s path suffix", d.Path, d.Version, major)
+	}
+	return nil
+}
+
+// validateDependencies checks every dependency and that no module is listed
+// twice
+func validateDependencies(deps []Dependency) error {
+	seen := map[string]bool{}
+	for _, d := range deps {
+		if err := d.validate(); err != nil {
+			return err
+		}
+		if seen[d.Path] {
+			return fmt.Errorf("dependency %s is listed twice", d.Path)
+		}
+		seen[d.Path] = true
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package templates
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestDependencyValidate(t *testing.T) {
+	tests := []struct {
+		dep  Dependency
+		want string
+	}{
+		{Dependency{"github.com/gorilla/websocket", "v1.5.3"}, ""},
+		{Dependency{"github.com/redis/go-redis/v9", "v9.7.0"}, ""},
+		{Dependency{"gopkg.in/yaml.v3", "v3.0.1"}, ""},
+		{Dependency{"github.com/99designs/gqlgen", "v0.17.55"}, ""},
+		{Dependency{"golang.org/x/crypto", "v0.28.0-rc.1"}, ""},
+		{Dependency{"github.com/docker/docker", "v27.3.1+incompatible"}, ""},
+		{Dependency{"websocket", "v1.5.3"}, "invalid module path"},
+		{Dependency{"github.com/gorilla/websocket", ""}, "is not a semantic version"},
+		{Dependency{"github.com/gorilla/websocket", "latest"}, "is not a semantic version"},
+		{Dependency{"github.com/gorilla/websocket", "1.5.3"}, "is not a semantic version"},
+		{Dependency{"github.com/redis/go-redis/v9", "v8.11.5"}, "doesn't match the /v9 suffix"},
+		{Dependency{"github.com/redis/go-redis", "v9.7.0"}, "needs a /v9 path suffix"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dep.String(), func(t *testing.T) {
+			err := tt.dep.validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("validate failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("validate error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	dup := []Dependency{{"github.com/a/b", "v1.0.0"}, {"github.com/a/b", "v1.1.0"}}
+	if err := validateDependencies(dup); err == nil || !strings.Contains(err.Error(), "listed twice") {
+		t.Errorf("validateDependencies error = %v, want the duplicate reported", err)
+	}
+}
+
+var thirdPartyImportRe = regexp.MustCompile(`(?m)^\s*(?:[\w.]+\s+)?"([a-z0-9.-]+\.[a-z]{2,}/[^"]*)"\s*$`)
+
+// TestBuiltInDependencies checks that built-in templates require every
+// third-party module their Go files import
+func TestBuiltInDependencies(t *testing.T) {
+	for _, tmpl := range GetAllTemplates() {
+		if err := validateDependencies(tmpl.Dependencies); err != nil {
+			t.Errorf("template %s: %v", tmpl.Name, err)
+		}
+		if _, ownGoMod := findFile(tmpl, "go.mod"); tmpl.SkipGoMod || ownGoMod {
+			continue
+		}
+		for _, f := range tmpl.Files {
+			if !strings.HasSuffix(f.Path, ".go") {
+				continue
+			}
+			for _, m := range thirdPartyImportRe.FindAllStringSubmatch(f.Content, -1) {
+				if !requires(tmpl.Dependencies, m[1]) {
+					t.Errorf("template %s: %s imports %s, which no dependency provides", tmpl.Name, f.Path, m[1])
+				}
+			}
+		}
+	}
+}
+
+func requires(deps []Dependency, pkg string) bool {
+	for _, d := range deps {
+		if pkg == d.Path || strings.HasPrefix(pkg, d.Path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDependencyLayers(t *testing.T) {
+	withBuiltIns(t,
+		Template{Name: "base", Dependencies: []Dependency{
+			{"github.com/a/one", "v1.0.0"},
+			{"github.com/a/two", "v1.0.0"},
+		}},
+		Template{Name: "child", Extends: "base", Dependencies: []Dependency{
+			{"github.com/a/two", "v1.2.0"},
+			{"github.com/a/three", "v0.1.0"},
+		}},
+	)
+
+	tmpl, err := GetTemplate("child")
+	if err != nil {
+		t.Fatalf("GetTemplate failed: %v", err)
+	}
+	var got []string
+	for _, d := range tmpl.Dependencies {
+		got = append(got, d.String())
+	}
+	want := "github.com/a/one@v1.0.0,github.com/a/two@v1.2.0,github.com/a/three@v0.1.0"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Dependencies = %s, want %s", strings.Join(got, ","), want)
+	}
+}
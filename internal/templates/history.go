@@ -0,0 +1,127 @@
+package templates
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Earlier versions of built-in templates, one directory per version laid out
+// like a custom template. Files carry a .tmpl suffix so the go tool and
+// gofmt leave them alone.
+//
+//go:embed all:history
+var historyFS embed.FS
+
+// HistoryDir is the directory of a custom template holding its earlier
+// versions, e.g. .history/1.0.0/template.json
+const HistoryDir = ".history"
+
+// Versions lists the versions of a template available offline, oldest first
+func Versions(name string) ([]string, error) {
+	t, err := GetTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []fs.DirEntry
+	if t.Source == SourceBuiltIn {
+		entries, err = fs.ReadDir(historyFS, path.Join("history", name))
+	} else {
+		entries, err = os.ReadDir(filepath.Join(t.Source, HistoryDir))
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	var versions []string
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != t.Version {
+			versions = append(versions, e.Name())
+		}
+	}
+	if t.Version != "" {
+		versions = append(versions, t.Version)
+	}
+	slices.SortFunc(versions, CompareVersions)
+	return versions, nil
+}
+
+// GetTemplateVersion returns a template as it was at the given version: the
+// current template, or an earlier one from the history embedded in the
+// binary or the custom template's .history directory
+func GetTemplateVersion(name, version string) (Template, error) {
+	t, err := GetTemplate(name)
+	if err != nil {
+		return Template{}, err
+	}
+	if t.Version == version {
+		return t, nil
+	}
+
+	versions, err := Versions(name)
+	if err != nil {
+		return Template{}, err
+	}
+	if !slices.Contains(versions, version) {
+		return Template{}, fmt.Errorf("version %q of template %s is not available, known versions: %s", version, name, strings.Join(versions, ", "))
+	}
+
+	var old Template
+	if t.Source == SourceBuiltIn {
+		sub, err := fs.Sub(historyFS, path.Join("history", name, version))
+		if err != nil {
+			return Template{}, err
+		}
+		if old, err = loadTemplateDir(sub, name); err != nil {
+			return Template{}, fmt.Errorf("template %s %s: %w", name, version, err)
+		}
+		for i := range old.Files {
+			old.Files[i].Path = strings.TrimSuffix(old.Files[i].Path, ".tmpl")
+		}
+		old.Source = SourceBuiltIn
+	} else {
+		dir := filepath.Join(t.Source, HistoryDir, version)
+		if old, err = loadTemplateDir(os.DirFS(dir), name); err != nil {
+			return Template{}, fmt.Errorf("template %s %s: %w", name, version, err)
+		}
+		old.Source = dir
+	}
+	old.Version = version
+	return resolveLayers(old, nil)
+}
+
+// CompareVersions orders dotted versions such as 1.2.0 and 1.10.0
+// numerically, part by part. Parts that aren't numbers compare as strings.
+func CompareVersions(a, b string) int {
+	pa := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y string
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		nx, errx := strconv.Atoi(x)
+		ny, erry := strconv.Atoi(y)
+		if errx == nil && erry == nil {
+			if nx != ny {
+				return nx - ny
+			}
+			continue
+		}
+		if c := strings.Compare(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
@@ -0,0 +1,133 @@
+package templates
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuiltInHistory(t *testing.T) {
+	versions, err := Versions("go-api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, _ := GetTemplate("go-api")
+	if len(versions) < 2 || versions[len(versions)-1] != current.Version {
+		t.Fatalf("Versions(go-api) = %v, want earlier versions then %s", versions, current.Version)
+	}
+
+	old, err := GetTemplateVersion("go-api", "1.0.0")
+	if err != nil {
+		t.Fatalf("GetTemplateVersion() error = %v", err)
+	}
+	if old.Version != "1.0.0" || old.Source != SourceBuiltIn || len(old.Variables) == 0 {
+		t.Errorf("unexpected template: %+v", old)
+	}
+	var paths []string
+	for _, f := range old.Files {
+		paths = append(paths, f.Path)
+	}
+	for _, want := range []string{".gitignore", "Dockerfile", "cmd/api/main.go"} {
+		if !slices.Contains(paths, want) {
+			t.Errorf("go-api 1.0.0 should have %s, has %v", want, paths)
+		}
+	}
+
+	// Every built-in template's history must load
+	for _, tmpl := range GetAllTemplates() {
+		versions, err := Versions(tmpl.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range versions {
+			if _, err := GetTemplateVersion(tmpl.Name, v); err != nil {
+				t.Errorf("GetTemplateVersion(%s, %s) error = %v", tmpl.Name, v, err)
+			}
+		}
+	}
+}
+
+func TestBuiltInHistoryBeforeFavicons(t *testing.T) {
+	// The favicons came with 1.1.0, projects generated before compare with
+	// the files they got
+	tests := []struct{ name, favicon string }{
+		{"fullstack", "frontend/public/favicon.ico"},
+		{"go-web-htmx", "static/favicon.ico"},
+	}
+	for _, tt := range tests {
+		old, err := GetTemplateVersion(tt.name, "1.0.0")
+		if err != nil {
+			t.Fatalf("GetTemplateVersion(%s, 1.0.0) error = %v", tt.name, err)
+		}
+		if _, ok := findFile(old, tt.favicon); ok {
+			t.Errorf("%s 1.0.0 has %s", tt.name, tt.favicon)
+		}
+		current, _ := GetTemplate(tt.name)
+		if _, ok := findFile(current, tt.favicon); !ok || current.Version == old.Version {
+			t.Errorf("%s %s should add %s", tt.name, current.Version, tt.favicon)
+		}
+	}
+}
+
+func TestCustomHistory(t *testing.T) {
+	root := t.TempDir()
+	t.Cleanup(func() { customTemplates = map[string]Template{} })
+
+	writeCustomTemplate(t, root, "svc", map[string]string{
+		ManifestFile:                     `{"name": "svc", "version": "1.10.0"}`,
+		"README.md":                      "new\n",
+		".history/1.2.0/" + ManifestFile: `{"name": "svc"}`,
+		".history/1.2.0/README.md":       "old\n",
+		".history/1.9.0/" + ManifestFile: `{"name": "svc", "version": "1.9.0"}`,
+		".history/1.9.0/README.md":       "older\n",
+		".history/broken/README.md":      "no manifest\n",
+	})
+	if err := LoadCustomTemplates(root); err != nil {
+		t.Fatal(err)
+	}
+
+	current, _ := GetTemplate("svc")
+	if len(current.Files) != 1 {
+		t.Errorf("history should not be part of the template, files: %+v", current.Files)
+	}
+
+	versions, _ := Versions("svc")
+	if !slices.Equal(versions, []string{"1.2.0", "1.9.0", "1.10.0", "broken"}) {
+		t.Errorf("Versions() = %v", versions)
+	}
+
+	old, err := GetTemplateVersion("svc", "1.2.0")
+	if err != nil {
+		t.Fatalf("GetTemplateVersion() error = %v", err)
+	}
+	if old.Version != "1.2.0" || old.Files[0].Content != "old\n" {
+		t.Errorf("unexpected template: %+v", old)
+	}
+
+	if _, err := GetTemplateVersion("svc", "broken"); err == nil {
+		t.Error("a version without manifest should fail to load")
+	}
+	if _, err := GetTemplateVersion("svc", "0.1.0"); err == nil || !strings.Contains(err.Error(), "1.2.0, 1.9.0") {
+		t.Errorf("unknown version error = %v, should list the known versions", err)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.2.0", "1.10.0", -1},
+		{"v2", "1.9", 1},
+		{"1.0", "1.0.1", -1},
+		{"1.x", "1.y", -1},
+	}
+
+	for _, tt := range tests {
+		got := CompareVersions(tt.a, tt.b)
+		if (got < 0) != (tt.want < 0) || (got > 0) != (tt.want > 0) {
+			t.Errorf("CompareVersions(%q, %q) = %d, want sign of %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -3,9 +3,12 @@ package templates
 import (
 	"embed"
 	"fmt"
+	"io/fs"
+
+	"github.com/purnama/scaffold/internal/hooks"
 )
 
-//go:embed embedded/*.tmpl
+//go:embed embedded/*.tmpl embedded/assets
 var embeddedFS embed.FS
 
 // loadEmbedded loads a template from embedded files
@@ -17,19 +20,68 @@ func loadEmbedded(name string) string {
 	return string(data)
 }
 
+// loadAsset loads a static file, such as an image, from embedded/assets
+func loadAsset(name string) string {
+	return loadEmbedded("assets/" + name)
+}
+
 // Template represents a project template
 type Template struct {
 	Name        string
 	Description string
+	Version     string   // Recorded in .scaffold.json, BuiltInVersion for built-in templates
+	Category    string   // Grouping shown by "scaffold list", CategoryProject when empty
+	Tags        []string // Keywords "scaffold list --tag" filters on
+	Source      string   // SourceBuiltIn or the directory a custom template was loaded from
+	Extends     string   // Template whose directories, files and variables this one builds on
+	Includes    []string // Layered on top of Extends in order: template names or "component:<name>"
 	Directories []string
 	Files       []FileTemplate
+	Variables   []Variable // Extra inputs, available as {{.Vars.<name>}}
+
+	// DirectoryWhen holds When expressions for directories, by path. Files
+	// below a directory whose expression is false are left out too, as is a
+	// file with that exact path.
+	DirectoryWhen map[string]string
+
+	Dependencies      []Dependency // Required in the generated go.mod
+	NextSteps         []string     // Commands shown after init, run from the project directory
+	PreInit           []hooks.Hook // Run in the staged project before it is moved into place
+	PostInit          []hooks.Hook // Run in the new project after init
+	SkipGoMod         bool         // Don't run go mod init, for projects without a root Go module
+	DockerfileProfile string       // Dockerfile written by --docker, one of DockerfileProfiles
 }
 
+// Categories of the built-in templates, in the order "scaffold list" shows them
+const (
+	CategoryProject   = "Project"
+	CategoryFullstack = "Fullstack"
+	CategoryLearning  = "Learning"
+	CategorySkill     = "Skill"
+)
+
+// Categories lists the built-in categories in display order
+var Categories = []string{CategoryProject, CategoryFullstack, CategoryLearning, CategorySkill}
+
+// Dockerfile profiles
+const (
+	DockerfileDefault = ""    // Single stage build of the root package
+	DockerfileAPI     = "api" // Multi-stage build of ./cmd/api, exposing 8080
+	DockerfileCLI     = "cli" // Multi-stage build of the root package as the entrypoint
+)
+
+// DockerfileProfiles lists the valid values of Template.DockerfileProfile
+var DockerfileProfiles = []string{DockerfileDefault, DockerfileAPI, DockerfileCLI}
+
 // FileTemplate represents a file to be generated
 type FileTemplate struct {
 	Path     string
 	Template string
 	Content  string
+	Layer    string      // Template or "component:<name>" the file comes from, set by GetTemplate
+	When     string      // Only generate the file when this expression holds, see Select
+	Mode     fs.FileMode // Permissions, 0755 for content starting with #! and 0644 otherwise when zero
+	Static   bool        // Copied byte-for-byte instead of rendered, e.g. images
 }
 
 // Template content loaded from embedded files
@@ -356,6 +408,8 @@ var (
 	tddBDDMainTmpl         = loadEmbedded("tdd_bdd_main.tmpl")
 	tddBDDTestTmpl         = loadEmbedded("tdd_bdd_test.tmpl")
 	tddExercisesReadmeTmpl = loadEmbedded("tdd_exercises_readme.tmpl")
+	// Static assets, copied as they are
+	faviconAsset = loadAsset("favicon.ico")
 )
 
 // Generator functions
@@ -369,6 +423,13 @@ func genPostcssConfig() string      { return loadEmbedded("fullstack_postcss_con
 
 func init() {
 	initBuiltInTemplates()
+	for name, t := range builtInTemplates {
+		t.Source = SourceBuiltIn
+		if t.Version == "" {
+			t.Version = BuiltInVersion
+		}
+		builtInTemplates[name] = t
+	}
 }
 
 var builtInTemplates map[string]Template
@@ -377,9 +438,23 @@ var builtInTemplates map[string]Template
 // This must be called in init() after all template strings are populated
 func initBuiltInTemplates() {
 	builtInTemplates = map[string]Template{
+		"go-base": {
+			Name:        "go-base",
+			Description: "Minimal Go module with README and .gitignore, the base of most Go templates",
+			Category:    CategoryProject,
+			Tags:        []string{"go"},
+			Files: []FileTemplate{
+				{Path: "README.md", Content: readmeTmpl},
+				{Path: ".gitignore", Content: gitignoreGoTmpl},
+			},
+		},
 		"go-api": {
 			Name:        "go-api",
 			Description: "Go REST API with clean architecture",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "http", "rest", "api"},
+			Extends:     "go-base",
+			Version:     "1.1.0", // 1.1.0: Dockerfile uses the port variable and works without go.sum
 			Directories: []string{
 				"cmd/api",
 				"internal/handler",
@@ -404,13 +479,22 @@ func initBuiltInTemplates() {
 				{Path: "internal/validator/validator.go", Content: goAPIValidatorTmpl},
 				{Path: "pkg/config/config.go", Content: goConfigTmpl},
 				{Path: "Dockerfile", Content: goAPIDockerfileTmpl},
-				{Path: "README.md", Content: readmeTmpl},
-				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			Variables: []Variable{
+				{Name: "port", Type: VarInt, Default: "8080", Pattern: `^[0-9]{2,5}$`, Prompt: "HTTP port", Help: "Default port the API listens on when PORT is not set"},
+			},
+			NextSteps: []string{
+				"go mod tidy",
+				"go run ./cmd/api",
+			},
+			DockerfileProfile: DockerfileAPI,
 		},
 		"go-cli": {
 			Name:        "go-cli",
 			Description: "Go CLI application with Cobra",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "cli", "cobra"},
+			Extends:     "go-base",
 			Directories: []string{
 				"cmd",
 				"internal/config",
@@ -427,13 +511,23 @@ func initBuiltInTemplates() {
 				{Path: "internal/output/output.go", Content: goCLIOutputTmpl},
 				{Path: "Makefile", Content: goCLIMakefileTmpl},
 				{Path: ".goreleaser.yaml", Content: goCLIGoreleaserTmpl},
-				{Path: "README.md", Content: readmeTmpl},
-				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			Dependencies: []Dependency{
+				{Path: "github.com/spf13/cobra", Version: "v1.10.2"},
+				{Path: "gopkg.in/yaml.v3", Version: "v3.0.1"},
+			},
+			NextSteps: []string{
+				"go mod tidy",
+				"go run .",
+			},
+			DockerfileProfile: DockerfileCLI,
 		},
 		"go-lib": {
 			Name:        "go-lib",
 			Description: "Go library/package",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "library"},
+			Extends:     "go-base",
 			Directories: []string{
 				"internal",
 				"examples",
@@ -448,14 +542,14 @@ func initBuiltInTemplates() {
 				{Path: "benchmark_test.go", Content: goLibBenchmarkTmpl},
 				{Path: "examples/main.go", Content: goLibExampleTmpl},
 				{Path: ".github/workflows/ci.yml", Content: goLibCITmpl},
-				{Path: "README.md", Content: readmeTmpl},
-				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
 		},
 		// Learning templates
 		"learn-concurrency": {
 			Name:        "learn-concurrency",
 			Description: "Learn Go concurrency patterns",
+			Category:    CategoryLearning,
+			Tags:        []string{"go", "concurrency", "goroutines", "channels"},
 			Directories: []string{
 				"01-goroutines",
 				"02-channels",
@@ -472,10 +566,15 @@ func initBuiltInTemplates() {
 				{Path: "05-patterns/main.go", Content: learnPatternsTmpl},
 				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			NextSteps: []string{
+				"cd 01-goroutines && go run main.go",
+			},
 		},
 		"learn-testing": {
 			Name:        "learn-testing",
 			Description: "Learn Go testing techniques",
+			Category:    CategoryLearning,
+			Tags:        []string{"go", "testing"},
 			Directories: []string{
 				"unit",
 				"table",
@@ -492,10 +591,15 @@ func initBuiltInTemplates() {
 				{Path: "benchmark/string_builder_test.go", Content: learnBenchTestTmpl},
 				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			NextSteps: []string{
+				"go test -v ./...",
+			},
 		},
 		"learn-dsa": {
 			Name:        "learn-dsa",
 			Description: "Practice Data Structures & Algorithms",
+			Category:    CategoryLearning,
+			Tags:        []string{"go", "algorithms", "data-structures"},
 			Directories: []string{
 				"datastructures/stack",
 				"datastructures/queue",
@@ -523,10 +627,17 @@ func initBuiltInTemplates() {
 				{Path: "algorithms/recursion/recursion_test.go", Content: dsaRecursionTestTmpl},
 				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			NextSteps: []string{
+				"go test -v ./...   # See which tests fail",
+				"# Then implement the functions!",
+			},
 		},
 		"go-grpc": {
 			Name:        "go-grpc",
 			Description: "Go gRPC service template",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "grpc", "protobuf"},
+			Extends:     "go-base",
 			Directories: []string{
 				"cmd/server",
 				"cmd/client",
@@ -544,13 +655,22 @@ func initBuiltInTemplates() {
 				{Path: "proto/service.proto", Content: goGRPCProtoTmpl},
 				{Path: "Makefile", Content: goGRPCMakefileTmpl},
 				{Path: "Dockerfile", Content: goGRPCDockerfileTmpl},
-				{Path: "README.md", Content: readmeTmpl},
-				{Path: ".gitignore", Content: gitignoreGoTmpl},
+			},
+			Dependencies: []Dependency{
+				{Path: "google.golang.org/grpc", Version: "v1.67.1"},
+				{Path: "google.golang.org/protobuf", Version: "v1.35.1"},
+			},
+			NextSteps: []string{
+				"go mod tidy",
+				"make run-server",
 			},
 		},
 		"go-worker": {
 			Name:        "go-worker",
 			Description: "Background worker with job queue",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "worker", "queue"},
+			Extends:     "go-base",
 			Directories: []string{
 				"cmd/worker",
 				"internal/job",
@@ -569,13 +689,18 @@ func initBuiltInTemplates() {
 				{Path: "internal/metrics/metrics.go", Content: goWorkerMetricsTmpl},
 				{Path: "internal/worker/pool.go", Content: goWorkerPoolTmpl},
 				{Path: "Dockerfile", Content: goWorkerDockerfileTmpl},
-				{Path: "README.md", Content: readmeTmpl},
-				{Path: ".gitignore", Content: gitignoreGoTmpl},
+			},
+			NextSteps: []string{
+				"go mod tidy",
+				"go run ./cmd/worker",
 			},
 		},
 		"go-tui": {
 			Name:        "go-tui",
 			Description: "Terminal UI app with Bubbletea",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "tui", "bubbletea"},
+			Extends:     "go-base",
 			Directories: []string{
 				"internal/ui",
 				"internal/ui/styles",
@@ -591,13 +716,23 @@ func initBuiltInTemplates() {
 				{Path: "internal/ui/components/list.go", Content: goTUIListTmpl},
 				{Path: "internal/ui/components/input.go", Content: goTUIInputTmpl},
 				{Path: "internal/ui/views/home.go", Content: goTUIHomeTmpl},
-				{Path: "README.md", Content: readmeTmpl},
-				{Path: ".gitignore", Content: gitignoreGoTmpl},
+			},
+			Dependencies: []Dependency{
+				{Path: "github.com/charmbracelet/bubbles", Version: "v0.21.0"},
+				{Path: "github.com/charmbracelet/bubbletea", Version: "v1.3.10"},
+				{Path: "github.com/charmbracelet/lipgloss", Version: "v1.1.0"},
+			},
+			NextSteps: []string{
+				"go mod tidy",
+				"go run .",
 			},
 		},
 		"fullstack": {
 			Name:        "fullstack",
 			Description: "Go backend + React/Vite/Bun/Tailwind frontend",
+			Category:    CategoryFullstack,
+			Tags:        []string{"go", "react", "vite", "bun", "tailwind"},
+			Version:     "1.1.0", // 1.1.0: adds a favicon to the frontend
 			Directories: []string{
 				"backend/cmd/api",
 				"backend/internal/handler",
@@ -605,6 +740,7 @@ func initBuiltInTemplates() {
 				"frontend/src/components",
 				"frontend/src/hooks",
 				"frontend/src/lib",
+				"frontend/public",
 			},
 			Files: []FileTemplate{
 				// Backend files
@@ -620,6 +756,7 @@ func initBuiltInTemplates() {
 				{Path: "frontend/tailwind.config.js", Content: fullstackTailwindConfigTmpl},
 				{Path: "frontend/postcss.config.js", Content: fullstackPostcssConfigTmpl},
 				{Path: "frontend/index.html", Content: fullstackIndexHtmlTmpl},
+				{Path: "frontend/public/favicon.ico", Content: faviconAsset, Static: true},
 				{Path: "frontend/src/main.tsx", Content: fullstackMainTsxTmpl},
 				{Path: "frontend/src/App.tsx", Content: fullstackAppTsxTmpl},
 				{Path: "frontend/src/index.css", Content: fullstackIndexCssTmpl},
@@ -630,11 +767,20 @@ func initBuiltInTemplates() {
 				{Path: "Makefile", Content: fullstackMakefileTmpl},
 				{Path: ".gitignore", Content: fullstackGitignore},
 			},
+			NextSteps: []string{
+				"make dev    # Runs backend + frontend",
+			},
+			PostInit: []hooks.Hook{
+				{Command: []string{"bun", "install"}, Dir: "frontend", ContinueOnError: true},
+			},
+			SkipGoMod: true,
 		},
 		// Skill Coding Templates
 		"algorithm-challenges": {
 			Name:        "algorithm-challenges",
 			Description: "Algo challenges (Two Sum, LRU Cache, Merge K Lists)",
+			Category:    CategorySkill,
+			Tags:        []string{"go", "algorithms"},
 			Directories: []string{
 				"easy",
 				"medium",
@@ -651,6 +797,8 @@ func initBuiltInTemplates() {
 		"system-design-exercise": {
 			Name:        "system-design-exercise",
 			Description: "System Design practice (URL Shortener doc + interfaces)",
+			Category:    CategorySkill,
+			Tags:        []string{"go", "system-design"},
 			Directories: []string{
 				"design-docs",
 				"prototypes",
@@ -661,10 +809,13 @@ func initBuiltInTemplates() {
 				{Path: "prototypes/main.go", Content: sysDesignInterfacesTmpl},
 				{Path: "go.mod", Content: "module system-design\ngo 1.22"},
 			},
+			SkipGoMod: true,
 		},
 		"challenge-30days": {
 			Name:        "challenge-30days",
 			Description: "30-day Go coding challenge",
+			Category:    CategorySkill,
+			Tags:        []string{"go", "challenge"},
 			Directories: []string{
 				"week1/day01_hello",
 				"week1/day02_variables",
@@ -690,10 +841,17 @@ func initBuiltInTemplates() {
 				{Path: "week4/day22_concurrency/main_test.go", Content: challengeDay22TestTmpl},
 				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			NextSteps: []string{
+				"cd week1/day01_hello",
+				"go test -v          # See failing tests",
+				"# Implement the functions, then move to next day!",
+			},
 		},
 		"mini-project": {
 			Name:        "mini-project",
 			Description: "Mini projects to build (todo-cli, url-shortener)",
+			Category:    CategorySkill,
+			Tags:        []string{"go", "projects"},
 			Directories: []string{
 				"todo-cli",
 				"url-shortener",
@@ -712,10 +870,17 @@ func initBuiltInTemplates() {
 				{Path: "kv-store/main.go", Content: miniProjKVTmpl},
 				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			NextSteps: []string{
+				"cd todo-cli         # or url-shortener",
+				"go test -v          # See what to implement",
+				"# Read README.md for requirements",
+			},
 		},
 		"refactoring-exercise": {
 			Name:        "refactoring-exercise",
 			Description: "Practice refactoring bad code",
+			Category:    CategorySkill,
+			Tags:        []string{"go", "refactoring"},
 			Directories: []string{
 				"exercises/01_long_function",
 				"exercises/02_magic_numbers",
@@ -731,10 +896,17 @@ func initBuiltInTemplates() {
 				{Path: "exercises/03_poor_naming/hints.md", Content: refactoring03HintsTmpl},
 				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			NextSteps: []string{
+				"cd exercises/01_long_function",
+				"# Look at before.go, read hints.md",
+				"# Create after.go with your refactored code",
+			},
 		},
 		"code-review-exercise": {
 			Name:        "code-review-exercise",
 			Description: "Find bugs in code (code review practice)",
+			Category:    CategorySkill,
+			Tags:        []string{"go", "code-review", "debugging"},
 			Directories: []string{
 				"bugs/01_off_by_one",
 				"bugs/02_nil_pointer",
@@ -750,11 +922,18 @@ func initBuiltInTemplates() {
 				{Path: "bugs/03_race_condition/buggy_test.go", Content: codeReview03TestTmpl},
 				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			NextSteps: []string{
+				"cd bugs/01_off_by_one",
+				"go test -v          # See the failing tests",
+				"# Find and fix the bugs in buggy.go!",
+			},
 		},
 		// Learning Specific Skills Templates
 		"learn-generics": {
 			Name:        "learn-generics",
 			Description: "Learn Go generics (type parameters & constraints)",
+			Category:    CategoryLearning,
+			Tags:        []string{"go", "generics"},
 			Directories: []string{
 				"basics",
 				"constraints",
@@ -770,10 +949,20 @@ func initBuiltInTemplates() {
 				{Path: "practical/main_test.go", Content: learnGenericsPracticalTestTmpl},
 				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			Dependencies: []Dependency{
+				{Path: "golang.org/x/exp", Version: "v0.0.0-20220909182711-5c715a9e8561"},
+			},
+			NextSteps: []string{
+				"go mod tidy",
+				"cd basics && go test -v",
+				"# Implement generic functions!",
+			},
 		},
 		"learn-context": {
 			Name:        "learn-context",
 			Description: "Learn context.Context (cancellation & timeout)",
+			Category:    CategoryLearning,
+			Tags:        []string{"go", "context"},
 			Directories: []string{
 				"cancellation",
 				"timeout",
@@ -789,10 +978,16 @@ func initBuiltInTemplates() {
 				{Path: "values/main_test.go", Content: learnContextValuesTestTmpl},
 				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			NextSteps: []string{
+				"cd cancellation && go test -v",
+				"# Learn context cancellation patterns",
+			},
 		},
 		"learn-http": {
 			Name:        "learn-http",
 			Description: "Learn HTTP client, server & middleware",
+			Category:    CategoryLearning,
+			Tags:        []string{"go", "http"},
 			Directories: []string{
 				"client",
 				"server",
@@ -808,10 +1003,16 @@ func initBuiltInTemplates() {
 				{Path: "middleware/main_test.go", Content: learnHTTPMiddlewareTestTmpl},
 				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			NextSteps: []string{
+				"cd server && go test -v",
+				"# Build HTTP handlers",
+			},
 		},
 		"learn-error-handling": {
 			Name:        "learn-error-handling",
 			Description: "Learn error handling patterns in Go",
+			Category:    CategoryLearning,
+			Tags:        []string{"go", "errors"},
 			Directories: []string{
 				"basics",
 				"wrapping",
@@ -827,10 +1028,16 @@ func initBuiltInTemplates() {
 				{Path: "custom/main_test.go", Content: learnErrorCustomTestTmpl},
 				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			NextSteps: []string{
+				"cd basics && go test -v",
+				"# Master error patterns",
+			},
 		},
 		"learn-interfaces": {
 			Name:        "learn-interfaces",
 			Description: "Learn interfaces & polymorphism in Go",
+			Category:    CategoryLearning,
+			Tags:        []string{"go", "interfaces"},
 			Directories: []string{
 				"basics",
 				"composition",
@@ -846,10 +1053,16 @@ func initBuiltInTemplates() {
 				{Path: "patterns/main_test.go", Content: learnInterfacesPatternsTestTmpl},
 				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			NextSteps: []string{
+				"cd basics && go test -v",
+				"# Learn interface design",
+			},
 		},
 		"learn-design-patterns": {
 			Name:        "learn-design-patterns",
 			Description: "Learn common design patterns in Go",
+			Category:    CategoryLearning,
+			Tags:        []string{"go", "design-patterns"},
 			Directories: []string{
 				"creational",
 				"behavioral",
@@ -867,10 +1080,16 @@ func initBuiltInTemplates() {
 				{Path: "behavioral/strategy_test.go", Content: learnPatternsStrategyTestTmpl},
 				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			NextSteps: []string{
+				"cd creational && go test -v",
+				"# Learn design patterns",
+			},
 		},
 		"learn-security": {
 			Name:        "learn-security",
 			Description: "Learn Go security (SQL injection, XSS, hashing)",
+			Category:    CategoryLearning,
+			Tags:        []string{"go", "security"},
 			Directories: []string{
 				"vulnerabilities/sql_injection",
 				"vulnerabilities/xss",
@@ -891,10 +1110,13 @@ require (
 )`},
 				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			SkipGoMod: true,
 		},
 		"learn-database": {
 			Name:        "learn-database",
 			Description: "Compare Database approaches (Raw SQL, GORM, sqlc)",
+			Category:    CategoryLearning,
+			Tags:        []string{"go", "database", "sql"},
 			Directories: []string{
 				"raw_sql",
 				"gorm",
@@ -914,10 +1136,13 @@ require (
 )`},
 				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			SkipGoMod: true,
 		},
 		"learn-performance": {
 			Name:        "learn-performance",
 			Description: "Learn Benchmarking, Profiling & Optimization",
+			Category:    CategoryLearning,
+			Tags:        []string{"go", "performance", "profiling"},
 			Directories: []string{
 				"benchmarking",
 				"profiling",
@@ -935,6 +1160,9 @@ require (
 		"go-microservice": {
 			Name:        "go-microservice",
 			Description: "Microservice with health check, metrics & graceful shutdown",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "http", "microservice"},
+			Extends:     "go-base",
 			Directories: []string{
 				"cmd/server",
 				"internal/handler",
@@ -949,21 +1177,28 @@ require (
 				{Path: "internal/health/health.go", Content: microserviceHealthTmpl},
 				{Path: "Dockerfile", Content: microserviceDockerfileTmpl},
 				{Path: "Makefile", Content: microserviceMakefileTmpl},
-				{Path: "README.md", Content: readmeTmpl},
-				{Path: ".gitignore", Content: gitignoreGoTmpl},
+			},
+			NextSteps: []string{
+				"go mod tidy && go run ./cmd/server",
+				"# curl http://localhost:8080/health",
 			},
 		},
 		"go-web-htmx": {
 			Name:        "go-web-htmx",
 			Description: "SSR Web App with Go + HTMX + Tailwind",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "http", "htmx", "web"},
+			Version:     "1.1.0", // 1.1.0: serves a favicon from static/
 			Directories: []string{
 				"cmd/server",
 				"templates",
+				"static",
 			},
 			Files: []FileTemplate{
 				{Path: "cmd/server/main.go", Content: goHtmxMainTmpl},
 				{Path: "templates/index.html", Content: goHtmxIndexTmpl},
 				{Path: "templates/list.html", Content: goHtmxListTmpl},
+				{Path: "static/favicon.ico", Content: faviconAsset, Static: true},
 				{Path: "README.md", Content: goHtmxReadmeTmpl},
 				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
@@ -971,6 +1206,8 @@ require (
 		"go-k8s-operator": {
 			Name:        "go-k8s-operator",
 			Description: "Kubernetes Operator (controller-runtime)",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "kubernetes", "operator"},
 			Directories: []string{
 				"api/v1alpha1",
 				"internal/controller",
@@ -992,10 +1229,13 @@ require (
 )`},
 				{Path: ".gitignore", Content: gitignoreGoTmpl},
 			},
+			SkipGoMod: true,
 		},
 		"go-wasm": {
 			Name:        "go-wasm",
 			Description: "WebAssembly App (Go compilation to WASM)",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "wasm", "web"},
 			Directories: []string{},
 			Files: []FileTemplate{
 				{Path: "main.go", Content: goWasmMainTmpl},
@@ -1009,6 +1249,9 @@ require (
 		"go-websocket": {
 			Name:        "go-websocket",
 			Description: "Real-time WebSocket application",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "websocket", "http"},
+			Extends:     "go-base",
 			Directories: []string{
 				"cmd/server",
 				"internal/hub",
@@ -1021,13 +1264,21 @@ require (
 				{Path: "internal/hub/hub_test.go", Content: goWebsocketTestTmpl},
 				{Path: "internal/client/client.go", Content: websocketClientTmpl},
 				{Path: "web/index.html", Content: websocketHTMLTmpl},
-				{Path: "README.md", Content: readmeTmpl},
-				{Path: ".gitignore", Content: gitignoreGoTmpl},
+			},
+			Dependencies: []Dependency{
+				{Path: "github.com/gorilla/websocket", Version: "v1.5.3"},
+			},
+			NextSteps: []string{
+				"go mod tidy",
+				"go run ./cmd/server",
 			},
 		},
 		"go-graphql": {
 			Name:        "go-graphql",
 			Description: "GraphQL API with gqlgen",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "graphql", "http"},
+			Extends:     "go-base",
 			Directories: []string{
 				"cmd/server",
 				"graph",
@@ -1043,13 +1294,22 @@ require (
 				{Path: "internal/middleware/middleware.go", Content: graphqlMiddlewareTmpl},
 				{Path: "gqlgen.yml", Content: graphqlConfigTmpl},
 				{Path: "Dockerfile", Content: graphqlDockerfileTmpl},
-				{Path: "README.md", Content: readmeTmpl},
-				{Path: ".gitignore", Content: gitignoreGoTmpl},
+			},
+			Dependencies: []Dependency{
+				{Path: "github.com/99designs/gqlgen", Version: "v0.17.55"},
+			},
+			NextSteps: []string{
+				"go mod tidy",
+				"go run github.com/99designs/gqlgen generate",
+				"go run ./cmd/server",
 			},
 		},
 		"go-lambda": {
 			Name:        "go-lambda",
 			Description: "AWS Lambda function with SAM",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "aws", "lambda", "serverless"},
+			Extends:     "go-base",
 			Directories: []string{
 				"cmd/lambda",
 				"cmd/local",
@@ -1064,13 +1324,22 @@ require (
 				{Path: "internal/middleware/middleware.go", Content: lambdaMiddlewareTmpl},
 				{Path: "template.yaml", Content: lambdaSAMEnhancedTmpl},
 				{Path: "Makefile", Content: lambdaMakefileTmpl},
-				{Path: "README.md", Content: readmeTmpl},
-				{Path: ".gitignore", Content: gitignoreGoTmpl},
+			},
+			Dependencies: []Dependency{
+				{Path: "github.com/aws/aws-lambda-go", Version: "v1.47.0"},
+			},
+			NextSteps: []string{
+				"go mod tidy",
+				"make build",
+				"sam local start-api",
 			},
 		},
 		"go-cron": {
 			Name:        "go-cron",
 			Description: "Scheduled jobs with cron",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "cron", "scheduler"},
+			Extends:     "go-base",
 			Directories: []string{
 				"cmd/cron",
 				"internal/jobs",
@@ -1087,14 +1356,22 @@ require (
 				{Path: "internal/config/config.go", Content: goCronConfigTmpl},
 				{Path: "internal/health/health.go", Content: goCronHealthTmpl},
 				{Path: "Dockerfile", Content: goCronDockerfileTmpl},
-				{Path: "README.md", Content: readmeTmpl},
-				{Path: ".gitignore", Content: gitignoreGoTmpl},
+			},
+			Dependencies: []Dependency{
+				{Path: "github.com/robfig/cron/v3", Version: "v3.0.1"},
+			},
+			NextSteps: []string{
+				"go mod tidy",
+				"go run ./cmd/scheduler",
 			},
 		},
 		// Additional Project Templates
 		"go-auth": {
 			Name:        "go-auth",
 			Description: "JWT authentication with middleware",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "auth", "jwt", "http"},
+			Extends:     "go-base",
 			Directories: []string{
 				"cmd/server",
 				"internal/auth",
@@ -1107,13 +1384,21 @@ require (
 				{Path: "internal/auth/middleware.go", Content: authMiddlewareTmpl},
 				{Path: "internal/handler/auth.go", Content: authHandlerTmpl},
 				{Path: "internal/model/user.go", Content: authUserModelTmpl},
-				{Path: "README.md", Content: readmeTmpl},
-				{Path: ".gitignore", Content: gitignoreGoTmpl},
+			},
+			Dependencies: []Dependency{
+				{Path: "github.com/golang-jwt/jwt/v5", Version: "v5.2.1"},
+			},
+			NextSteps: []string{
+				"go mod tidy",
+				"go run ./cmd/server",
 			},
 		},
 		"go-kafka": {
 			Name:        "go-kafka",
 			Description: "Kafka consumer & producer",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "kafka", "messaging"},
+			Extends:     "go-base",
 			Directories: []string{
 				"cmd/producer",
 				"cmd/consumer",
@@ -1125,13 +1410,21 @@ require (
 				{Path: "internal/kafka/producer.go", Content: kafkaProducerTmpl},
 				{Path: "internal/kafka/consumer.go", Content: kafkaConsumerTmpl},
 				{Path: "docker-compose.yml", Content: kafkaDockerComposeTmpl},
-				{Path: "README.md", Content: readmeTmpl},
-				{Path: ".gitignore", Content: gitignoreGoTmpl},
+			},
+			Dependencies: []Dependency{
+				{Path: "github.com/IBM/sarama", Version: "v1.43.3"},
+			},
+			NextSteps: []string{
+				"go mod tidy",
+				"docker-compose up -d",
 			},
 		},
 		"go-redis": {
 			Name:        "go-redis",
 			Description: "Redis caching & pub/sub patterns",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "redis", "cache"},
+			Extends:     "go-base",
 			Directories: []string{
 				"cmd/server",
 				"internal/cache",
@@ -1142,13 +1435,21 @@ require (
 				{Path: "internal/cache/redis.go", Content: redisCacheTmpl},
 				{Path: "internal/pubsub/pubsub.go", Content: redisPubSubTmpl},
 				{Path: "docker-compose.yml", Content: redisDockerComposeTmpl},
-				{Path: "README.md", Content: readmeTmpl},
-				{Path: ".gitignore", Content: gitignoreGoTmpl},
+			},
+			Dependencies: []Dependency{
+				{Path: "github.com/redis/go-redis/v9", Version: "v9.7.0"},
+			},
+			NextSteps: []string{
+				"go mod tidy",
+				"docker-compose up -d",
 			},
 		},
 		"go-clean-arch": {
 			Name:        "go-clean-arch",
 			Description: "Clean Architecture pattern",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "clean-architecture", "http"},
+			Extends:     "go-base",
 			Directories: []string{
 				"cmd/api",
 				"internal/entity",
@@ -1164,13 +1465,17 @@ require (
 				{Path: "internal/repository/user.go", Content: cleanArchRepoTmpl},
 				{Path: "internal/delivery/http/handler.go", Content: cleanArchHandlerTmpl},
 				{Path: "pkg/errors/errors.go", Content: cleanArchErrorsTmpl},
-				{Path: "README.md", Content: readmeTmpl},
-				{Path: ".gitignore", Content: gitignoreGoTmpl},
+			},
+			NextSteps: []string{
+				"go run ./cmd/api",
 			},
 		},
 		"go-monorepo": {
 			Name:        "go-monorepo",
 			Description: "Multi-service monorepo with shared packages",
+			Category:    CategoryProject,
+			Tags:        []string{"go", "monorepo"},
+			Extends:     "go-base",
 			Directories: []string{
 				"services/api",
 				"services/worker",
@@ -1182,13 +1487,16 @@ require (
 				{Path: "pkg/shared/config.go", Content: monorepoConfigTmpl},
 				{Path: "pkg/shared/logger.go", Content: monorepoLoggerTmpl},
 				{Path: "Makefile", Content: monorepoMakefileTmpl},
-				{Path: "README.md", Content: readmeTmpl},
-				{Path: ".gitignore", Content: gitignoreGoTmpl},
+			},
+			NextSteps: []string{
+				"make api  # atau make worker",
 			},
 		},
 		"learn-frontend": {
 			Name:        "learn-frontend",
 			Description: "Learn frontend development (HTML, CSS, JavaScript)",
+			Category:    CategoryLearning,
+			Tags:        []string{"html", "css", "javascript", "frontend"},
 			Directories: []string{
 				"01-html-basics",
 				"02-css-fundamentals",
@@ -1218,10 +1526,18 @@ require (
 				{Path: "05-fetch-api/style.css", Content: learnFrontendFetchStyleTmpl},
 				{Path: "05-fetch-api/script.js", Content: learnFrontendFetchScriptTmpl},
 			},
+			NextSteps: []string{
+				"cd 01-html-basics",
+				"open index.html  # Open in your browser",
+				"# Work through each folder in order!",
+			},
+			SkipGoMod: true,
 		},
 		"learn-debugging": {
 			Name:        "learn-debugging",
 			Description: "Learn debugging techniques (print, logging, profiling, tracing)",
+			Category:    CategoryLearning,
+			Tags:        []string{"go", "debugging", "profiling"},
 			Directories: []string{
 				"01-print-debugging",
 				"02-structured-logging",
@@ -1249,6 +1565,8 @@ require (
 		"learn-tdd": {
 			Name:        "learn-tdd",
 			Description: "Learn Test-Driven Development (Red-Green-Refactor)",
+			Category:    CategoryLearning,
+			Tags:        []string{"go", "testing", "tdd"},
 			Directories: []string{
 				"01-red-green-refactor",
 				"02-mocking",
@@ -1273,21 +1591,24 @@ require (
 	}
 }
 
-// GetTemplate returns a template by name
+// GetTemplate returns a built-in or custom template by name, with the
+// templates and components it extends and includes applied
 func GetTemplate(name string) (Template, error) {
-	t, ok := builtInTemplates[name]
-	if !ok {
-		return Template{}, fmt.Errorf("template not found: %s", name)
-	}
-	return t, nil
+	return resolve(name, nil)
 }
 
-// GetAllTemplates returns all available templates
+// GetAllTemplates returns all available templates, built-in and custom
 func GetAllTemplates() []Template {
-	result := make([]Template, 0, len(builtInTemplates))
-	for _, t := range builtInTemplates {
-		result = append(result, t)
-		fmt.Println(len(result))
+	result := make([]Template, 0, len(builtInTemplates)+len(customTemplates))
+	for name := range builtInTemplates {
+		if t, err := resolve(name, nil); err == nil {
+			result = append(result, t)
+		}
+	}
+	for name := range customTemplates {
+		if t, err := resolve(name, nil); err == nil {
+			result = append(result, t)
+		}
 	}
 	return result
 }
@@ -1,6 +1,7 @@
 package templates
 
 import (
+	"slices"
 	"strings"
 	"testing"
 )
@@ -259,3 +260,70 @@ func TestSpecificTemplatesStructure(t *testing.T) {
 		})
 	}
 }
+
+func TestTemplateMetadata(t *testing.T) {
+	for _, tmpl := range GetAllTemplates() {
+		if !slices.Contains(Categories, tmpl.Category) {
+			t.Errorf("template %s: category %q is not one of %v", tmpl.Name, tmpl.Category, Categories)
+		}
+		if len(tmpl.Tags) == 0 {
+			t.Errorf("template %s has no tags", tmpl.Name)
+		}
+		if !slices.Contains(DockerfileProfiles, tmpl.DockerfileProfile) {
+			t.Errorf("template %s: unknown dockerfile profile %q", tmpl.Name, tmpl.DockerfileProfile)
+		}
+		for _, h := range tmpl.PostInit {
+			if len(h.Command) == 0 {
+				t.Errorf("template %s has a post-init hook without a command", tmpl.Name)
+			}
+		}
+	}
+
+	tests := []struct {
+		name       string
+		category   string
+		skipGoMod  bool
+		dockerfile string
+		postInit   string
+	}{
+		{"go-api", CategoryProject, false, DockerfileAPI, ""},
+		{"go-cli", CategoryProject, false, DockerfileCLI, ""},
+		{"go-tui", CategoryProject, false, DockerfileDefault, ""},
+		{"fullstack", CategoryFullstack, true, DockerfileDefault, "bun install"},
+		{"learn-frontend", CategoryLearning, true, DockerfileDefault, ""},
+		{"learn-dsa", CategoryLearning, false, DockerfileDefault, ""},
+		{"code-review-exercise", CategorySkill, false, DockerfileDefault, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := GetTemplate(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tmpl.Category != tt.category {
+				t.Errorf("Category = %q, want %q", tmpl.Category, tt.category)
+			}
+			if tmpl.SkipGoMod != tt.skipGoMod {
+				t.Errorf("SkipGoMod = %v, want %v", tmpl.SkipGoMod, tt.skipGoMod)
+			}
+			if tmpl.DockerfileProfile != tt.dockerfile {
+				t.Errorf("DockerfileProfile = %q, want %q", tmpl.DockerfileProfile, tt.dockerfile)
+			}
+			var hooks []string
+			for _, h := range tmpl.PostInit {
+				hooks = append(hooks, h.String())
+			}
+			if strings.Join(hooks, "; ") != tt.postInit {
+				t.Errorf("PostInit = %v, want %q", hooks, tt.postInit)
+			}
+		})
+	}
+}
+
+func TestTemplatesWithGoModSkipGoModInit(t *testing.T) {
+	for _, tmpl := range GetAllTemplates() {
+		if _, ok := findFile(tmpl, "go.mod"); ok && !tmpl.SkipGoMod {
+			t.Errorf("template %s ships a go.mod, go mod init would fail on it", tmpl.Name)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Variable types
+const (
+	VarString = "string"
+	VarInt    = "int"
+	VarBool   = "bool"
+	VarChoice = "choice"
+)
+
+// Variable is a template input declared in the manifest. Values are
+// available to file contents and paths as {{.Vars.<name>}}.
+type Variable struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type,omitempty"`    // VarString (default), VarInt, VarBool or VarChoice
+	Default string   `json:"default,omitempty"` // Used when no value is given
+	Pattern string   `json:"pattern,omitempty"` // Regexp the value must match
+	Prompt  string   `json:"prompt,omitempty"`  // Question asked in interactive mode
+	Help    string   `json:"help,omitempty"`    // Longer explanation shown with the prompt
+	Choices []string `json:"choices,omitempty"` // Allowed values for VarChoice
+}
+
+var variableNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Question returns the text to ask the user for this variable
+func (v Variable) Question() string {
+	if v.Prompt != "" {
+		return v.Prompt
+	}
+	return v.Name
+}
+
+// Parse validates a raw value and converts it to the variable's type
+func (v Variable) Parse(value string) (any, error) {
+	if v.Pattern != "" {
+		re, err := regexp.Compile(v.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: invalid pattern: %w", v.Name, err)
+		}
+		if !re.MatchString(value) {
+			return nil, fmt.Errorf("variable %s: %q does not match %s", v.Name, value, v.Pattern)
+		}
+	}
+
+	switch v.Type {
+	case "", VarString:
+		return value, nil
+	case VarInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %q is not an integer", v.Name, value)
+		}
+		return n, nil
+	case VarBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %q is not a boolean", v.Name, value)
+		}
+		return b, nil
+	case VarChoice:
+		if !slices.Contains(v.Choices, value) {
+			return nil, fmt.Errorf("variable %s: %q is not one of %s", v.Name, value, strings.Join(v.Choices, ", "))
+		}
+		return value, nil
+	default:
+		return nil, fmt.Errorf("variable %s: unknown type %q", v.Name, v.Type)
+	}
+}
+
+// validate checks the declaration itself, including its default value
+func (v Variable) validate() error {
+	if !variableNameRe.MatchString(v.Name) {
+		return fmt.Errorf("invalid variable name %q", v.Name)
+	}
+	if v.Type == VarChoice && len(v.Choices) == 0 {
+		return fmt.Errorf("variable %s: choice variables need choices", v.Name)
+	}
+	if v.Pattern != "" {
+		if _, err := regexp.Compile(v.Pattern); err != nil {
+			return fmt.Errorf("variable %s: invalid pattern: %w", v.Name, err)
+		}
+	}
+	if v.Default != "" {
+		if _, err := v.Parse(v.Default); err != nil {
+			return fmt.Errorf("invalid default: %w", err)
+		}
+	}
+	return nil
+}
+
+// ResolveVariables validates values against the template's variables and
+// returns them typed, with defaults filled in. Unknown names are an error.
+func ResolveVariables(t Template, values map[string]string) (map[string]any, error) {
+	for name := range values {
+		if !slices.ContainsFunc(t.Variables, func(v Variable) bool { return v.Name == name }) {
+			return nil, fmt.Errorf("template %s has no variable %q", t.Name, name)
+		}
+	}
+
+	vars := make(map[string]any, len(t.Variables))
+	for _, v := range t.Variables {
+		value, ok := values[v.Name]
+		if !ok && v.Default == "" {
+			switch v.Type {
+			case "", VarString:
+				// A pattern that rejects the empty string makes the variable required
+				if _, err := v.Parse(""); err != nil {
+					return nil, fmt.Errorf("variable %s is required, an empty value doesn't match %s", v.Name, v.Pattern)
+				}
+				vars[v.Name] = ""
+				continue
+			case VarBool:
+				vars[v.Name] = false
+				continue
+			default:
+				return nil, fmt.Errorf("variable %s is required", v.Name)
+			}
+		}
+		if !ok {
+			value = v.Default
+		}
+
+		parsed, err := v.Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		vars[v.Name] = parsed
+	}
+	return vars, nil
+}
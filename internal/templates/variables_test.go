@@ -0,0 +1,101 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVariableParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       Variable
+		value   string
+		want    any
+		wantErr bool
+	}{
+		{"string", Variable{Name: "team"}, "payments", "payments", false},
+		{"int", Variable{Name: "port", Type: VarInt}, "9000", 9000, false},
+		{"int invalid", Variable{Name: "port", Type: VarInt}, "http", nil, true},
+		{"bool", Variable{Name: "metrics", Type: VarBool}, "true", true, false},
+		{"bool invalid", Variable{Name: "metrics", Type: VarBool}, "maybe", nil, true},
+		{"choice", Variable{Name: "db", Type: VarChoice, Choices: []string{"postgres", "mysql"}}, "mysql", "mysql", false},
+		{"choice invalid", Variable{Name: "db", Type: VarChoice, Choices: []string{"postgres", "mysql"}}, "oracle", nil, true},
+		{"pattern match", Variable{Name: "team", Pattern: `^[a-z]+$`}, "core", "core", false},
+		{"pattern mismatch", Variable{Name: "team", Pattern: `^[a-z]+$`}, "Core Team", nil, true},
+		{"unknown type", Variable{Name: "x", Type: "float"}, "1.5", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.v.Parse(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Parse(%q) = %v (%T), want %v (%T)", tt.value, got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveVariables(t *testing.T) {
+	tmpl := Template{
+		Name: "svc",
+		Variables: []Variable{
+			{Name: "port", Type: VarInt, Default: "8080"},
+			{Name: "db", Type: VarChoice, Choices: []string{"postgres", "sqlite"}, Default: "sqlite"},
+			{Name: "metrics", Type: VarBool},
+			{Name: "team"},
+		},
+	}
+
+	vars, err := ResolveVariables(tmpl, map[string]string{"port": "9000", "team": "core"})
+	if err != nil {
+		t.Fatalf("ResolveVariables failed: %v", err)
+	}
+
+	want := map[string]any{"port": 9000, "db": "sqlite", "metrics": false, "team": "core"}
+	for k, v := range want {
+		if vars[k] != v {
+			t.Errorf("vars[%s] = %v, want %v", k, vars[k], v)
+		}
+	}
+
+	if _, err := ResolveVariables(tmpl, map[string]string{"region": "eu"}); err == nil {
+		t.Error("expected error for unknown variable")
+	}
+	if _, err := ResolveVariables(tmpl, map[string]string{"port": "http"}); err == nil {
+		t.Error("expected error for invalid value")
+	}
+
+	required := Template{Name: "svc", Variables: []Variable{{Name: "db", Type: VarChoice, Choices: []string{"a"}}}}
+	if _, err := ResolveVariables(required, nil); err == nil {
+		t.Error("expected error for missing required variable")
+	}
+
+	// A pattern is checked even without a value or default
+	patterned := Template{Name: "svc", Variables: []Variable{{Name: "region", Pattern: `^[a-z]{2}-[a-z]+$`}}}
+	if _, err := ResolveVariables(patterned, nil); err == nil || !strings.Contains(err.Error(), "region is required") {
+		t.Errorf("missing patterned variable error = %v, want it required", err)
+	}
+	if _, err := ResolveVariables(patterned, map[string]string{"region": "Europe"}); err == nil {
+		t.Error("expected error for a value not matching the pattern")
+	}
+	optional := Template{Name: "svc", Variables: []Variable{{Name: "suffix", Pattern: `^[a-z]*$`}}}
+	if vars, err := ResolveVariables(optional, nil); err != nil || vars["suffix"] != "" {
+		t.Errorf("ResolveVariables = %v, %v, want an empty value the pattern allows", vars, err)
+	}
+}
+
+func TestBuiltInVariablesAreValid(t *testing.T) {
+	for _, tmpl := range GetAllTemplates() {
+		for _, v := range tmpl.Variables {
+			if err := v.validate(); err != nil {
+				t.Errorf("template %s: %v", tmpl.Name, err)
+			}
+		}
+		if _, err := ResolveVariables(tmpl, nil); err != nil {
+			t.Errorf("template %s: defaults don't resolve: %v", tmpl.Name, err)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package templates
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A When expression decides whether a file or directory is generated. It is
+// evaluated against the template variables and supports variable names,
+// 'quoted' or "quoted" strings, integers, true and false, == and !=, !, &&,
+// || and parentheses, e.g. "db == 'postgres' && !minimal". A bare value is
+// true when it is true, a non-empty string or a non-zero integer.
+type condition interface {
+	eval(vars map[string]any) (any, error)
+	names(out []string) []string
+}
+
+type (
+	condName  string
+	condValue struct{ v any }
+	condNot   struct{ x condition }
+	condBinOp struct {
+		op   string
+		x, y condition
+	}
+)
+
+func (c condName) eval(vars map[string]any) (any, error) {
+	v, ok := vars[string(c)]
+	if !ok {
+		return nil, fmt.Errorf("unknown variable %q", string(c))
+	}
+	return v, nil
+}
+
+func (c condValue) eval(map[string]any) (any, error) { return c.v, nil }
+
+func (c condNot) eval(vars map[string]any) (any, error) {
+	v, err := c.x.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	return !truthy(v), nil
+}
+
+func (c condBinOp) eval(vars map[string]any) (any, error) {
+	x, err := c.x.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	// && and || short-circuit like in Go
+	switch {
+	case c.op == "&&" && !truthy(x):
+		return false, nil
+	case c.op == "||" && truthy(x):
+		return true, nil
+	}
+	y, err := c.y.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch c.op {
+	case "==":
+		return fmt.Sprint(x) == fmt.Sprint(y), nil
+	case "!=":
+		return fmt.Sprint(x) != fmt.Sprint(y), nil
+	default:
+		return truthy(y), nil
+	}
+}
+
+func (c condName) names(out []string) []string  { return append(out, string(c)) }
+func (c condValue) names(out []string) []string { return out }
+func (c condNot) names(out []string) []string   { return c.x.names(out) }
+func (c condBinOp) names(out []string) []string { return c.y.names(c.x.names(out)) }
+
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case int:
+		return v != 0
+	default:
+		return v != nil
+	}
+}
+
+// parseWhen parses a When expression
+func parseWhen(expr string) (condition, error) {
+	tokens, err := tokenizeWhen(expr)
+	if err != nil {
+		return nil, fmt.Errorf("when %q: %w", expr, err)
+	}
+	p := &whenParser{tokens: tokens}
+	c, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %s", p.tokens[p.pos])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("when %q: %w", expr, err)
+	}
+	return c, nil
+}
+
+// evalWhen reports whether expr holds for vars. An empty expression always
+// holds.
+func evalWhen(expr string, vars map[string]any) (bool, error) {
+	if strings.TrimSpace(expr) == "" {
+		return true, nil
+	}
+	c, err := parseWhen(expr)
+	if err != nil {
+		return false, err
+	}
+	v, err := c.eval(vars)
+	if err != nil {
+		return false, fmt.Errorf("when %q: %w", expr, err)
+	}
+	return truthy(v), nil
+}
+
+var whenOperators = []string{"==", "!=", "&&", "||", "!", "(", ")"}
+
+func tokenizeWhen(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, s[i:i+end+2])
+			i += end + 2
+		case c == '_' || c == '-' || isAlnum(c):
+			start := i
+			for i++; i < len(s) && (s[i] == '_' || isAlnum(s[i])); i++ {
+			}
+			tokens = append(tokens, s[start:i])
+		default:
+			op := ""
+			for _, o := range whenOperators {
+				if strings.HasPrefix(s[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q", c)
+			}
+			tokens = append(tokens, op)
+			i += len(op)
+		}
+	}
+	return tokens, nil
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+type whenParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *whenParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *whenParser) or() (condition, error) {
+	x, err := p.and()
+	for err == nil && p.peek() == "||" {
+		p.pos++
+		var y condition
+		if y, err = p.and(); err == nil {
+			x = condBinOp{"||", x, y}
+		}
+	}
+	return x, err
+}
+
+func (p *whenParser) and() (condition, error) {
+	x, err := p.comparison()
+	for err == nil && p.peek() == "&&" {
+		p.pos++
+		var y condition
+		if y, err = p.comparison(); err == nil {
+			x = condBinOp{"&&", x, y}
+		}
+	}
+	return x, err
+}
+
+func (p *whenParser) comparison() (condition, error) {
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	if op := p.peek(); op == "==" || op == "!=" {
+		p.pos++
+		y, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return condBinOp{op, x, y}, nil
+	}
+	return x, nil
+}
+
+func (p *whenParser) unary() (condition, error) {
+	tok := p.peek()
+	p.pos++
+	switch {
+	case tok == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case tok == "!":
+		x, err := p.unary()
+		return condNot{x}, err
+	case tok == "(":
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return x, nil
+	case tok[0] == '\'' || tok[0] == '"':
+		return condValue{tok[1 : len(tok)-1]}, nil
+	case tok == "true" || tok == "false":
+		return condValue{tok == "true"}, nil
+	case tok[0] == '-' || tok[0] >= '0' && tok[0] <= '9':
+		n, err := strconv.Atoi(tok)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", tok)
+		}
+		return condValue{n}, nil
+	case variableNameRe.MatchString(tok):
+		return condName(tok), nil
+	default:
+		return nil, fmt.Errorf("unexpected %s", tok)
+	}
+}
+
+// checkConditions parses every When expression of t and checks that it only
+// refers to variables t declares
+func checkConditions(t Template) error {
+	check := func(what, expr string) error {
+		if strings.TrimSpace(expr) == "" {
+			return nil
+		}
+		c, err := parseWhen(expr)
+		if err != nil {
+			return fmt.Errorf("%s: %w", what, err)
+		}
+		for _, name := range c.names(nil) {
+			if !slices.ContainsFunc(t.Variables, func(v Variable) bool { return v.Name == name }) {
+				return fmt.Errorf("%s: when %q: unknown variable %q", what, expr, name)
+			}
+		}
+		return nil
+	}
+
+	for _, f := range t.Files {
+		if err := check(f.Path, f.When); err != nil {
+			return err
+		}
+	}
+	for _, dir := range sortedKeys(t.DirectoryWhen) {
+		if err := check(dir+"/", t.DirectoryWhen[dir]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Select returns t with only the directories and files whose When
+// expression holds for vars. Files below a directory left out are left out
+// too.
+func Select(t Template, vars map[string]any) (Template, error) {
+	var excluded []string
+	for _, dir := range sortedKeys(t.DirectoryWhen) {
+		ok, err := evalWhen(t.DirectoryWhen[dir], vars)
+		if err != nil {
+			return Template{}, fmt.Errorf("template %s: %s/: %w", t.Name, dir, err)
+		}
+		if !ok {
+			excluded = append(excluded, path.Clean(dir))
+		}
+	}
+	within := func(p string) bool {
+		for _, dir := range excluded {
+			if p == dir || strings.HasPrefix(p, dir+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	selected := t
+	selected.Directories = nil
+	for _, dir := range t.Directories {
+		if !within(path.Clean(dir)) {
+			selected.Directories = append(selected.Directories, dir)
+		}
+	}
+	selected.Files = nil
+	for _, f := range t.Files {
+		if within(path.Clean(f.Path)) {
+			continue
+		}
+		ok, err := evalWhen(f.When, vars)
+		if err != nil {
+			return Template{}, fmt.Errorf("template %s: %s: %w", t.Name, f.Path, err)
+		}
+		if ok {
+			selected.Files = append(selected.Files, f)
+		}
+	}
+	return selected, nil
+}
+
+// DirectoryConditions returns the DirectoryWhen expressions that apply to
+// p: its own and those of the directories it is in
+func (t Template) DirectoryConditions(p string) []string {
+	var conds []string
+	p = path.Clean(p)
+	for _, dir := range sortedKeys(t.DirectoryWhen) {
+		clean := path.Clean(dir)
+		if p == clean || strings.HasPrefix(p, clean+"/") {
+			conds = append(conds, t.DirectoryWhen[dir])
+		}
+	}
+	return conds
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,216 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEvalWhen(t *testing.T) {
+	vars := map[string]any{
+		"db":     "postgres",
+		"docker": true,
+		"tests":  false,
+		"port":   8080,
+		"name":   "",
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"", true},
+		{"db == 'postgres'", true},
+		{`db == "mysql"`, false},
+		{"db != 'mysql'", true},
+		{"docker", true},
+		{"tests", false},
+		{"!tests", true},
+		{"!!docker", true},
+		{"name", false},
+		{"port", true},
+		{"port == 8080", true},
+		{"port == '8080'", true},
+		{"port != 80", true},
+		{"docker == true", true},
+		{"tests == false", true},
+		{"docker && tests", false},
+		{"docker || tests", true},
+		{"db == 'mysql' || db == 'postgres' && docker", true},
+		{"(db == 'mysql' || db == 'postgres') && tests", false},
+		{"!(db == 'sqlite')", true},
+		{"  db=='postgres'&&docker  ", true},
+		// The right side isn't evaluated when the left side decides
+		{"tests && missing", false},
+		{"docker || missing", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := evalWhen(tt.expr, vars)
+			if err != nil {
+				t.Fatalf("evalWhen failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("evalWhen(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalWhenErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"missing", `unknown variable "missing"`},
+		{"db ==", "unexpected end of expression"},
+		{"db = 'x'", `unexpected '='`},
+		{"db == 'x", "unterminated string"},
+		{"(db == 'x'", "missing )"},
+		{"db == 'x')", "unexpected )"},
+		{"db 'x'", "unexpected 'x'"},
+		{"db == 12ab", "invalid number 12ab"},
+		{"&& db", "unexpected &&"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := evalWhen(tt.expr, map[string]any{"db": "x"})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("evalWhen(%q) error = %v, want %q", tt.expr, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tmpl := Template{
+		Name:        "svc",
+		Directories: []string{"cmd", "migrations", "migrations/postgres", "deploy"},
+		Files: []FileTemplate{
+			{Path: "main.go"},
+			{Path: "db/postgres.go", When: "db == 'postgres'"},
+			{Path: "db/mysql.go", When: "db == 'mysql'"},
+			{Path: "main_test.go", When: "tests"},
+			{Path: "migrations/postgres/001.sql"},
+			{Path: "Dockerfile"},
+		},
+		DirectoryWhen: map[string]string{
+			"migrations": "db != 'none'",
+			"deploy":     "docker",
+			"Dockerfile": "docker",
+		},
+		Variables: []Variable{
+			{Name: "db", Type: VarChoice, Choices: []string{"postgres", "mysql", "none"}},
+			{Name: "tests", Type: VarBool},
+			{Name: "docker", Type: VarBool},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		vars  map[string]any
+		dirs  string
+		files string
+	}{
+		{
+			name:  "postgres with everything",
+			vars:  map[string]any{"db": "postgres", "tests": true, "docker": true},
+			dirs:  "cmd,migrations,migrations/postgres,deploy",
+			files: "main.go,db/postgres.go,main_test.go,migrations/postgres/001.sql,Dockerfile",
+		},
+		{
+			name:  "mysql without tests",
+			vars:  map[string]any{"db": "mysql", "tests": false, "docker": true},
+			dirs:  "cmd,migrations,migrations/postgres,deploy",
+			files: "main.go,db/mysql.go,migrations/postgres/001.sql,Dockerfile",
+		},
+		{
+			name:  "no database and no docker",
+			vars:  map[string]any{"db": "none", "tests": false, "docker": false},
+			dirs:  "cmd",
+			files: "main.go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Select(tmpl, tt.vars)
+			if err != nil {
+				t.Fatalf("Select failed: %v", err)
+			}
+			if dirs := strings.Join(got.Directories, ","); dirs != tt.dirs {
+				t.Errorf("Directories = %s, want %s", dirs, tt.dirs)
+			}
+			if files := strings.Join(filePaths(got), ","); files != tt.files {
+				t.Errorf("Files = %s, want %s", files, tt.files)
+			}
+		})
+	}
+
+	if err := checkConditions(tmpl); err != nil {
+		t.Errorf("checkConditions failed: %v", err)
+	}
+	tmpl.Files = append(tmpl.Files, FileTemplate{Path: "x.go", When: "dbb == 'postgres'"})
+	if err := checkConditions(tmpl); err == nil || !strings.Contains(err.Error(), `x.go: when "dbb == 'postgres'": unknown variable "dbb"`) {
+		t.Errorf("checkConditions error = %v, want the unknown variable reported", err)
+	}
+}
+
+func TestBuiltInConditions(t *testing.T) {
+	for _, tmpl := range GetAllTemplates() {
+		if err := checkConditions(tmpl); err != nil {
+			t.Errorf("template %s: %v", tmpl.Name, err)
+		}
+	}
+}
+
+func TestCustomTemplateWhen(t *testing.T) {
+	root := t.TempDir()
+	t.Cleanup(func() { customTemplates = map[string]Template{} })
+
+	writeCustomTemplate(t, root, "svc", map[string]string{
+		ManifestFile: `{
+  "variables": [{"name": "docker", "type": "bool", "default": "true"}],
+  "directories": ["deploy"],
+  "when": {
+    "Dockerfile": "docker",
+    "deploy/": "docker"
+  }
+}`,
+		"main.go":         "package main\n",
+		"Dockerfile":      "FROM scratch\n",
+		"deploy/app.yaml": "kind: Deployment\n",
+	})
+	writeCustomTemplate(t, root, "typo", map[string]string{
+		ManifestFile: `{"when": {"main.go": "dockr"}}`,
+		"main.go":    "package main\n",
+	})
+
+	err := LoadCustomTemplates(root)
+	if err == nil || !strings.Contains(err.Error(), `unknown variable "dockr"`) {
+		t.Errorf("LoadCustomTemplates error = %v, want the typo reported", err)
+	}
+
+	tmpl, err := GetTemplate("svc")
+	if err != nil {
+		t.Fatalf("GetTemplate failed: %v", err)
+	}
+	if f, _ := findFile(tmpl, "Dockerfile"); f.When != "docker" {
+		t.Errorf("Dockerfile When = %q, want docker", f.When)
+	}
+	if tmpl.DirectoryWhen["deploy"] != "docker" {
+		t.Errorf("DirectoryWhen = %v, want deploy: docker", tmpl.DirectoryWhen)
+	}
+
+	selected, err := Select(tmpl, map[string]any{"docker": false})
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if got := strings.Join(filePaths(selected), ","); got != "main.go" {
+		t.Errorf("Files = %s, want main.go", got)
+	}
+	if len(selected.Directories) != 0 {
+		t.Errorf("Directories = %v, want none", selected.Directories)
+	}
+}
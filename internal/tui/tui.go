@@ -7,6 +7,8 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/purnama/scaffold/internal/config"
+	"github.com/purnama/scaffold/internal/safefs"
 	"github.com/purnama/scaffold/internal/templates"
 )
 
@@ -17,6 +19,10 @@ type ProjectConfig struct {
 	License       string
 	IncludeDocker bool
 	InitGit       bool
+	Vars          map[string]string // Raw values for the template's variables
+	ModuleName    string            // Go module path, derived from ProjectName when empty
+	OutputDir     string            // Parent directory of the project, cwd when empty
+	Author        string            // Copyright holder for LICENSE and README
 }
 
 type step int
@@ -24,6 +30,7 @@ type step int
 const (
 	stepProjectName step = iota
 	stepTemplate
+	stepVariables
 	stepDocker
 	stepLicense
 	stepGit
@@ -36,6 +43,10 @@ type model struct {
 	cursor    int
 	templates []templates.Template
 	licenses  []string
+	vars      []templates.Variable // Variables of the selected template
+	varIndex  int                  // Variable currently being asked
+	varErr    error                // Validation error for the last answer
+	defaults  ProjectConfig        // Preselected answers
 	config    ProjectConfig
 	err       error
 	width     int
@@ -62,21 +73,42 @@ var (
 	successStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#10B981")).
 			Bold(true)
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#EF4444"))
 )
 
-func initialModel() model {
+func initialModel(defaults ProjectConfig) model {
 	ti := textinput.New()
 	ti.Placeholder = "my-awesome-project"
 	ti.Focus()
 	ti.CharLimit = 64
 	ti.Width = 40
 
-	return model{
+	m := model{
 		step:      stepProjectName,
 		textInput: ti,
 		templates: templates.GetAllTemplates(),
-		licenses:  []string{"MIT", "Apache 2.0", "GPL 3.0", "None"},
-		config:    ProjectConfig{},
+		licenses:  config.Licenses,
+		defaults:  defaults,
+		config:    defaults,
+	}
+	// A name given up front, e.g. with --name, isn't asked again
+	if defaults.ProjectName != "" {
+		m.startTemplateStep()
+	}
+	return m
+}
+
+// startTemplateStep moves to the template step with the default template
+// selected
+func (m *model) startTemplateStep() {
+	m.step = stepTemplate
+	m.cursor = 0
+	for i, t := range m.templates {
+		if t.Name == m.defaults.TemplateName {
+			m.cursor = i
+		}
 	}
 }
 
@@ -94,9 +126,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "enter":
 			return m.handleEnter()
+		}
+
+		// Typed characters belong to the text input
+		if m.isTextStep() {
+			break
+		}
 
+		switch msg.String() {
 		case "up", "k":
-			if m.step != stepProjectName && m.cursor > 0 {
+			if m.cursor > 0 {
 				m.cursor--
 			}
 
@@ -105,17 +144,25 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.cursor++
 			} else if m.step == stepLicense && m.cursor < len(m.licenses)-1 {
 				m.cursor++
+			} else if m.step == stepVariables && m.cursor < len(m.currentVar().Choices)-1 {
+				m.cursor++
 			}
 
 		case "y", "Y":
 			if m.step == stepDocker || m.step == stepGit {
 				return m.handleYesNo(true)
 			}
+			if m.step == stepVariables && m.currentVar().Type == templates.VarBool {
+				return m.handleVariable("true")
+			}
 
 		case "n", "N":
 			if m.step == stepDocker || m.step == stepGit {
 				return m.handleYesNo(false)
 			}
+			if m.step == stepVariables && m.currentVar().Type == templates.VarBool {
+				return m.handleVariable("false")
+			}
 		}
 
 	case tea.WindowSizeMsg:
@@ -123,7 +170,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 	}
 
-	if m.step == stepProjectName {
+	if m.isTextStep() {
 		var cmd tea.Cmd
 		m.textInput, cmd = m.textInput.Update(msg)
 		return m, cmd
@@ -132,6 +179,22 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// isTextStep reports whether the current step reads free text
+func (m model) isTextStep() bool {
+	if m.step == stepProjectName {
+		return true
+	}
+	if m.step == stepVariables {
+		t := m.currentVar().Type
+		return t != templates.VarBool && t != templates.VarChoice
+	}
+	return false
+}
+
+func (m model) currentVar() templates.Variable {
+	return m.vars[m.varIndex]
+}
+
 func (m model) handleEnter() (tea.Model, tea.Cmd) {
 	switch m.step {
 	case stepProjectName:
@@ -139,30 +202,112 @@ func (m model) handleEnter() (tea.Model, tea.Cmd) {
 		if name == "" {
 			name = "my-project"
 		}
+		if err := safefs.ValidateName(name); err != nil {
+			m.varErr = fmt.Errorf("the project name %w", err)
+			return m, nil
+		}
+		m.varErr = nil
 		m.config.ProjectName = name
-		m.step = stepTemplate
-		m.cursor = 0
+		m.startTemplateStep()
 
 	case stepTemplate:
-		m.config.TemplateName = m.templates[m.cursor].Name
-		m.step = stepDocker
+		tmpl := m.templates[m.cursor]
+		m.config.TemplateName = tmpl.Name
 		m.cursor = 0
 
+		// Only ask for variables that weren't preset
+		m.config.Vars = map[string]string{}
+		m.vars = nil
+		for _, v := range tmpl.Variables {
+			if value, ok := m.defaults.Vars[v.Name]; ok {
+				m.config.Vars[v.Name] = value
+				continue
+			}
+			m.vars = append(m.vars, v)
+		}
+		if len(m.vars) == 0 {
+			m.step = stepDocker
+			break
+		}
+		m.step = stepVariables
+		m.varIndex = 0
+		m.prepareVariable()
+		return m, textinput.Blink
+
+	case stepVariables:
+		v := m.currentVar()
+		switch v.Type {
+		case templates.VarChoice:
+			return m.handleVariable(v.Choices[m.cursor])
+		case templates.VarBool:
+			return m.handleVariable(v.Default)
+		default:
+			value := strings.TrimSpace(m.textInput.Value())
+			if value == "" {
+				value = v.Default
+			}
+			return m.handleVariable(value)
+		}
+
 	case stepLicense:
 		m.config.License = m.licenses[m.cursor]
 		m.step = stepGit
 		m.cursor = 0
+
+	case stepGit:
+		return m.handleYesNo(m.defaults.InitGit)
 	}
 
 	return m, nil
 }
 
+// handleVariable validates the answer for the current variable and moves on
+func (m model) handleVariable(value string) (tea.Model, tea.Cmd) {
+	v := m.currentVar()
+	if value == "" && v.Type == templates.VarBool {
+		value = "false"
+	}
+	if _, err := v.Parse(value); err != nil {
+		m.varErr = err
+		return m, nil
+	}
+
+	m.config.Vars[v.Name] = value
+	m.varErr = nil
+	m.varIndex++
+	if m.varIndex == len(m.vars) {
+		m.step = stepDocker
+		m.cursor = 0
+		return m, nil
+	}
+	m.prepareVariable()
+	return m, nil
+}
+
+// prepareVariable resets the inputs for the current variable
+func (m *model) prepareVariable() {
+	v := m.currentVar()
+	m.cursor = 0
+	for i, c := range v.Choices {
+		if c == v.Default {
+			m.cursor = i
+		}
+	}
+	m.textInput.SetValue("")
+	m.textInput.Placeholder = v.Default
+}
+
 func (m model) handleYesNo(yes bool) (tea.Model, tea.Cmd) {
 	switch m.step {
 	case stepDocker:
 		m.config.IncludeDocker = yes
 		m.step = stepLicense
 		m.cursor = 0
+		for i, l := range m.licenses {
+			if l == m.defaults.License {
+				m.cursor = i
+			}
+		}
 
 	case stepGit:
 		m.config.InitGit = yes
@@ -184,6 +329,10 @@ func (m model) View() string {
 		s.WriteString(questionStyle.Render("? What is the project name?"))
 		s.WriteString("\n")
 		s.WriteString(m.textInput.View())
+		if m.varErr != nil {
+			s.WriteString("\n")
+			s.WriteString(errorStyle.Render(m.varErr.Error()))
+		}
 
 	case stepTemplate:
 		s.WriteString(questionStyle.Render("? Select a template:"))
@@ -198,6 +347,35 @@ func (m model) View() string {
 			s.WriteString(fmt.Sprintf("%s%s\n", cursor, style.Render(fmt.Sprintf("%s - %s", t.Name, t.Description))))
 		}
 
+	case stepVariables:
+		v := m.currentVar()
+		s.WriteString(questionStyle.Render("? " + v.Question()))
+		s.WriteString("\n")
+		if v.Help != "" {
+			s.WriteString(normalStyle.Render(v.Help))
+			s.WriteString("\n")
+		}
+		switch v.Type {
+		case templates.VarChoice:
+			for i, c := range v.Choices {
+				cursor := "  "
+				style := normalStyle
+				if i == m.cursor {
+					cursor = "▸ "
+					style = selectedStyle
+				}
+				s.WriteString(fmt.Sprintf("%s%s\n", cursor, style.Render(c)))
+			}
+		case templates.VarBool:
+			s.WriteString(normalStyle.Render(fmt.Sprintf("(y/n, default %s)", valueOr(v.Default, "false"))))
+		default:
+			s.WriteString(m.textInput.View())
+		}
+		if m.varErr != nil {
+			s.WriteString("\n")
+			s.WriteString(errorStyle.Render(m.varErr.Error()))
+		}
+
 	case stepDocker:
 		s.WriteString(questionStyle.Render("? Include Dockerfile? (y/n)"))
 
@@ -215,7 +393,11 @@ func (m model) View() string {
 		}
 
 	case stepGit:
-		s.WriteString(questionStyle.Render("? Initialize git repository? (y/n)"))
+		hint := "(y/N)"
+		if m.defaults.InitGit {
+			hint = "(Y/n)"
+		}
+		s.WriteString(questionStyle.Render("? Initialize git repository? " + hint))
 
 	case stepDone:
 		s.WriteString(successStyle.Render("✓ Configuration complete!"))
@@ -227,9 +409,23 @@ func (m model) View() string {
 	return s.String()
 }
 
+func valueOr(val, def string) string {
+	if val == "" {
+		return def
+	}
+	return val
+}
+
 // Run starts the interactive TUI and returns the user's configuration
 func Run() (ProjectConfig, error) {
-	p := tea.NewProgram(initialModel())
+	return RunWithDefaults(ProjectConfig{License: "MIT", InitGit: true})
+}
+
+// RunWithDefaults starts the interactive TUI with answers preselected from
+// defaults. Variables present in defaults.Vars are not asked for, and fields
+// the TUI doesn't ask about are returned unchanged.
+func RunWithDefaults(defaults ProjectConfig) (ProjectConfig, error) {
+	p := tea.NewProgram(initialModel(defaults))
 	m, err := p.Run()
 	if err != nil {
 		return ProjectConfig{}, err
@@ -0,0 +1,288 @@
+// Package upgrade brings a generated project to another version of its
+// template with a three-way merge: the old version is the base, the new one
+// theirs and the project on disk ours
+package upgrade
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/purnama/scaffold/internal/diff"
+	"github.com/purnama/scaffold/internal/drift"
+	"github.com/purnama/scaffold/internal/generator"
+	"github.com/purnama/scaffold/internal/lockfile"
+	"github.com/purnama/scaffold/internal/render"
+	"github.com/purnama/scaffold/internal/safefs"
+	"github.com/purnama/scaffold/internal/templates"
+)
+
+// Action is what the upgrade does with one file
+type Action string
+
+const (
+	Unchanged Action = "unchanged" // The template didn't change it, or the project already has the new content
+	Updated   Action = "updated"   // Untouched since generation, replaced by the new version
+	Merged    Action = "merged"    // Template changes and project edits combined
+	Conflict  Action = "conflict"  // Combined with conflict markers to resolve by hand
+	Added     Action = "added"     // New in the template
+	Removed   Action = "removed"   // Dropped by the template and untouched, deleted
+	Skipped   Action = "skipped"   // Left alone, see Reason
+)
+
+// File is the planned change for one file
+type File struct {
+	Path      string
+	Action    Action
+	Reason    string      // Why a file is skipped
+	Content   []byte      // New content to write, nil when the file stays as it is
+	Generated []byte      // What the new version generates, nil when it dropped the file
+	Mode      fs.FileMode // Permissions of an added file, existing files keep theirs
+	Conflicts int
+
+	component bool // Owned by a component, its lockfile hash stays
+}
+
+// Plan is the set of changes an upgrade makes. Nothing is written until
+// Apply.
+type Plan struct {
+	Dir   string
+	Lock  *lockfile.Lock
+	From  string // Recorded template version
+	To    string
+	Dirs  []string // Directories of the new version missing from the project
+	Files []File   // Sorted by path
+
+	FollowSymlinks bool // Write through symbolic links in Dir that stay inside it
+}
+
+// NewPlan computes the upgrade of the project in dir to version to of its
+// template, the current version when to is empty
+func NewPlan(dir, to string) (*Plan, error) {
+	lock, err := lockfile.Read(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s has no %s, only projects created by scaffold init can be upgraded", dir, lockfile.FileName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if lock.TemplateVersion == "" {
+		return nil, fmt.Errorf("%s doesn't record the version of template %s, there is nothing to merge from", lockfile.FileName, lock.Template)
+	}
+
+	if to == "" {
+		current, err := templates.GetTemplate(lock.Template)
+		if err != nil {
+			return nil, err
+		}
+		to = current.Version
+	}
+	oldTmpl, err := templates.GetTemplateVersion(lock.Template, lock.TemplateVersion)
+	if err != nil {
+		return nil, err
+	}
+	newTmpl, err := templates.GetTemplateVersion(lock.Template, to)
+	if err != nil {
+		return nil, err
+	}
+
+	base, _, err := renderVersion(lock, oldTmpl)
+	if err != nil {
+		return nil, err
+	}
+	theirs, dirs, err := renderVersion(lock, newTmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Dir: dir, Lock: lock, From: lock.TemplateVersion, To: to}
+	for _, d := range dirs {
+		if _, err := os.Stat(filepath.Join(dir, d)); errors.Is(err, fs.ErrNotExist) {
+			plan.Dirs = append(plan.Dirs, d)
+		}
+	}
+
+	paths := map[string]bool{}
+	for p := range base {
+		paths[p] = true
+	}
+	for p := range theirs {
+		paths[p] = true
+	}
+	owners := lock.ComponentFiles()
+	label := fmt.Sprintf("%s %s", lock.Template, to)
+
+	for p := range paths {
+		if owner, ok := owners[p]; ok {
+			plan.Files = append(plan.Files, File{Path: p, Action: Skipped, Reason: "managed by component " + owner, component: true})
+			continue
+		}
+		ours, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(p)))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		exists := err == nil
+		f := planFile(p, content(base, p), content(theirs, p), ours, exists, label)
+		f.Mode = theirs[p].Mode
+		plan.Files = append(plan.Files, f)
+	}
+
+	sort.Slice(plan.Files, func(i, j int) bool {
+		return plan.Files[i].Path < plan.Files[j].Path
+	})
+	return plan, nil
+}
+
+// planFile decides what happens to one file. base and theirs are nil when
+// the old or the new version doesn't generate it.
+func planFile(path string, base, theirs, ours []byte, exists bool, label string) File {
+	f := File{Path: path, Generated: theirs}
+	switch {
+	case theirs == nil:
+		// Dropped by the new version
+		switch {
+		case !exists:
+			f.Action = Unchanged
+		case bytes.Equal(ours, base):
+			f.Action = Removed
+		default:
+			f.Action, f.Reason = Skipped, "no longer in the template, kept because you changed it"
+		}
+	case !exists:
+		if base == nil {
+			f.Action, f.Content = Added, theirs
+		} else if bytes.Equal(base, theirs) {
+			f.Action = Unchanged
+		} else {
+			f.Action, f.Reason = Skipped, "deleted from the project"
+		}
+	case bytes.Equal(ours, theirs), bytes.Equal(base, theirs):
+		f.Action = Unchanged
+	case base != nil && bytes.Equal(ours, base):
+		f.Action, f.Content = Updated, theirs
+	case render.IsBinary(theirs) || render.IsBinary(ours):
+		f.Action, f.Reason = Skipped, "binary file changed in the project and the template, kept yours"
+	default:
+		merged, conflicts := diff.Merge3(string(base), string(ours), string(theirs), "yours", label)
+		f.Content, f.Conflicts = []byte(merged), conflicts
+		if conflicts > 0 {
+			f.Action = Conflict
+		} else {
+			f.Action = Merged
+		}
+	}
+	return f
+}
+
+// renderVersion generates a version of the template with the recorded
+// inputs and returns its files by path and its directories
+func renderVersion(lock *lockfile.Lock, tmpl templates.Template) (map[string]generator.File, []string, error) {
+	out, err := generator.RenderTemplate(tmpl, drift.ProjectConfig(lock, tmpl))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render %s %s: %w", tmpl.Name, tmpl.Version, err)
+	}
+	files := make(map[string]generator.File, len(out.Files))
+	for _, f := range out.Files {
+		files[f.Path] = f
+	}
+	return files, out.Directories, nil
+}
+
+// content returns the content of the file at p, nil when there is none
+func content(files map[string]generator.File, p string) []byte {
+	f, ok := files[p]
+	if !ok {
+		return nil
+	}
+	return []byte(f.Content)
+}
+
+// Count returns the number of files with the given action
+func (p *Plan) Count(a Action) int {
+	n := 0
+	for _, f := range p.Files {
+		if f.Action == a {
+			n++
+		}
+	}
+	return n
+}
+
+// Conflicts returns the number of conflict blocks over all files
+func (p *Plan) Conflicts() int {
+	n := 0
+	for _, f := range p.Files {
+		n += f.Conflicts
+	}
+	return n
+}
+
+// Apply writes the planned changes and records the new version and file
+// hashes in the lockfile
+func (p *Plan) Apply() error {
+	root := safefs.Root{Dir: p.Dir, FollowSymlinks: p.FollowSymlinks}
+
+	// Check every path before anything is written
+	targets := make([]string, len(p.Files))
+	for i, f := range p.Files {
+		var err error
+		if targets[i], err = root.Path(f.Path); err != nil {
+			return err
+		}
+	}
+
+	for _, d := range p.Dirs {
+		if err := root.MkdirAll(d, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", d, err)
+		}
+	}
+
+	for i, f := range p.Files {
+		target := targets[i]
+		switch {
+		case f.Content != nil:
+			if err := writeFile(target, f.Content, f.Mode); err != nil {
+				return fmt.Errorf("failed to write %s: %w", f.Path, err)
+			}
+		case f.Action == Removed:
+			if err := os.Remove(target); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", f.Path, err)
+			}
+		}
+
+		// Hashes follow what the new version generates
+		switch {
+		case f.component:
+		case f.Generated != nil:
+			p.Lock.SetFile(f.Path, f.Generated)
+		default:
+			delete(p.Lock.Files, f.Path)
+		}
+	}
+
+	p.Lock.TemplateVersion = p.To
+	p.Lock.ScaffoldVersion = lockfile.ScaffoldVersion
+	p.Lock.Touch()
+	if err := lockfile.Write(p.Dir, p.Lock); err != nil {
+		return fmt.Errorf("failed to update %s: %w", lockfile.FileName, err)
+	}
+	return nil
+}
+
+// writeFile replaces the content of path, keeping the mode of an existing file
+func writeFile(path string, content []byte, mode fs.FileMode) error {
+	if mode == 0 {
+		mode = 0644
+	}
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, mode)
+}
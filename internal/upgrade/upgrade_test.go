@@ -0,0 +1,301 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/purnama/scaffold/internal/generator"
+	"github.com/purnama/scaffold/internal/lockfile"
+	"github.com/purnama/scaffold/internal/templates"
+	"github.com/purnama/scaffold/internal/tui"
+)
+
+// writeTemplate writes a custom template version into dir
+func writeTemplate(t *testing.T, dir, version string, files map[string]string) {
+	t.Helper()
+	os.MkdirAll(dir, 0755)
+	manifest, _ := json.Marshal(templates.Manifest{Name: "svc", Version: version})
+	os.WriteFile(filepath.Join(dir, templates.ManifestFile), manifest, 0644)
+	for p, content := range files {
+		os.MkdirAll(filepath.Dir(filepath.Join(dir, p)), 0755)
+		os.WriteFile(filepath.Join(dir, p), []byte(content), 0644)
+	}
+}
+
+// customProject generates a project from version 1.0.0 of a custom
+// template, then releases version 2.0.0 of the template, keeping 1.0.0 in
+// its .history
+func customProject(t *testing.T) string {
+	t.Helper()
+	templatesDir := t.TempDir()
+	t.Cleanup(func() { templates.LoadCustomTemplates(t.TempDir()) })
+	tmplDir := filepath.Join(templatesDir, "svc")
+
+	v1 := map[string]string{
+		"config.yaml": "name: {{.ProjectName}}\nport: 8080\nhost: 0.0.0.0\nlog: info\n",
+		"old.txt":     "dropped in 2.0.0\n",
+		"edited.txt":  "dropped in 2.0.0 but edited\n",
+		"notes.txt":   "one\ntwo\nthree\n",
+	}
+	writeTemplate(t, tmplDir, "1.0.0", v1)
+	if err := templates.LoadCustomTemplates(templatesDir); err != nil {
+		t.Fatal(err)
+	}
+
+	outDir := t.TempDir()
+	config := tui.ProjectConfig{ProjectName: "billing", TemplateName: "svc", License: "None", OutputDir: outDir}
+	if err := generator.GenerateWithOptions(config, generator.Options{}); err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+
+	writeTemplate(t, filepath.Join(tmplDir, templates.HistoryDir, "1.0.0"), "1.0.0", v1)
+	os.Remove(filepath.Join(tmplDir, "old.txt"))
+	os.Remove(filepath.Join(tmplDir, "edited.txt"))
+	writeTemplate(t, tmplDir, "2.0.0", map[string]string{
+		"config.yaml": "name: {{.ProjectName}}\nport: 8080\nhost: 0.0.0.0\nlog: warn\n",
+		"notes.txt":   "one\nTWO\nthree\n",
+		"new.txt":     "added in 2.0.0\n",
+	})
+	if err := templates.LoadCustomTemplates(templatesDir); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(outDir, "billing")
+}
+
+func TestUpgradeCustomTemplate(t *testing.T) {
+	dir := customProject(t)
+	os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("name: billing\nport: 9000\nhost: 0.0.0.0\nlog: info\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("one\nmine\nthree\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "edited.txt"), []byte("still needed\n"), 0644)
+
+	plan, err := NewPlan(dir, "")
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+	if plan.From != "1.0.0" || plan.To != "2.0.0" {
+		t.Errorf("plan from %s to %s, want 1.0.0 to 2.0.0", plan.From, plan.To)
+	}
+	want := map[string]Action{
+		"config.yaml": Merged,
+		"notes.txt":   Conflict,
+		"new.txt":     Added,
+		"old.txt":     Removed,
+		"edited.txt":  Skipped,
+	}
+	for _, f := range plan.Files {
+		if f.Action != want[f.Path] {
+			t.Errorf("%s action = %s, want %s", f.Path, f.Action, want[f.Path])
+		}
+	}
+
+	if err := plan.Apply(); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	read := func(p string) string {
+		content, _ := os.ReadFile(filepath.Join(dir, p))
+		return string(content)
+	}
+	if got := read("config.yaml"); got != "name: billing\nport: 9000\nhost: 0.0.0.0\nlog: warn\n" {
+		t.Errorf("config.yaml = %q, want both changes", got)
+	}
+	if got := read("notes.txt"); got != "one\n<<<<<<< yours\nmine\n=======\nTWO\n>>>>>>> svc 2.0.0\nthree\n" {
+		t.Errorf("notes.txt = %q", got)
+	}
+	if got := read("new.txt"); got != "added in 2.0.0\n" {
+		t.Errorf("new.txt = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old.txt")); !os.IsNotExist(err) {
+		t.Error("old.txt should be removed")
+	}
+	if got := read("edited.txt"); got != "still needed\n" {
+		t.Errorf("edited.txt = %q, should be kept", got)
+	}
+
+	lock, err := lockfile.Read(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.TemplateVersion != "2.0.0" {
+		t.Errorf("lock version = %s, want 2.0.0", lock.TemplateVersion)
+	}
+	if lock.Files["new.txt"] != lockfile.Hash([]byte("added in 2.0.0\n")) {
+		t.Error("new.txt should be recorded")
+	}
+	for _, p := range []string{"old.txt", "edited.txt"} {
+		if _, ok := lock.Files[p]; ok {
+			t.Errorf("%s should no longer be recorded", p)
+		}
+	}
+	if lock.Files["notes.txt"] != lockfile.Hash([]byte("one\nTWO\nthree\n")) {
+		t.Error("notes.txt should be recorded with the generated content")
+	}
+}
+
+func TestUpgradeDryRunWritesNothing(t *testing.T) {
+	dir := customProject(t)
+	before, _ := os.ReadFile(lockfile.Path(dir))
+
+	plan, err := NewPlan(dir, "")
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+	if plan.Count(Updated) != 2 || plan.Count(Added) != 1 || plan.Count(Removed) != 2 {
+		t.Errorf("unexpected plan: %+v", plan.Files)
+	}
+
+	after, _ := os.ReadFile(lockfile.Path(dir))
+	if string(before) != string(after) {
+		t.Error("planning should not touch the lockfile")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.txt")); !os.IsNotExist(err) {
+		t.Error("planning should not write files")
+	}
+}
+
+// oldGoAPI turns a freshly generated go-api project into one generated by
+// go-api 1.0.0
+func oldGoAPI(t *testing.T) string {
+	t.Helper()
+	outDir := t.TempDir()
+	config := tui.ProjectConfig{ProjectName: "api", TemplateName: "go-api", License: "None", OutputDir: outDir, Vars: map[string]string{"port": "9090"}}
+	if err := generator.GenerateWithOptions(config, generator.Options{}); err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+	dir := filepath.Join(outDir, "api")
+
+	old, err := templates.GetTemplateVersion("go-api", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := generator.RenderTemplate(old, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock, _ := lockfile.Read(dir)
+	for _, f := range out.Files {
+		os.WriteFile(filepath.Join(dir, f.Path), []byte(f.Content), 0644)
+		lock.SetFile(f.Path, []byte(f.Content))
+	}
+	lock.TemplateVersion = "1.0.0"
+	lockfile.Write(dir, lock)
+	return dir
+}
+
+func TestUpgradeBuiltInTemplate(t *testing.T) {
+	dir := oldGoAPI(t)
+	dockerfile := filepath.Join(dir, "Dockerfile")
+	content, _ := os.ReadFile(dockerfile)
+	os.WriteFile(dockerfile, []byte("# syntax=docker/dockerfile:1\n"+string(content)), 0644)
+
+	plan, err := NewPlan(dir, "")
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+	for _, f := range plan.Files {
+		want := Unchanged
+		if f.Path == "Dockerfile" {
+			want = Merged
+		}
+		if f.Action != want {
+			t.Errorf("%s action = %s, want %s", f.Path, f.Action, want)
+		}
+	}
+	if err := plan.Apply(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ = os.ReadFile(dockerfile)
+	for _, want := range []string{"# syntax=docker/dockerfile:1\n", "EXPOSE 9090\n", "COPY go.mod go.sum* ./\n"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Dockerfile should contain %q, got:\n%s", want, content)
+		}
+	}
+}
+
+func TestUpgradeSkipsComponentFiles(t *testing.T) {
+	dir := oldGoAPI(t)
+	lock, _ := lockfile.Read(dir)
+	lock.AddComponent("dockerfile", []string{"Dockerfile"})
+	lockfile.Write(dir, lock)
+	before, _ := os.ReadFile(filepath.Join(dir, "Dockerfile"))
+
+	plan, err := NewPlan(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plan.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := os.ReadFile(filepath.Join(dir, "Dockerfile"))
+	if string(before) != string(after) {
+		t.Error("component files should not be upgraded")
+	}
+}
+
+func TestUpgradeRefusesSymlinks(t *testing.T) {
+	dir := customProject(t)
+	outside := filepath.Join(t.TempDir(), "notes.txt")
+	os.WriteFile(outside, []byte("one\ntwo\nthree\n"), 0644)
+	os.Remove(filepath.Join(dir, "notes.txt"))
+	if err := os.Symlink(outside, filepath.Join(dir, "notes.txt")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	for _, follow := range []bool{false, true} {
+		plan, err := NewPlan(dir, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		plan.FollowSymlinks = follow
+		if err := plan.Apply(); err == nil || !strings.Contains(err.Error(), "notes.txt") {
+			t.Errorf("Apply(follow %v) error = %v, want notes.txt refused", follow, err)
+		}
+	}
+	if content, _ := os.ReadFile(outside); string(content) != "one\ntwo\nthree\n" {
+		t.Errorf("file outside the project changed: %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.txt")); err == nil {
+		t.Error("files were written before the link was refused")
+	}
+}
+
+func TestPlanBinaryFiles(t *testing.T) {
+	base := []byte("\x89PNG\x00old")
+	theirs := []byte("\x89PNG\x00new")
+	mine := []byte("\x89PNG\x00mine")
+
+	tests := []struct {
+		name   string
+		ours   []byte
+		action Action
+	}{
+		{"unchanged in the project", base, Updated},
+		{"changed on both sides", mine, Skipped},
+		{"already the new version", theirs, Unchanged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := planFile("logo.png", base, theirs, tt.ours, true, "svc 2.0.0")
+			if f.Action != tt.action {
+				t.Errorf("Action = %s, want %s", f.Action, tt.action)
+			}
+			if f.Conflicts != 0 || (f.Content != nil && string(f.Content) != string(theirs)) {
+				t.Errorf("binary file merged: %q", f.Content)
+			}
+		})
+	}
+}
+
+func TestNewPlanErrors(t *testing.T) {
+	if _, err := NewPlan(t.TempDir(), ""); err == nil || !strings.Contains(err.Error(), lockfile.FileName) {
+		t.Errorf("NewPlan() without lockfile error = %v", err)
+	}
+
+	dir := oldGoAPI(t)
+	if _, err := NewPlan(dir, "9.9.9"); err == nil || !strings.Contains(err.Error(), "not available") {
+		t.Errorf("NewPlan() to an unknown version error = %v", err)
+	}
+}